@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/bump"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	moduleSetNamesBump []string
+	bumpLevel          string
+	chlogDir           string
+)
+
+// bumpCmd represents the bump command
+var bumpCmd = &cobra.Command{
+	Use:   "bump",
+	Short: "Computes the next version of changed module sets",
+	Long: `Updates the versions of module sets whose modules have changed since they were last tagged:
+- Skips module sets whose version in the versioning file has not been tagged yet.
+- Checks which module sets have files changed since the tag of their current version.
+- Determines the bump level from --bump, or from the highest change_type of the chloggen entries
+  whose component is the name of the module set, or the path or directory of one of its modules
+  (breaking: major, deprecation/new_component/enhancement: minor, bug_fix: patch).
+  Breaking changes to module sets before v1 only bump the minor version, and module sets without
+  entries are skipped.
+- Refuses a new major version of a module set whose module paths lack the new major version suffix.
+- Rewrites the versions in the versioning file, keeping its comments and formatting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Using versioning file", versioningFile)
+
+		if err := multimod.Bump(multimod.BumpOptions{
+			VersioningFile: versioningFile,
+			ModuleSetNames: moduleSetNamesBump,
+			Level:          bumpLevel,
+			ChlogDir:       chlogDir,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(bumpCmd)
+
+	bumpCmd.Flags().StringSliceVarP(&moduleSetNamesBump, "module-set-names", "m", nil,
+		"Names of module sets to consider for a new version. "+
+			"If unspecified, all module sets in the versioning file are considered. "+
+			"To specify multiple module sets, specify set names as comma-separated values. "+
+			"For example: --module-set-names=\"mod-set-1,mod-set-2\"",
+	)
+
+	bumpCmd.Flags().StringVarP(&bumpLevel, "bump", "b", "",
+		"Bump level to apply to changed module sets: patch, minor or major. "+
+			"If unspecified, the level is derived from the chloggen entries.",
+	)
+
+	bumpCmd.Flags().StringVar(&chlogDir, "chloggen-dir", "",
+		"Directory containing chloggen entries used to derive the bump level. "+
+			"If unspecified, defaults to "+bump.DefaultChlogDir+" in the Git repo root.",
+	)
+}
@@ -5,36 +5,72 @@ package cmd
 
 import (
 	"log"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
-	"go.opentelemetry.io/build-tools/internal/repo"
 	"go.opentelemetry.io/build-tools/multimod/internal/diff"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
 )
 
 var (
 	previousVersion string
+	baseDiff        string
+	headDiff        string
+	includeDiff     []string
+	excludeDiff     []string
+	exitCodeDiff    bool
 )
 
 var diffCmd = &cobra.Command{
 	Use:   "diff",
 	Short: "Determines if any files in a module have changed",
+	Long: `Lists the files of each module in a module set that changed between two refs:
+- By default, HEAD is compared with the tag of each module at the previous version.
+- With --base, all modules are compared against the given ref instead.
+Only Go files are compared unless other patterns are given with --include.
+With --exit-code, the command exits with 1 if any module changed, 0 if none did,
+and 2 on error, so that CI can skip releasing unchanged module sets.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		repoRoot, err := repo.FindRoot()
-		if err != nil {
-			log.Fatalf("could not find repo root: %v", err)
+		fatalf := log.Fatalf
+		if exitCodeDiff {
+			fatalf = func(format string, v ...interface{}) {
+				log.Printf(format, v...)
+				os.Exit(2)
+			}
 		}
 
-		changedFiles, err := diff.HasChanged(repoRoot, versioningFile, previousVersion, moduleSetName)
+		changes, err := multimod.Diff(multimod.DiffOptions{
+			VersioningFile:  versioningFile,
+			ModuleSetName:   moduleSetName,
+			PreviousVersion: previousVersion,
+			Base:            baseDiff,
+			Head:            headDiff,
+			Include:         includeDiff,
+			Exclude:         excludeDiff,
+		})
 		if err != nil {
-			log.Fatalf("error running diff: %v", err)
+			fatalf("error running diff: %v", err)
+		}
+
+		since := baseDiff
+		if since == "" {
+			since = previousVersion
+		}
+		if len(changes) == 0 {
+			log.Printf("No %s modules have changed since %s", moduleSetName, since)
+			return
 		}
 
-		if len(changedFiles) > 0 {
-			log.Fatalf("The following files changed in %s modules since %s: \n%s\nRelease is required for %s modset", moduleSetName, previousVersion, strings.Join(changedFiles, "\n"), moduleSetName)
+		var b strings.Builder
+		if err = multimod.WriteDiffReport(&b, changes); err != nil {
+			fatalf("could not write report: %v", err)
+		}
+		log.Printf("The following files changed in %s modules since %s:\n%sRelease is required for %s modset", moduleSetName, since, b.String(), moduleSetName)
+		if exitCodeDiff {
+			os.Exit(1)
 		}
-		log.Printf("No %s modules have changed since %s", moduleSetName, previousVersion)
 	},
 }
 
@@ -52,10 +88,25 @@ func init() {
 	}
 
 	diffCmd.Flags().StringVarP(&previousVersion, "previous-version", "p", "",
-		"Previously released version."+
-			"Version must be a tag in the repository. ",
+		"Previously released version. "+
+			"The tags of the modules at this version are compared with the head ref. "+
+			"Required unless --base is given.",
+	)
+	diffCmd.Flags().StringVar(&baseDiff, "base", "",
+		"Ref (branch, tag or commit) to compare all modules against instead of their previous version tags.",
+	)
+	diffCmd.Flags().StringVar(&headDiff, "head", "",
+		"Ref (branch, tag or commit) to compare. Defaults to HEAD.",
+	)
+	diffCmd.Flags().StringSliceVar(&includeDiff, "include", nil,
+		"Patterns of the files to compare, relative to the module directory. "+
+			"A pattern without a slash matches any element of the path. "+
+			"Defaults to "+strings.Join(diff.DefaultInclude, ",")+".",
+	)
+	diffCmd.Flags().StringSliceVar(&excludeDiff, "exclude", nil,
+		"Patterns of included files not to compare, such as testdata.",
+	)
+	diffCmd.Flags().BoolVar(&exitCodeDiff, "exit-code", false,
+		"Exit with 1 if any module changed, 0 if none did, and 2 on error.",
 	)
-	if err := diffCmd.MarkFlagRequired("previous-version"); err != nil {
-		log.Fatalf("could not mark previous-version flag as required: %v", err)
-	}
 }
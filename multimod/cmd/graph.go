@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/graph"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	graphLevel  string
+	graphOutput string
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Prints the dependency graph of modules or module sets",
+	Long: `Prints the dependencies between the modules listed in the versioning file,
+or between their module sets, as defined by the require sections of go.mod files:
+- Each node is labeled with its module set and version.
+- Dependencies of a stable module (set) on an unstable one are highlighted.
+The graph is printed as Graphviz DOT, Mermaid or JSON.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := multimod.Graph(multimod.GraphOptions{
+			VersioningFile: versioningFile,
+			Level:          graphLevel,
+			Format:         graphOutput,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphLevel, "level", "l", graph.LevelModuleSet,
+		"Nodes of the graph: "+graph.LevelModule+" or "+graph.LevelModuleSet+".",
+	)
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", graph.FormatDOT,
+		"Output format of the graph: "+graph.FormatDOT+", "+graph.FormatMermaid+" or "+graph.FormatJSON+".",
+	)
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/plan"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	moduleSetNamePlan string
+	planOutput        string
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Prints a release plan for a module set",
+	Long: `Prints a summary of the release of a module set, suitable for review before tagging:
+- The current tag and the new tag of each module in the set.
+- The files of each module changed since its current tag.
+- The modules outside of the set that require modules of the set at another version,
+  and will need a new version after the release.
+The plan is printed as markdown suitable for a pull request description, or as JSON.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := multimod.Plan(multimod.PlanOptions{
+			VersioningFile: versioningFile,
+			ModuleSetName:  moduleSetNamePlan,
+			Format:         planOutput,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringVarP(&moduleSetNamePlan, "module-set-name", "m", "",
+		"Name of module set to plan the release of. "+
+			"Name must be listed in the module set versioning YAML. ",
+	)
+	if err := planCmd.MarkFlagRequired("module-set-name"); err != nil {
+		log.Fatalf("could not mark module-set-name flag as required: %v", err)
+	}
+
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", plan.FormatMarkdown,
+		"Output format of the plan: "+plan.FormatMarkdown+" or "+plan.FormatJSON+".",
+	)
+}
@@ -20,14 +20,18 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"go.opentelemetry.io/build-tools/multimod/internal/prerelease"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
 )
 
 var (
 	allModuleSets           bool
 	moduleSetNames          []string
 	skipGoModTidy           bool
+	goModTidyCompat         string
 	commitToDifferentBranch bool
+	dryRun                  bool
+	skipChecks              []string
 )
 
 // prereleaseCmd represents the prerelease command
@@ -38,10 +42,15 @@ var prereleaseCmd = &cobra.Command{
 - Checks that the working tree is clean.
 - Checks that Git tags do not already exist for the new module set version.
 - Switches to a new branch called prerelease_<module set name>_<new version>.
-- Updates version.go files, if they exist.
+- Updates the version files declared for the module set, or version.go files if none are declared.
 - Updates module versions in all go.mod files.
 - Attempts to call 'go mod tidy' in the directory of each modified go.mod file.
-- Adds and commits changes to Git branch`,
+- Runs the pre-tag checks on the modules of the set, unless skipped with --skip-checks:
+  no local replace directives for modules of the repo, tidy go.mod files and complete go.sum files.
+  The changes are discarded if a check fails.
+- Adds and commits changes to Git branch
+With --dry-run, prints the planned edits as unified diffs and the branch that
+would be created, without changing the repository.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		if allModuleSets {
 			// do not require module set names if operating on all module sets
@@ -57,7 +66,18 @@ var prereleaseCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Using versioning file", versioningFile)
 
-		prerelease.Run(versioningFile, moduleSetNames, allModuleSets, skipGoModTidy, commitToDifferentBranch)
+		if err := multimod.Prerelease(multimod.PrereleaseOptions{
+			VersioningFile:          versioningFile,
+			ModuleSetNames:          moduleSetNames,
+			AllModuleSets:           allModuleSets,
+			SkipModTidy:             skipGoModTidy,
+			GoModTidyCompat:         goModTidyCompat,
+			CommitToDifferentBranch: commitToDifferentBranch,
+			DryRun:                  dryRun,
+			SkipChecks:              skipChecks,
+		}); err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
@@ -84,7 +104,14 @@ func init() {
 		"Specify this flag to skip calling 'go mod tidy'. "+
 			"To be used for debugging purposes. Should not be skipped during actual release.",
 	)
+	prereleaseCmd.Flags().StringVar(&goModTidyCompat, "go-mod-tidy-compat", common.DefaultGoModTidyCompat,
+		"Go version passed to 'go mod tidy -compat'. The -compat flag is omitted if empty.",
+	)
 	prereleaseCmd.Flags().BoolVarP(&commitToDifferentBranch, "commit-to-different-branch", "b", true,
 		"Specify this flag to commit to a different branch.",
 	)
+	prereleaseCmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Specify this flag to print the planned changes without modifying the repository.",
+	)
+	prereleaseCmd.Flags().StringSliceVar(&skipChecks, "skip-checks", nil, skipChecksUsage)
 }
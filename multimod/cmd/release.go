@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/release"
+	"go.opentelemetry.io/build-tools/multimod/internal/tag"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	allModuleSetsRelease   bool
+	moduleSetNamesRelease  []string
+	commitHashRelease      string
+	remoteRelease          string
+	stateFileRelease       string
+	skipGoModTidyRelease   bool
+	goModTidyCompatRelease string
+	signRelease            string
+	skipChecksRelease      []string
+)
+
+// releaseCmd represents the release command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Prepares, tags and pushes a release of one or more module sets",
+	Long: `Drives a release from start to finish, recording its progress in a state file:
+- Runs prerelease for each module set, including its pre-tag checks, committing to a new prerelease branch.
+- Stops so that the prerelease branches can be reviewed and merged.
+- When run again with --commit-hash, runs the pre-tag checks on the merged commit and tags it for
+  each module set.
+- Pushes the new tags to the remote and verifies that they point at the commit.
+If any step fails, running release again continues from where it stopped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Using versioning file", versioningFile)
+
+		if err := multimod.Release(multimod.ReleaseOptions{
+			VersioningFile:  versioningFile,
+			ModuleSetNames:  moduleSetNamesRelease,
+			AllModuleSets:   allModuleSetsRelease,
+			CommitHash:      commitHashRelease,
+			Remote:          remoteRelease,
+			StateFile:       stateFileRelease,
+			SkipModTidy:     skipGoModTidyRelease,
+			GoModTidyCompat: goModTidyCompatRelease,
+			SigningMode:     signRelease,
+			SkipChecks:      skipChecksRelease,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(releaseCmd)
+
+	releaseCmd.Flags().BoolVarP(&allModuleSetsRelease, "all-module-sets", "a", false,
+		"Specify this flag to release all sets listed in the versioning file. "+
+			"Ignored when resuming a release.",
+	)
+
+	releaseCmd.Flags().StringSliceVarP(&moduleSetNamesRelease, "module-set-names", "m", nil,
+		"Names of module sets to release. "+
+			"When starting a release, each name must be listed in the module set versioning YAML. "+
+			"When resuming a release, only the named module sets are advanced. "+
+			"To specify multiple module sets, specify set names as comma-separated values. "+
+			"For example: --module-set-names=\"mod-set-1,mod-set-2\"",
+	)
+
+	releaseCmd.Flags().StringVarP(&commitHashRelease, "commit-hash", "c", "",
+		"Git commit hash of the merged prerelease changes to tag. "+
+			"Required to continue a release once the prerelease branches are merged.",
+	)
+
+	releaseCmd.Flags().StringVarP(&remoteRelease, "remote", "r", "upstream",
+		"Name of the Git remote to push tags to.",
+	)
+
+	releaseCmd.Flags().StringVar(&stateFileRelease, "state-file", "",
+		"Path to the file recording the progress of the release. "+
+			"If unspecified, defaults to "+release.DefaultStateFileName+" in the .git directory of the repo.",
+	)
+
+	releaseCmd.Flags().BoolVarP(&skipGoModTidyRelease, "skip-go-mod-tidy", "s", false,
+		"Specify this flag to skip calling 'go mod tidy'. "+
+			"To be used for debugging purposes. Should not be skipped during actual release.",
+	)
+	releaseCmd.Flags().StringVar(&goModTidyCompatRelease, "go-mod-tidy-compat", common.DefaultGoModTidyCompat,
+		"Go version passed to 'go mod tidy -compat'. The -compat flag is omitted if empty.",
+	)
+
+	releaseCmd.Flags().StringVar(&signRelease, "sign", string(tag.DefaultSigningMode),
+		"How to sign the new tags: "+string(tag.SignDefault)+" for signatures in the format set by gpg.format, "+
+			string(tag.SignNone)+" for unsigned annotated tags, "+
+			string(tag.SignGPG)+" for GPG signatures or "+string(tag.SignSSH)+" for SSH signatures (gpg.format=ssh).",
+	)
+
+	releaseCmd.Flags().StringSliceVar(&skipChecksRelease, "skip-checks", nil, skipChecksUsage)
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	moduleSetNameRetract           string
+	versionsRetract                string
+	rationaleRetract               string
+	patchRetract                   bool
+	skipGoModTidyRetract           bool
+	goModTidyCompatRetract         string
+	skipChecksRetract              []string
+	commitToDifferentBranchRetract bool
+)
+
+// retractCmd represents the retract command
+var retractCmd = &cobra.Command{
+	Use:   "retract",
+	Short: "Retracts bad releases of a module set",
+	Long: `Adds retract directives for a version or a range of versions to the go.mod file
+of every module in a module set and commits them:
+- Checks that the working tree is clean.
+- Switches to a new branch called retract_<module set name>_<low version>[_<high version>].
+- Adds the retract directives, with the rationale as a comment, to go.mod files that do not
+  already retract the versions.
+- Adds and commits changes to Git branch.
+With --patch, also bumps the module set to its next patch version and runs prerelease
+for it, so that the retract directives can be published.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Using versioning file", versioningFile)
+
+		if err := multimod.Retract(multimod.RetractOptions{
+			VersioningFile:          versioningFile,
+			ModuleSetName:           moduleSetNameRetract,
+			Versions:                versionsRetract,
+			Rationale:               rationaleRetract,
+			Patch:                   patchRetract,
+			SkipModTidy:             skipGoModTidyRetract,
+			GoModTidyCompat:         goModTidyCompatRetract,
+			SkipChecks:              skipChecksRetract,
+			CommitToDifferentBranch: commitToDifferentBranchRetract,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(retractCmd)
+
+	retractCmd.Flags().StringVarP(&moduleSetNameRetract, "module-set-name", "m", "",
+		"Name of the module set whose versions are retracted.",
+	)
+	if err := retractCmd.MarkFlagRequired("module-set-name"); err != nil {
+		log.Fatalf("could not mark module-set-name flag as required: %v", err)
+	}
+	retractCmd.Flags().StringVar(&versionsRetract, "versions", "",
+		"Version to retract, such as v1.2.3, or closed range of versions, such as \"[v1.2.0, v1.2.3]\".",
+	)
+	if err := retractCmd.MarkFlagRequired("versions"); err != nil {
+		log.Fatalf("could not mark versions flag as required: %v", err)
+	}
+	retractCmd.Flags().StringVarP(&rationaleRetract, "rationale", "r", "",
+		"Reason for the retraction, added as a comment above each retract directive.",
+	)
+	retractCmd.Flags().BoolVarP(&patchRetract, "patch", "p", false,
+		"Specify this flag to bump the module set to its next patch version and run prerelease for it.",
+	)
+	retractCmd.Flags().BoolVarP(&skipGoModTidyRetract, "skip-go-mod-tidy", "s", false,
+		"Specify this flag to skip calling 'go mod tidy' when running prerelease for the patch version.",
+	)
+	retractCmd.Flags().StringVar(&goModTidyCompatRetract, "go-mod-tidy-compat", common.DefaultGoModTidyCompat,
+		"Go version passed to 'go mod tidy -compat'. The -compat flag is omitted if empty.",
+	)
+	retractCmd.Flags().StringSliceVar(&skipChecksRetract, "skip-checks", nil, skipChecksUsage)
+	retractCmd.Flags().BoolVarP(&commitToDifferentBranchRetract, "commit-to-different-branch", "b", true,
+		"Specify this flag to commit to a different branch.",
+	)
+}
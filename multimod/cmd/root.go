@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
 )
 
 var (
@@ -33,6 +34,11 @@ const (
 	defaultVersionsConfigType = "yaml"
 )
 
+// skipChecksUsage is the usage of the flag listing the pre-tag checks to skip.
+var skipChecksUsage = fmt.Sprintf("Pre-tag checks to skip, as comma-separated values: all or any of %v. "+
+	"The checks reject local replace directives for modules of the repo, go.mod files that are not tidy "+
+	"and go.sum files missing checksums.", common.PreTagChecks)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "versions",
@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var writeSuggest bool
+
+// suggestCmd represents the suggest command
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggests module sets for modules missing from the versioning file",
+	Long: `Finds the modules of the repo that are not listed in any module set and proposes
+a module set for each of them, among the sets whose version matches its major version:
+- Sets whose modules share a longer path prefix with the module are preferred.
+- Each module of the set that the module requires, or is required by, counts too.
+- No set is proposed without a dependency or a path prefix longer than the one common
+  to all modules of the repo, or when several sets fit equally well.
+The reasons for each suggestion are printed. With --write, the modules are
+inserted into the modules of the proposed sets, keeping comments and formatting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := multimod.Suggest(multimod.SuggestOptions{
+			VersioningFile: versioningFile,
+			Write:          writeSuggest,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(suggestCmd)
+
+	suggestCmd.Flags().BoolVarP(&writeSuggest, "write", "w", false,
+		"Specify this flag to insert the modules into the versioning file.",
+	)
+}
@@ -17,31 +17,46 @@ package cmd
 import (
 	"fmt"
 	"log"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
-	"go.opentelemetry.io/build-tools/multimod/internal/sync"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
 )
 
 var (
-	otherVersioningFile string
-	otherRepoRoot       string
-	allModuleSetsSync   bool
-	moduleSetNamesSync  []string
-	skipGoModTidySync   bool
+	otherVersioningFile         string
+	otherRepoRoot               string
+	otherRepoURL                string
+	otherRef                    string
+	allModuleSetsSync           bool
+	moduleSetNamesSync          []string
+	skipGoModTidySync           bool
+	goModTidyCompatSync         string
+	commitToDifferentBranchSync bool
+	dryRunSync                  bool
 )
 
 // syncCmd represents the sync command
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Syncs the versions of a repo's dependencies",
-	Long: `Updates version numbers of module sets from another repo:
+	Long: `Updates version numbers of module sets from another repo.
+The versioning file of the other repo is read from a local clone given with
+--other-repo-root, or from a git URL or local bare repo given with
+--other-repo-url, which is cloned in memory. With --other-repo-url, the
+versioning file is read at --other-ref, or at the latest tag of each module set.
+
+Steps:
 - Checks that the working tree is clean.
-- Switches to a new branch called prerelease_<module set name>_<new version>.
+- Reports the modules requiring the module set, and which of them are already
+  at its version. Module sets that are already up to date are skipped.
 - Updates module versions in all go.mod files.
 - Attempts to call go mod tidy on the files.
-- Adds and commits changes to Git branch`,
+- Commits the changes to a new branch called sync_<module set name>_<version>,
+  or to the current branch with --commit-to-different-branch=false.
+With --dry-run, prints the planned go.mod edits as unified diffs without
+changing the repository.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		if allModuleSetsSync {
 			// do not require module set names if operating on all module sets
@@ -57,11 +72,21 @@ var syncCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Using versioning file", versioningFile)
 
-		if otherVersioningFile == "" {
-			otherVersioningFile = filepath.Join(otherRepoRoot,
-				fmt.Sprintf("%v.%v", defaultVersionsConfigName, defaultVersionsConfigType))
+		if err := multimod.Sync(multimod.SyncOptions{
+			VersioningFile:          versioningFile,
+			OtherRepoRoot:           otherRepoRoot,
+			OtherRepoURL:            otherRepoURL,
+			OtherRef:                otherRef,
+			OtherVersioningFile:     otherVersioningFile,
+			ModuleSetNames:          moduleSetNamesSync,
+			AllModuleSets:           allModuleSetsSync,
+			SkipModTidy:             skipGoModTidySync,
+			GoModTidyCompat:         goModTidyCompatSync,
+			CommitToDifferentBranch: commitToDifferentBranchSync,
+			DryRun:                  dryRunSync,
+		}); err != nil {
+			log.Fatal(err)
 		}
-		sync.Run(versioningFile, otherVersioningFile, otherRepoRoot, moduleSetNamesSync, allModuleSetsSync, skipGoModTidySync)
 	},
 }
 
@@ -73,12 +98,18 @@ func init() {
 
 	syncCmd.Flags().StringVarP(&otherRepoRoot, "other-repo-root", "o", "",
 		"File path of other repository root whose modules' versions need to be updated.")
-	if err := syncCmd.MarkFlagRequired("other-repo-root"); err != nil {
-		log.Fatalf("could not mark other-repo-root flag as required: %v", err)
-	}
+
+	syncCmd.Flags().StringVar(&otherRepoURL, "other-repo-url", "",
+		"Git URL, or path of a local bare repository, of the other repository. "+
+			"The repository is cloned in memory instead of using --other-repo-root.")
+
+	syncCmd.Flags().StringVar(&otherRef, "other-ref", "",
+		"Tag or other ref of --other-repo-url to read the other versioning file at. "+
+			"If unspecified, the latest tag of each module set is used.")
 
 	syncCmd.Flags().StringVar(&otherVersioningFile, "other-versioning-file", "",
 		"Path to other versioning file that contains all module set versions to sync. "+
+			"With --other-repo-url, the path is relative to the root of that repository. "+
 			"If unspecified, defaults to versions.yaml in the other Git repo root.")
 
 	syncCmd.Flags().BoolVarP(&allModuleSetsSync, "all-module-sets", "a", false,
@@ -99,4 +130,15 @@ func init() {
 		"Specify this flag to skip invoking `go mod tidy`. "+
 			"To be used for debugging purposes. Should not be skipped during actual release.",
 	)
+	syncCmd.Flags().StringVar(&goModTidyCompatSync, "go-mod-tidy-compat", common.DefaultGoModTidyCompat,
+		"Go version passed to 'go mod tidy -compat'. The -compat flag is omitted if empty.",
+	)
+
+	syncCmd.Flags().BoolVarP(&commitToDifferentBranchSync, "commit-to-different-branch", "b", true,
+		"Specify this flag to commit to a different branch.",
+	)
+
+	syncCmd.Flags().BoolVar(&dryRunSync, "dry-run", false,
+		"Specify this flag to print the planned changes without modifying the repository.",
+	)
 }
@@ -20,7 +20,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
 	"go.opentelemetry.io/build-tools/multimod/internal/tag"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
 )
 
 var (
@@ -28,6 +30,12 @@ var (
 	deleteModuleSetTags bool
 	moduleSetName       string
 	printTags           bool
+	pushTags            bool
+	remoteTag           string
+	signTag             string
+	dryRunTag           bool
+	skipChecksTag       []string
+	goModTidyCompatTag  string
 )
 
 // tagCmd represents the tag command
@@ -35,12 +43,34 @@ var tagCmd = &cobra.Command{
 	Use:   "tag",
 	Short: "Applies Git tags to specified commit",
 	Long: `Tag script to add Git tags to a specified commit hash created by prerelease script:
+- Runs the pre-tag checks on the modules of the set, unless skipped with --skip-checks:
+  no local replace directives for modules of the repo, tidy go.mod files and complete go.sum files.
+  The checks read the files of the commit, which does not need to be checked out.
 - Creates new Git tags for all modules being updated.
-- If tagging fails in the middle of the script, the recently created tags will be deleted.`,
+- Signs the tags according to --sign: signatures in the format configured in Git (default), unsigned
+  annotated tags (none), GPG (gpg) or SSH (ssh) signatures.
+  The signing setup is checked before any tag is created.
+- If tagging fails in the middle of the script, the recently created tags will be deleted.
+- If --push is specified, pushes the new tags to the remote and verifies that they point at the commit.
+- If --dry-run is specified, lists the tags that would be created or deleted without changing the repository.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Using versioning file", versioningFile)
 
-		tag.Run(versioningFile, moduleSetName, commitHash, deleteModuleSetTags, printTags)
+		if err := multimod.Tag(multimod.TagOptions{
+			VersioningFile:      versioningFile,
+			ModuleSetName:       moduleSetName,
+			CommitHash:          commitHash,
+			DeleteModuleSetTags: deleteModuleSetTags,
+			PrintTags:           printTags,
+			Push:                pushTags,
+			Remote:              remoteTag,
+			SigningMode:         signTag,
+			DryRun:              dryRunTag,
+			SkipChecks:          skipChecksTag,
+			GoModTidyCompat:     goModTidyCompatTag,
+		}); err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
@@ -72,4 +102,30 @@ func init() {
 	tagCmd.Flags().BoolVarP(&printTags, "print-tags", "p", false,
 		"Specify this flag to print all tags after tagging is complete. Printed tags are new-line delimited.",
 	)
+
+	tagCmd.Flags().BoolVar(&pushTags, "push", false,
+		"Specify this flag to push the new tags to the remote and verify that each of them points at the tagged commit.",
+	)
+
+	tagCmd.Flags().StringVarP(&remoteTag, "remote", "r", "upstream",
+		"Name or URL of the Git remote to push tags to. Only used with --push.",
+	)
+
+	tagCmd.Flags().StringVar(&signTag, "sign", string(tag.DefaultSigningMode),
+		"How to sign the new tags: "+string(tag.SignDefault)+" for signatures in the format set by gpg.format, "+
+			string(tag.SignNone)+" for unsigned annotated tags, "+
+			string(tag.SignGPG)+" for GPG signatures or "+string(tag.SignSSH)+" for SSH signatures (gpg.format=ssh).",
+	)
+
+	tagCmd.Flags().BoolVar(&dryRunTag, "dry-run", false,
+		"Specify this flag to list the tags that would be created or deleted without modifying the repository.",
+	)
+
+	tagCmd.Flags().StringSliceVar(&skipChecksTag, "skip-checks", nil, skipChecksUsage)
+
+	tagCmd.Flags().StringVar(&goModTidyCompatTag, "go-mod-tidy-compat", common.DefaultGoModTidyCompat,
+		"Go version passed to 'go mod tidy -compat' by the tidy check. The -compat flag is omitted if empty.",
+	)
+
+	tagCmd.MarkFlagsMutuallyExclusive("push", "delete-module-set-tags")
 }
@@ -20,7 +20,11 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"go.opentelemetry.io/build-tools/multimod/internal/verify"
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	fixVerify bool
 )
 
 // verifyCmd represents the verify command
@@ -31,12 +35,21 @@ var verifyCmd = &cobra.Command{
 - All modules are contained in exactly one module set.
 - Versions conform to semver semantics.
 - No more than one set of modules exists for any non-zero major version.
+- The major version of each module set matches the /vN suffix of its module paths.
 - Script warns if any stable modules depend on any unstable modules.
+- Every go.mod file requires modules of module sets at the version of their set
+  or of their latest tag. With --fix, out-of-date requirements are rewritten to
+  the version of the module set.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Using versioning file", versioningFile)
 
-		verify.Run(versioningFile)
+		if err := multimod.Verify(multimod.VerifyOptions{
+			VersioningFile: versioningFile,
+			Fix:            fixVerify,
+		}); err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
@@ -45,4 +58,9 @@ func init() {
 	log.SetFlags(0)
 
 	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().BoolVar(&fixVerify, "fix", false,
+		"Rewrite out-of-date intra-repo requirements in go.mod files to the version of the "+
+			"required module's set.",
+	)
 }
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"go.opentelemetry.io/build-tools/multimod/multimod"
+)
+
+var (
+	moduleSetNamesVerifyTags []string
+	allVersionsVerifyTags    bool
+)
+
+// verifyTagsCmd represents the verify-tags command
+var verifyTagsCmd = &cobra.Command{
+	Use:   "verify-tags",
+	Short: "Verifies that module set tags match the content of the tagged commits",
+	Long: `verify-tags reads the tagged commit of every module in the module sets and checks that:
+- The go.mod file exists and declares the module.
+- Modules of the same set are required at the tagged version.
+- The version files of the set (or version.go if none are declared) hold the tagged version.
+Only the tags of the current version of each set are checked, unless --all is specified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Using versioning file", versioningFile)
+
+		if err := multimod.VerifyTags(multimod.VerifyTagsOptions{
+			VersioningFile: versioningFile,
+			ModuleSetNames: moduleSetNamesVerifyTags,
+			AllVersions:    allVersionsVerifyTags,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	// Plain log output, no timestamps.
+	log.SetFlags(0)
+
+	rootCmd.AddCommand(verifyTagsCmd)
+
+	verifyTagsCmd.Flags().StringSliceVarP(&moduleSetNamesVerifyTags, "module-set-names", "m", nil,
+		"Names of module sets whose tags to verify. "+
+			"If unspecified, the tags of all module sets in the versioning file are verified. "+
+			"To specify multiple module sets, specify set names as comma-separated values. "+
+			"For example: --module-set-names=\"mod-set-1,mod-set-2\"",
+	)
+
+	verifyTagsCmd.Flags().BoolVar(&allVersionsVerifyTags, "all", false,
+		"Specify this flag to verify the tags of every version of the modules, not only the current version of their set.",
+	)
+}
@@ -0,0 +1,361 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bump
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/diff"
+)
+
+// DefaultChlogDir is the directory, relative to the repo root, where chloggen entries are read from.
+const DefaultChlogDir = ".chloggen"
+
+// Level is the part of a version that a bump increments.
+type Level int
+
+const (
+	LevelNone Level = iota
+	LevelPatch
+	LevelMinor
+	LevelMajor
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelPatch:
+		return "patch"
+	case LevelMinor:
+		return "minor"
+	case LevelMajor:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// ParseLevel parses a bump level given as "patch", "minor" or "major".
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "patch":
+		return LevelPatch, nil
+	case "minor":
+		return LevelMinor, nil
+	case "major":
+		return LevelMajor, nil
+	default:
+		return LevelNone, fmt.Errorf("invalid bump level %q, expected one of patch, minor or major", s)
+	}
+}
+
+// changeTypeLevels maps each chloggen change_type to the bump level it requires.
+var changeTypeLevels = map[string]Level{
+	"breaking":      LevelMajor,
+	"deprecation":   LevelMinor,
+	"new_component": LevelMinor,
+	"enhancement":   LevelMinor,
+	"bug_fix":       LevelPatch,
+}
+
+func Run(versioningFile string, moduleSetNames []string, level string, chlogDir string) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+	log.Printf("Using repo with root at %s\n\n", repoRoot)
+
+	if chlogDir == "" {
+		chlogDir = filepath.Join(repoRoot, DefaultChlogDir)
+	}
+
+	b := bumper{
+		versioningFile: versioningFile,
+		repoRoot:       repoRoot,
+		chlogDir:       chlogDir,
+	}
+	if level != "" {
+		if b.level, err = ParseLevel(level); err != nil {
+			return err
+		}
+	}
+
+	bumped, err := b.bump(moduleSetNames)
+	if err != nil {
+		return fmt.Errorf("bump failed: %w", err)
+	}
+
+	if len(bumped) == 0 {
+		log.Println("No module sets need a new version.")
+		return nil
+	}
+
+	log.Printf("=========\nUpdated %v. Review the changes, then run prerelease for the module sets above.\n", versioningFile)
+	return nil
+}
+
+// bumper holds the fields needed to compute and apply new module set versions.
+type bumper struct {
+	versioningFile string
+	repoRoot       string
+	chlogDir       string
+	// level is the bump level given explicitly. If it is LevelNone, the level is derived from
+	// the chloggen entries in chlogDir.
+	level Level
+}
+
+// bump computes the new version of every named module set (or of all module sets if no names are
+// given) whose modules changed since the set was last tagged, and writes the new versions to the
+// versioning file. It returns the new versions keyed by module set name.
+func (b bumper) bump(moduleSetNames []string) (map[string]string, error) {
+	r, err := git.PlainOpen(b.repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("could not open repo at %v: %w", b.repoRoot, err)
+	}
+
+	if len(moduleSetNames) == 0 {
+		if moduleSetNames, err = common.GetAllModuleSetNames(b.versioningFile, b.repoRoot); err != nil {
+			return nil, fmt.Errorf("could not automatically get all module set names: %w", err)
+		}
+	}
+	if moduleSetNames, err = common.GetModuleSetOrder(b.versioningFile, b.repoRoot, moduleSetNames); err != nil {
+		return nil, fmt.Errorf("could not order module sets: %w", err)
+	}
+
+	var changed []common.ModuleSetRelease
+	for _, moduleSetName := range moduleSetNames {
+		msr, err := common.NewModuleSetRelease(b.versioningFile, moduleSetName, b.repoRoot)
+		if err != nil {
+			return nil, err
+		}
+
+		hasChanged, err := b.moduleSetChanged(r, msr)
+		if err != nil {
+			return nil, fmt.Errorf("could not check changes of module set %v: %w", moduleSetName, err)
+		}
+		if hasChanged {
+			changed = append(changed, msr)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	var entries []chlogEntry
+	if b.level == LevelNone {
+		if entries, err = readEntries(b.chlogDir); err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no chloggen entries found in %v, specify the bump level explicitly", b.chlogDir)
+		}
+	}
+
+	newVersions := make(map[string]string, len(changed))
+	for _, msr := range changed {
+		setLevel := b.level
+		if setLevel == LevelNone {
+			if setLevel, err = moduleSetLevel(entries, msr, b.repoRoot); err != nil {
+				return nil, err
+			}
+			if setLevel == LevelNone {
+				log.Printf("Module set %v: no chloggen entries for its components. Skipping...\n", msr.ModSetName)
+				continue
+			}
+			// Before v1, breaking changes only require a new minor version.
+			if setLevel == LevelMajor && !common.IsStableVersion(msr.ModSetVersion()) {
+				setLevel = LevelMinor
+			}
+		}
+
+		newVersion, err := NextVersion(msr.ModSetVersion(), setLevel)
+		if err != nil {
+			return nil, fmt.Errorf("could not compute next version of module set %v: %w", msr.ModSetName, err)
+		}
+		// A new major version needs new module paths, which bump cannot make.
+		if err = common.CheckModuleSetMajorVersion(msr.ModSetName, common.ModuleSet{Version: newVersion, Modules: msr.ModSet.Modules}); err != nil {
+			return nil, fmt.Errorf("could not bump module set %v to %v, add the major version suffix to its module paths first: %w",
+				msr.ModSetName, newVersion, err)
+		}
+		log.Printf("Module set %v: %v -> %v (%v)\n", msr.ModSetName, msr.ModSetVersion(), newVersion, setLevel)
+		newVersions[msr.ModSetName] = newVersion
+	}
+
+	if len(newVersions) == 0 {
+		return nil, fmt.Errorf("no chloggen entries in %v refer to the changed module sets, specify the bump level explicitly", b.chlogDir)
+	}
+
+	if err = common.UpdateModuleSetVersions(b.versioningFile, newVersions); err != nil {
+		return nil, fmt.Errorf("could not update versioning file: %w", err)
+	}
+
+	return newVersions, nil
+}
+
+// moduleSetChanged returns true if any module of the set changed since the set was tagged with its
+// current version. Module sets whose current version has not been tagged yet already have a new
+// version pending, so they are reported as unchanged.
+func (b bumper) moduleSetChanged(r *git.Repository, msr common.ModuleSetRelease) (bool, error) {
+	err := msr.CheckGitTagsAlreadyExist(r)
+	switch {
+	case errors.As(err, &common.ErrGitTagsAlreadyExist{}):
+	case err == nil:
+		log.Printf("Module set %v: version %v has not been tagged yet. Skipping...\n", msr.ModSetName, msr.ModSetVersion())
+		return false, nil
+	default:
+		return false, err
+	}
+
+	changes, err := diff.HasChanged(b.repoRoot, b.versioningFile, msr.ModSetName, diff.Options{PreviousVersion: msr.ModSetVersion()})
+	if err != nil {
+		return false, err
+	}
+
+	if len(changes) == 0 {
+		log.Printf("Module set %v: no changes since %v.\n", msr.ModSetName, msr.ModSetVersion())
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// chlogEntry holds the fields of a chloggen entry needed to determine a bump level.
+type chlogEntry struct {
+	ChangeType string `yaml:"change_type"`
+	Component  string `yaml:"component"`
+	// file is the path of the entry, used in error messages.
+	file string
+}
+
+// readEntries reads the chloggen entries in dir, checking that each has a known change_type and
+// a component.
+func readEntries(dir string) ([]chlogEntry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []chlogEntry
+	for _, file := range files {
+		if filepath.Base(file) == "TEMPLATE.yaml" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, fmt.Errorf("could not read chloggen entry: %w", err)
+		}
+
+		entry := chlogEntry{file: file}
+		if err = yaml.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("could not parse chloggen entry %v: %w", file, err)
+		}
+
+		if _, ok := changeTypeLevels[entry.ChangeType]; !ok {
+			return nil, fmt.Errorf("chloggen entry %v has invalid change_type %q", file, entry.ChangeType)
+		}
+		if entry.Component == "" {
+			return nil, fmt.Errorf("chloggen entry %v has no component", file)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// moduleSetLevel returns the highest bump level required by the entries whose component refers to
+// the module set: its name, or the path or the directory, relative to the repo root, of one of its
+// modules. A component also refers to a module whose path ends with "/" followed by the component.
+func moduleSetLevel(entries []chlogEntry, msr common.ModuleSetRelease, repoRoot string) (Level, error) {
+	repoRoot, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return LevelNone, fmt.Errorf("could not get absolute path of repo root: %w", err)
+	}
+
+	components := map[string]bool{msr.ModSetName: true}
+	for _, modPath := range msr.ModSet.Modules {
+		components[string(modPath)] = true
+		if modFilePath, ok := msr.ModPathMap[modPath]; ok {
+			if dir, err := filepath.Rel(repoRoot, filepath.Dir(string(modFilePath))); err == nil {
+				components[filepath.ToSlash(dir)] = true
+			}
+		}
+	}
+
+	level := LevelNone
+	for _, entry := range entries {
+		if !components[entry.Component] && !hasComponentSuffix(msr.ModSet.Modules, entry.Component) {
+			continue
+		}
+		if entryLevel := changeTypeLevels[entry.ChangeType]; entryLevel > level {
+			level = entryLevel
+		}
+	}
+
+	return level, nil
+}
+
+// hasComponentSuffix returns true if the path of one of the modules ends with "/" followed by the
+// component.
+func hasComponentSuffix(modPaths []common.ModulePath, component string) bool {
+	for _, modPath := range modPaths {
+		if strings.HasSuffix(string(modPath), "/"+component) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextVersion increments version at the given level. A pre-release version is first bumped to its
+// release if that satisfies the level, so v1.0.0-rc.1 becomes v1.0.0 for any level.
+func NextVersion(version string, level Level) (string, error) {
+	if !semver.IsValid(version) {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+
+	core := strings.TrimPrefix(semver.Canonical(version), "v")
+	core = strings.TrimSuffix(core, semver.Prerelease(version))
+	parts := strings.Split(core, ".")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		nums[i] = n
+	}
+	major, minor, patch := nums[0], nums[1], nums[2]
+	isPrerelease := semver.Prerelease(version) != ""
+
+	switch level {
+	case LevelMajor:
+		if !isPrerelease || minor != 0 || patch != 0 {
+			major, minor, patch = major+1, 0, 0
+		}
+	case LevelMinor:
+		if !isPrerelease || patch != 0 {
+			minor, patch = minor+1, 0
+		}
+	case LevelPatch:
+		if !isPrerelease {
+			patch++
+		}
+	default:
+		return version, nil
+	}
+
+	return fmt.Sprintf("v%d.%d.%d", major, minor, patch), nil
+}
@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bump
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+var (
+	testDataDir, _ = filepath.Abs("./test_data")
+)
+
+// TestMain performs setup for the tests and suppress printing logs.
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestNextVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		level    Level
+		expected string
+	}{
+		{version: "v1.2.3", level: LevelPatch, expected: "v1.2.4"},
+		{version: "v1.2.3", level: LevelMinor, expected: "v1.3.0"},
+		{version: "v1.2.3", level: LevelMajor, expected: "v2.0.0"},
+		{version: "v0.20.0", level: LevelMinor, expected: "v0.21.0"},
+		{version: "v1.2.3+meta", level: LevelPatch, expected: "v1.2.4"},
+		{version: "v1.0.0-RC1", level: LevelPatch, expected: "v1.0.0"},
+		{version: "v1.0.0-RC1", level: LevelMajor, expected: "v1.0.0"},
+		{version: "v1.2.1-RC1", level: LevelMinor, expected: "v1.3.0"},
+		{version: "v1.2.3", level: LevelNone, expected: "v1.2.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version+"_"+tc.level.String(), func(t *testing.T) {
+			actual, err := NextVersion(tc.version, tc.level)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	_, err := NextVersion("1.2.3", LevelPatch)
+	assert.ErrorContains(t, err, `invalid version "1.2.3"`)
+}
+
+func TestReadEntries(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dir           string
+		expected      []string
+		expectedError string
+	}{
+		{name: "minor", dir: "chloggen_minor", expected: []string{"enhancement", "bug_fix"}},
+		{name: "no entries", dir: "does_not_exist"},
+		{name: "invalid change type", dir: "chloggen_invalid", expectedError: `has invalid change_type "unknown"`},
+		{name: "no component", dir: "chloggen_no_component", expectedError: "has no component"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := readEntries(filepath.Join(testDataDir, tc.dir))
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []string
+			for _, entry := range entries {
+				actual = append(actual, entry.ChangeType)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestModuleSetLevel(t *testing.T) {
+	repoRoot := t.TempDir()
+	msr := common.ModuleSetRelease{
+		ModuleVersioning: common.ModuleVersioning{
+			ModPathMap: common.ModulePathMap{
+				"go.opentelemetry.io/test/exporters/foo": common.ModuleFilePath(filepath.Join(repoRoot, "exporters", "foo", "go.mod")),
+			},
+		},
+		ModSetName: "stable",
+		ModSet: common.ModuleSet{
+			Version: "v1.2.3",
+			Modules: []common.ModulePath{"go.opentelemetry.io/test/exporters/foo"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		entries  []chlogEntry
+		expected Level
+	}{
+		{name: "module set name", entries: []chlogEntry{{ChangeType: "enhancement", Component: "stable"}}, expected: LevelMinor},
+		{name: "module path", entries: []chlogEntry{{ChangeType: "breaking", Component: "go.opentelemetry.io/test/exporters/foo"}}, expected: LevelMajor},
+		{name: "module directory", entries: []chlogEntry{{ChangeType: "bug_fix", Component: "exporters/foo"}}, expected: LevelPatch},
+		{name: "module path suffix", entries: []chlogEntry{{ChangeType: "bug_fix", Component: "foo"}}, expected: LevelPatch},
+		{
+			name: "highest level of matching entries",
+			entries: []chlogEntry{
+				{ChangeType: "bug_fix", Component: "foo"},
+				{ChangeType: "deprecation", Component: "stable"},
+				{ChangeType: "breaking", Component: "bar"},
+			},
+			expected: LevelMinor,
+		},
+		{name: "other component", entries: []chlogEntry{{ChangeType: "breaking", Component: "oo"}}, expected: LevelNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := moduleSetLevel(tc.entries, msr, repoRoot)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestBump(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versionsFile: []byte("# versions of all modules\nmodule-sets:\n" +
+			"  stable:\n    version: v1.2.3 # released\n    modules:\n      - go.opentelemetry.io/test/test1\n" +
+			"  unstable:\n    version: \"v0.4.0\"\n    modules:\n      - go.opentelemetry.io/test/test2\n" +
+			"  unchanged:\n    version: v1.0.0\n    modules:\n      - go.opentelemetry.io/test/test3\n" +
+			"  untagged:\n    version: v0.1.0\n    modules:\n      - go.opentelemetry.io/test/test4\n"),
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test/test2\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test", "test3", "go.mod"): []byte("module go.opentelemetry.io/test/test3\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test", "test4", "go.mod"): []byte("module go.opentelemetry.io/test/test4\n\ngo 1.19\n"),
+	}))
+	releaseHash, err := commontest.CommitAll(repo, "release")
+	require.NoError(t, err)
+
+	for _, tagName := range []string{"test/test1/v1.2.3", "test/test2/v0.4.0", "test/test3/v1.0.0"} {
+		_, err = repo.CreateTag(tagName, releaseHash, &git.CreateTagOptions{
+			Message: tagName,
+			Tagger:  commontest.TestAuthor,
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "test1.go"): []byte("package test1\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "test2.go"): []byte("package test2\n"),
+		filepath.Join(tmpRootDir, "test", "test4", "test4.go"): []byte("package test4\n"),
+	}))
+	_, err = commontest.CommitAll(repo, "changes")
+	require.NoError(t, err)
+
+	chlogDir := filepath.Join(t.TempDir(), ".chloggen")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(chlogDir, "fix.yaml"):      []byte("change_type: bug_fix\ncomponent: test/test1\nnote: fix\n"),
+		filepath.Join(chlogDir, "breaking.yaml"): []byte("change_type: breaking\ncomponent: unstable\nnote: break\n"),
+		filepath.Join(chlogDir, "other.yaml"):    []byte("change_type: breaking\ncomponent: other\nnote: other\n"),
+	}))
+
+	b := bumper{
+		versioningFile: versionsFile,
+		repoRoot:       tmpRootDir,
+		chlogDir:       chlogDir,
+	}
+
+	actual, err := b.bump(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"stable": "v1.2.4",
+		// breaking changes before v1 only bump the minor version
+		"unstable": "v0.5.0",
+	}, actual)
+
+	data, err := os.ReadFile(versionsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "# versions of all modules\nmodule-sets:\n"+
+		"  stable:\n    version: v1.2.4 # released\n    modules:\n      - go.opentelemetry.io/test/test1\n"+
+		"  unstable:\n    version: \"v0.5.0\"\n    modules:\n      - go.opentelemetry.io/test/test2\n"+
+		"  unchanged:\n    version: v1.0.0\n    modules:\n      - go.opentelemetry.io/test/test3\n"+
+		"  untagged:\n    version: v0.1.0\n    modules:\n      - go.opentelemetry.io/test/test4\n", string(data))
+
+	// A breaking change to a stable module set needs module paths with the new major version.
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(chlogDir, "fix.yaml"): []byte("change_type: breaking\ncomponent: test1\nnote: break\n"),
+	}))
+	require.NoError(t, os.WriteFile(versionsFile, []byte("module-sets:\n"+
+		"  stable:\n    version: v1.2.3\n    modules:\n      - go.opentelemetry.io/test/test1\n"), 0o600))
+	_, err = commontest.CommitAll(repo, "breaking")
+	require.NoError(t, err)
+
+	_, err = b.bump([]string{"stable"})
+	assert.ErrorContains(t, err, "could not bump module set stable to v2.0.0, add the major version suffix to its module paths first")
+}
@@ -0,0 +1,6 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bump computes the next version of module sets whose modules have
+// changed since they were last tagged and updates the versioning file.
+package bump
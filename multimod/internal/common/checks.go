@@ -0,0 +1,398 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// PreTagCheck names a check run on the modules of a module set before they are tagged.
+type PreTagCheck string
+
+const (
+	// CheckLocalReplace rejects replace directives pointing modules of the repo at local paths.
+	CheckLocalReplace PreTagCheck = "local-replace"
+	// CheckTidy rejects go.mod and go.sum files that "go mod tidy" would change.
+	CheckTidy PreTagCheck = "tidy"
+	// CheckGoSum rejects go.sum files missing the go.mod checksum of a requirement.
+	CheckGoSum PreTagCheck = "go-sum"
+)
+
+// PreTagChecks lists every pre-tag check, in the order they run.
+var PreTagChecks = []PreTagCheck{CheckLocalReplace, CheckTidy, CheckGoSum}
+
+// SkippedChecks is the set of pre-tag checks to skip.
+type SkippedChecks map[PreTagCheck]bool
+
+// ParseSkippedChecks parses the names of pre-tag checks to skip. The name "all" skips every check.
+func ParseSkippedChecks(names []string) (SkippedChecks, error) {
+	skipped := make(SkippedChecks, len(names))
+	for _, name := range names {
+		if name == "all" {
+			for _, check := range PreTagChecks {
+				skipped[check] = true
+			}
+			continue
+		}
+
+		known := false
+		for _, check := range PreTagChecks {
+			if PreTagCheck(name) == check {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown check %q, expected all or one of %v", name, PreTagChecks)
+		}
+		skipped[PreTagCheck(name)] = true
+	}
+	return skipped, nil
+}
+
+// All returns true if every pre-tag check is skipped.
+func (s SkippedChecks) All() bool {
+	for _, check := range PreTagChecks {
+		if !s[check] {
+			return false
+		}
+	}
+	return true
+}
+
+// PreTagCheckOptions configures RunPreTagChecks.
+type PreTagCheckOptions struct {
+	// Skip lists the checks that are not run.
+	Skip SkippedChecks
+	// GoModTidyCompat is the Go version passed to "go mod tidy -compat" by the tidy check.
+	GoModTidyCompat string
+}
+
+// RunPreTagChecks runs the pre-tag checks that are not skipped on the go.mod and go.sum files of
+// every module in the module set, as found in files. All problems found are returned together.
+func (modRelease ModuleSetRelease) RunPreTagChecks(files RepoFiles, opts PreTagCheckOptions) error {
+	var tidy tidyCopy
+	if !opts.Skip[CheckTidy] {
+		var err error
+		if tidy, err = modRelease.newTidyCopy(files); err != nil {
+			return err
+		}
+		defer func() {
+			if err := os.RemoveAll(tidy.dir); err != nil {
+				log.Printf("could not remove %v: %v\n", tidy.dir, err)
+			}
+		}()
+	}
+
+	var problems []string
+	for _, check := range PreTagChecks {
+		if opts.Skip[check] {
+			log.Printf("Skipping %v check...\n", check)
+			continue
+		}
+
+		for _, modPath := range modRelease.ModSetPaths() {
+			modFilePath, ok := modRelease.ModPathMap[modPath]
+			if !ok {
+				return fmt.Errorf("could not find go.mod file of module %v", modPath)
+			}
+
+			var found []string
+			var err error
+			switch check {
+			case CheckLocalReplace:
+				found, err = modRelease.checkLocalReplace(files, modFilePath)
+			case CheckTidy:
+				found, err = modRelease.checkTidy(tidy, modPath, opts.GoModTidyCompat)
+			case CheckGoSum:
+				found, err = modRelease.checkGoSum(files, modFilePath)
+			}
+			if err != nil {
+				return fmt.Errorf("%v check failed for %v: %w", check, modPath, err)
+			}
+			for _, p := range found {
+				problems = append(problems, fmt.Sprintf("%v: %v: %v", check, modPath, p))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &errPreTagChecksFailed{problems: problems}
+	}
+	return nil
+}
+
+// isRepoModule returns true if the module is one of the modules of the repo.
+func (modRelease ModuleSetRelease) isRepoModule(modPath string) bool {
+	if _, ok := modRelease.ModPathMap[ModulePath(modPath)]; ok {
+		return true
+	}
+	_, ok := modRelease.ModInfoMap[ModulePath(modPath)]
+	return ok
+}
+
+// checkLocalReplace returns the replace directives of the go.mod file pointing modules of the repo
+// at local paths. Such directives are ignored by the users of a released module, which then
+// require versions of the replaced modules that may not exist.
+func (modRelease ModuleSetRelease) checkLocalReplace(files RepoFiles, modFilePath ModuleFilePath) ([]string, error) {
+	modFile, err := parseModFile(files, modFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, r := range modFile.Replace {
+		if r.New.Version == "" && modfile.IsDirectoryPath(r.New.Path) && modRelease.isRepoModule(r.Old.Path) {
+			problems = append(problems, fmt.Sprintf("replace %v => %v points at a local path", r.Old.Path, r.New.Path))
+		}
+	}
+	return problems, nil
+}
+
+// tidyCopy is a temporary copy of the modules of a repo, in which the tidy check runs "go mod tidy"
+// so that the repo itself is never modified.
+type tidyCopy struct {
+	dir string
+	// modDirs maps the path of every module of the repo to its directory in the copy.
+	modDirs map[ModulePath]string
+}
+
+// newTidyCopy copies the go.mod, go.sum and .go files of the repo to a new temporary directory.
+func (modRelease ModuleSetRelease) newTidyCopy(files RepoFiles) (tidyCopy, error) {
+	dir, err := os.MkdirTemp("", "multimod-tidy-")
+	if err != nil {
+		return tidyCopy{}, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	c := tidyCopy{dir: dir, modDirs: make(map[ModulePath]string, len(modRelease.ModPathMap))}
+
+	if err = files.copyModuleFiles(dir); err != nil {
+		return c, fmt.Errorf("could not copy the modules of the repo to %v: %w", dir, err)
+	}
+	for modPath, modFilePath := range modRelease.ModPathMap {
+		rel, err := files.relPath(filepath.Dir(string(modFilePath)))
+		if err != nil {
+			return c, err
+		}
+		c.modDirs[modPath] = filepath.Join(dir, filepath.FromSlash(rel))
+	}
+	return c, nil
+}
+
+// checkTidy runs "go mod tidy" on the copy of the module and reports whether its go.mod or go.sum
+// file changed. The modules of the repo are required at versions that are not published yet, so
+// they are replaced with their directories in the copy while tidying, and the checksums of their
+// versions are left out of the comparison. The copy is restored afterwards.
+func (modRelease ModuleSetRelease) checkTidy(c tidyCopy, modPath ModulePath, compat string) ([]string, error) {
+	dir := c.modDirs[modPath]
+	modFile, sumFile := filepath.Join(dir, "go.mod"), filepath.Join(dir, "go.sum")
+
+	origMod, err := os.ReadFile(filepath.Clean(modFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read mod file: %w", err)
+	}
+	origSum, err := os.ReadFile(filepath.Clean(sumFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not read go.sum file: %w", err)
+	}
+
+	f, err := modfile.Parse(modFile, origMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse go.mod file: %w", err)
+	}
+	// "go mod tidy" also formats the go.mod file.
+	formatted, err := f.Format()
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := make(map[string]bool, len(f.Replace))
+	for _, r := range f.Replace {
+		replaced[r.Old.Path] = true
+	}
+	repoModPaths := make([]ModulePath, 0, len(c.modDirs))
+	for repoModPath := range c.modDirs {
+		repoModPaths = append(repoModPaths, repoModPath)
+	}
+	sort.Slice(repoModPaths, func(i, j int) bool { return repoModPaths[i] < repoModPaths[j] })
+	for _, repoModPath := range repoModPaths {
+		if repoModPath == modPath || replaced[string(repoModPath)] {
+			continue
+		}
+		rel, err := filepath.Rel(dir, c.modDirs[repoModPath])
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		if err = f.AddReplace(string(repoModPath), "", rel, ""); err != nil {
+			return nil, err
+		}
+	}
+	edited, err := f.Format()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.WriteFile(modFile, edited, 0600); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Later checks of the copy must see the original files.
+		_ = os.WriteFile(modFile, origMod, 0600)
+		if origSum == nil {
+			_ = os.Remove(sumFile)
+		} else {
+			_ = os.WriteFile(sumFile, origSum, 0600)
+		}
+	}()
+
+	args := []string{"mod", "tidy"}
+	if compat != "" {
+		args = append(args, "-compat="+compat)
+	}
+	// #nosec G204
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("go mod tidy failed [%v]: %w", strings.TrimSpace(string(out)), err)
+	}
+
+	tidyMod, err := os.ReadFile(filepath.Clean(modFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read mod file: %w", err)
+	}
+	tidySum, err := os.ReadFile(filepath.Clean(sumFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not read go.sum file: %w", err)
+	}
+
+	var problems []string
+	if !bytes.Equal(origMod, formatted) || !bytes.Equal(edited, tidyMod) {
+		problems = append(problems, "go.mod is not tidy")
+	}
+	if modRelease.goSumLines(origSum) != modRelease.goSumLines(tidySum) {
+		problems = append(problems, "go.sum is not tidy")
+	}
+	return problems, nil
+}
+
+// goSumLines returns the sorted lines of a go.sum file, without the checksums of modules of the
+// repo.
+func (modRelease ModuleSetRelease) goSumLines(data []byte) string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || modRelease.isRepoModule(fields[0]) {
+			continue
+		}
+		lines = append(lines, strings.Join(fields, " "))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// checkGoSum returns the requirements of the go.mod file whose go.mod checksum is missing from the
+// go.sum file next to it. Requirements on modules of the repo are not checked, since their new
+// versions are not published yet, and neither are requirements replaced with local paths.
+func (modRelease ModuleSetRelease) checkGoSum(files RepoFiles, modFilePath ModuleFilePath) ([]string, error) {
+	modFile, err := parseModFile(files, modFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := readGoSum(files, filepath.Join(filepath.Dir(string(modFilePath)), "go.sum"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Replacements are keyed by "<path>@<version>", with an empty version for all versions.
+	replaced := make(map[string]*modfile.Replace, len(modFile.Replace))
+	for _, r := range modFile.Replace {
+		replaced[r.Old.Path+"@"+r.Old.Version] = r
+	}
+
+	var problems []string
+	for _, req := range modFile.Require {
+		path, version := req.Mod.Path, req.Mod.Version
+		if modRelease.isRepoModule(path) {
+			continue
+		}
+		// A replacement of a specific version takes precedence over one of all versions.
+		r, ok := replaced[path+"@"+version]
+		if !ok {
+			r, ok = replaced[path+"@"]
+		}
+		if ok {
+			if r.New.Version == "" {
+				continue
+			}
+			path, version = r.New.Path, r.New.Version
+		}
+
+		if !sums[path+" "+version+"/go.mod"] {
+			problems = append(problems, fmt.Sprintf("go.sum is missing the go.mod checksum of %v %v", path, version))
+		}
+	}
+	return problems, nil
+}
+
+// readGoSum returns the set of "<module path> <version>" pairs with a checksum in a go.sum file.
+// A missing go.sum file has no checksums.
+func readGoSum(files RepoFiles, path string) (map[string]bool, error) {
+	data, err := files.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read go.sum file: %w", err)
+	}
+
+	sums := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 {
+			sums[fields[0]+" "+fields[1]] = true
+		}
+	}
+	return sums, scanner.Err()
+}
+
+// parseModFile reads and parses a go.mod file.
+func parseModFile(files RepoFiles, modFilePath ModuleFilePath) (*modfile.File, error) {
+	data, err := files.ReadFile(string(modFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("could not read mod file: %w", err)
+	}
+
+	modFile, err := modfile.Parse(string(modFilePath), data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse go.mod file at %v: %w", modFilePath, err)
+	}
+	return modFile, nil
+}
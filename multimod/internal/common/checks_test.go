@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestParseSkippedChecks(t *testing.T) {
+	skipped, err := ParseSkippedChecks(nil)
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.False(t, skipped.All())
+
+	skipped, err = ParseSkippedChecks([]string{"tidy", "go-sum"})
+	require.NoError(t, err)
+	assert.Equal(t, SkippedChecks{CheckTidy: true, CheckGoSum: true}, skipped)
+	assert.False(t, skipped.All())
+
+	skipped, err = ParseSkippedChecks([]string{"all"})
+	require.NoError(t, err)
+	assert.True(t, skipped.All())
+
+	_, err = ParseSkippedChecks([]string{"replace"})
+	assert.ErrorContains(t, err, `unknown check "replace"`)
+}
+
+func TestRunPreTagChecks(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	versioningFilename := filepath.Join(tmpRootDir, "versions.yaml")
+	files := map[string][]byte{
+		versioningFilename: []byte("module-sets:\n" +
+			"  checked:\n    version: v1.0.0\n    modules:\n      - example.com/test/a\n      - example.com/test/b\n" +
+			"  other:\n    version: v0.1.0\n    modules:\n      - example.com/test/c\n"),
+		filepath.Join(tmpRootDir, "a", "go.mod"): []byte("module example.com/test/a\n\ngo 1.19\n\n" +
+			"require example.com/ext v1.0.0\n"),
+		filepath.Join(tmpRootDir, "a", "go.sum"): []byte("example.com/ext v1.0.0 h1:abc=\n" +
+			"example.com/ext v1.0.0/go.mod h1:def=\n"),
+		filepath.Join(tmpRootDir, "b", "go.mod"): []byte("module example.com/test/b\n\ngo 1.19\n\n" +
+			"require (\n\texample.com/ext v1.0.0\n\texample.com/old v1.0.0\n\texample.com/test/c v0.1.0\n)\n\n" +
+			"replace example.com/test/c => ../c\n\nreplace example.com/old => example.com/new v1.1.0\n"),
+		filepath.Join(tmpRootDir, "b", "go.sum"): []byte("example.com/new v1.1.0/go.mod h1:abc=\n"),
+		filepath.Join(tmpRootDir, "c", "go.mod"): []byte("module example.com/test/c\n\ngo 1.19\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files))
+
+	msr, err := NewModuleSetRelease(versioningFilename, "checked", tmpRootDir)
+	require.NoError(t, err)
+	repoFiles, err := WorkingTreeFiles(tmpRootDir)
+	require.NoError(t, err)
+
+	err = msr.RunPreTagChecks(repoFiles, PreTagCheckOptions{Skip: SkippedChecks{CheckTidy: true}})
+	var checksErr *errPreTagChecksFailed
+	require.ErrorAs(t, err, &checksErr)
+	assert.Equal(t, []string{
+		"local-replace: example.com/test/b: replace example.com/test/c => ../c points at a local path",
+		"go-sum: example.com/test/b: go.sum is missing the go.mod checksum of example.com/ext v1.0.0",
+	}, checksErr.problems)
+
+	assert.NoError(t, msr.RunPreTagChecks(repoFiles, PreTagCheckOptions{Skip: SkippedChecks{CheckTidy: true, CheckLocalReplace: true, CheckGoSum: true}}))
+
+	// Only the modules of the set are checked.
+	msr, err = NewModuleSetRelease(versioningFilename, "other", tmpRootDir)
+	require.NoError(t, err)
+	assert.NoError(t, msr.RunPreTagChecks(repoFiles, PreTagCheckOptions{Skip: SkippedChecks{CheckTidy: true}}))
+}
+
+func TestCheckTidy(t *testing.T) {
+	// Fail fast instead of looking up unknown modules.
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	tmpRootDir := t.TempDir()
+	versioningFilename := filepath.Join(tmpRootDir, "versions.yaml")
+	files := map[string][]byte{
+		versioningFilename: []byte("module-sets:\n" +
+			"  tidy:\n    version: v1.1.0\n    modules:\n      - example.com/test/a\n      - example.com/test/b\n" +
+			"  untidy:\n    version: v0.1.0\n    modules:\n      - example.com/test/untidy\n      - example.com/test/unused\n" +
+			"  broken:\n    version: v0.1.0\n    modules:\n      - example.com/test/broken\n"),
+		// a and b require each other at the new version of their set, which is not published.
+		filepath.Join(tmpRootDir, "a", "go.mod"):      []byte("module example.com/test/a\n\ngo 1.19\n\nrequire example.com/test/b v1.1.0\n"),
+		filepath.Join(tmpRootDir, "a", "a.go"):        []byte("package a\n\nimport _ \"example.com/test/b\"\n"),
+		filepath.Join(tmpRootDir, "b", "go.mod"):      []byte("module example.com/test/b\n\ngo 1.19\n\nrequire example.com/test/a v1.1.0\n"),
+		filepath.Join(tmpRootDir, "b", "b.go"):        []byte("package b\n"),
+		filepath.Join(tmpRootDir, "b", "b_test.go"):   []byte("package b_test\n\nimport _ \"example.com/test/a\"\n"),
+		filepath.Join(tmpRootDir, "untidy", "go.mod"): []byte("module example.com/test/untidy\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "untidy", "x.go"):   []byte("package x\n"),
+		filepath.Join(tmpRootDir, "unused", "go.mod"): []byte("module example.com/test/unused\n\ngo 1.19\n\nrequire example.com/test/a v1.1.0\n"),
+		filepath.Join(tmpRootDir, "unused", "x.go"):   []byte("package x\n"),
+		filepath.Join(tmpRootDir, "broken", "go.mod"): []byte("module example.com/test/broken\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "broken", "x.go"):   []byte("package x\n\nimport _ \"example.com/missing\"\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files))
+
+	repoFiles, err := WorkingTreeFiles(tmpRootDir)
+	require.NoError(t, err)
+	msr, err := NewModuleSetRelease(versioningFilename, "tidy", tmpRootDir)
+	require.NoError(t, err)
+	c, err := msr.newTidyCopy(repoFiles)
+	t.Cleanup(func() { require.NoError(t, os.RemoveAll(c.dir)) })
+	require.NoError(t, err)
+
+	for _, modPath := range []ModulePath{"example.com/test/a", "example.com/test/b"} {
+		problems, err := msr.checkTidy(c, modPath, "1.19")
+		require.NoError(t, err)
+		assert.Empty(t, problems, modPath)
+	}
+
+	problems, err := msr.checkTidy(c, "example.com/test/untidy", "1.19")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go.mod is not tidy"}, problems)
+
+	problems, err = msr.checkTidy(c, "example.com/test/unused", "1.19")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go.mod is not tidy"}, problems)
+
+	_, err = msr.checkTidy(c, "example.com/test/broken", "1.19")
+	assert.ErrorContains(t, err, "go mod tidy failed")
+
+	// Neither the repo nor the copy are left changed.
+	for path, expected := range files {
+		actual, err := os.ReadFile(filepath.Clean(path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		rel, err := filepath.Rel(tmpRootDir, path)
+		require.NoError(t, err)
+		if filepath.Ext(path) == ".yaml" {
+			continue
+		}
+		actual, err = os.ReadFile(filepath.Join(c.dir, rel))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	for _, dir := range []string{tmpRootDir, c.dir} {
+		_, err = os.Stat(filepath.Join(dir, "untidy", "go.sum"))
+		assert.True(t, os.IsNotExist(err))
+	}
+}
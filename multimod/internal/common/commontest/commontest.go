@@ -20,9 +20,11 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 var (
@@ -75,3 +77,45 @@ func InitNewRepoWithCommit(repoRoot string) (*git.Repository, plumbing.Hash, err
 
 	return repo, commitHash, nil
 }
+
+// CommitAll adds every file of the worktree of repo and commits it, returning the hash of the commit.
+func CommitAll(repo *git.Repository, msg string) (plumbing.Hash, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if err = worktree.AddGlob("."); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("could not add files to git: %w", err)
+	}
+
+	commitHash, err := worktree.Commit(msg, &git.CommitOptions{All: true, Author: TestAuthor})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("could not commit changes to git: %w", err)
+	}
+
+	return commitHash, nil
+}
+
+// InitNewMemoryRepoWithCommit initializes a git repository held in memory, with an empty commit.
+func InitNewMemoryRepoWithCommit() (*git.Repository, plumbing.Hash, error) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("could not initialize in-memory git repo: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	commitHash, err := worktree.Commit("test commit", &git.CommitOptions{
+		Author:            TestAuthor,
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("could not commit changes to git: %w", err)
+	}
+
+	return repo, commitHash, nil
+}
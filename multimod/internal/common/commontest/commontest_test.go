@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,3 +52,24 @@ func TestWriteTempFiles(t *testing.T) {
 		assert.Equal(t, expectedModFile, actual)
 	}
 }
+
+func TestCommitAll(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	repo, firstHash, err := InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	require.NoError(t, WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "go.mod"): []byte("module go.opentelemetry.io/test\n\ngo 1.19\n"),
+	}))
+
+	hash, err := CommitAll(repo, "add module")
+	require.NoError(t, err)
+
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	assert.Equal(t, "add module", commit.Message)
+	assert.Equal(t, []plumbing.Hash{firstHash}, commit.ParentHashes)
+
+	_, err = commit.File("test/go.mod")
+	assert.NoError(t, err)
+}
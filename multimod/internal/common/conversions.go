@@ -16,31 +16,51 @@ package common
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 const (
 	RepoRootTag = ModuleTagName("REPOROOTTAG")
 )
 
-// combineModuleTagNamesAndVersion combines a slice of ModuleTagNames with the version number and returns
-// the new full module tags.
-func combineModuleTagNamesAndVersion(modTagNames []ModuleTagName, version string) []string {
+// combineModuleTagNamesAndVersion combines a slice of ModuleTagNames with the tag prefix of their
+// module set and the version number and returns the new full module tags.
+func combineModuleTagNamesAndVersion(tagPrefix string, modTagNames []ModuleTagName, version string) []string {
 	var modFullTags []string
 	for _, modTagName := range modTagNames {
-		var newFullTag string
-		if modTagName == RepoRootTag {
-			newFullTag = version
-		} else {
-			newFullTag = string(modTagName) + "/" + version
-		}
-		modFullTags = append(modFullTags, newFullTag)
+		modFullTags = append(modFullTags, ModuleTagPrefix(tagPrefix, modTagName)+version)
 	}
 
 	return modFullTags
 }
 
+// ModuleTagPrefix returns the part of the full tags of a module before their version: the tag
+// prefix of its module set, followed by its tag name and a slash unless it is at the repo root.
+func ModuleTagPrefix(tagPrefix string, modTagName ModuleTagName) string {
+	if modTagName == RepoRootTag {
+		return tagPrefix
+	}
+	return tagPrefix + string(modTagName) + "/"
+}
+
+// TagVersion returns the version of a full tag of a module whose tags start with modTagPrefix, as
+// returned by ModuleTagPrefix. It returns false if tag is not a valid tag of that module.
+func TagVersion(tag, modTagPrefix string) (string, bool) {
+	if !strings.HasPrefix(tag, modTagPrefix) {
+		return "", false
+	}
+	version := strings.TrimPrefix(tag, modTagPrefix)
+	return version, semver.IsValid(version)
+}
+
 // ModulePathsToTagNames returns a list of tag names from a list of module's import paths.
+// Modules in a major version subdirectory (such as "foo/v2" for the module "example.com/foo/v2")
+// are tagged without that subdirectory, as in the major branch layout.
 func ModulePathsToTagNames(modPaths []ModulePath, modPathMap ModulePathMap, repoRoot string) ([]ModuleTagName, error) {
 	modFilePaths, err := modulePathsToFilePaths(modPaths, modPathMap)
 	if err != nil {
@@ -52,9 +72,33 @@ func ModulePathsToTagNames(modPaths []ModulePath, modPathMap ModulePathMap, repo
 		return nil, fmt.Errorf("could not convert module file paths to tag names: %w", err)
 	}
 
+	for i, modPath := range modPaths {
+		modTagNames[i] = trimMajorVersionDir(modTagNames[i], modPath)
+	}
+
 	return modTagNames, nil
 }
 
+// trimMajorVersionDir removes the major version subdirectory from the tag name of the module at
+// modPath, if the module is in such a subdirectory. The go command looks up the versions of a module
+// with a major version suffix under the directory without that suffix.
+func trimMajorVersionDir(modTagName ModuleTagName, modPath ModulePath) ModuleTagName {
+	_, pathMajor, ok := module.SplitPathVersion(string(modPath))
+	if !ok || !strings.HasPrefix(pathMajor, "/") {
+		return modTagName
+	}
+
+	majorDir := strings.TrimPrefix(pathMajor, "/")
+	switch {
+	case string(modTagName) == majorDir:
+		return RepoRootTag
+	case strings.HasSuffix(string(modTagName), "/"+majorDir):
+		return ModuleTagName(strings.TrimSuffix(string(modTagName), "/"+majorDir))
+	default:
+		return modTagName
+	}
+}
+
 // modulePathsToFilePaths returns a list of absolute file paths from a list of module's import paths.
 func modulePathsToFilePaths(modPaths []ModulePath, modPathMap ModulePathMap) ([]ModuleFilePath, error) {
 	var modFilePaths []ModuleFilePath
@@ -105,3 +149,28 @@ func moduleFilePathsToTagNames(modFilePaths []ModuleFilePath, repoRoot string) (
 
 	return modNames, nil
 }
+
+// ModuleDir returns the directory of the go.mod file at modFilePath relative to repoRoot, in slash
+// form with a trailing slash, or an empty string for the module at the repo root.
+func ModuleDir(modFilePath ModuleFilePath, repoRoot string) string {
+	dir, err := filepath.Rel(repoRoot, filepath.Dir(string(modFilePath)))
+	if err != nil || dir == "." {
+		return ""
+	}
+	return filepath.ToSlash(dir) + "/"
+}
+
+// NestedModuleDirs returns the sorted directories of the modules in modPathMap nested in dir, a
+// module directory as returned by ModuleDir. Files under these directories belong to the nested
+// modules rather than to the module in dir.
+func NestedModuleDirs(dir string, modPathMap ModulePathMap, repoRoot string) []string {
+	var nested []string
+	for _, modFilePath := range modPathMap {
+		modDir := ModuleDir(modFilePath, repoRoot)
+		if modDir != "" && modDir != dir && strings.HasPrefix(modDir, dir) {
+			nested = append(nested, modDir)
+		}
+	}
+	sort.Strings(nested)
+	return nested
+}
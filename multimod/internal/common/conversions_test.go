@@ -38,11 +38,44 @@ func TestCombineModuleTagNamesAndVersion(t *testing.T) {
 		"v1.2.3-RC1+meta-RC1",
 	}
 
-	actual := combineModuleTagNamesAndVersion(modTagNames, version)
+	actual := combineModuleTagNamesAndVersion("", modTagNames, version)
 
 	assert.Equal(t, expected, actual)
 }
 
+func TestModuleTagPrefix(t *testing.T) {
+	assert.Equal(t, "", ModuleTagPrefix("", RepoRootTag))
+	assert.Equal(t, "tag1/", ModuleTagPrefix("", "tag1"))
+	assert.Equal(t, "go/", ModuleTagPrefix("go/", RepoRootTag))
+	assert.Equal(t, "go/another/tag3/", ModuleTagPrefix("go/", "another/tag3"))
+}
+
+func TestTagVersion(t *testing.T) {
+	testCases := []struct {
+		tag          string
+		modTagPrefix string
+		expected     string
+		ok           bool
+	}{
+		{tag: "v1.2.3", modTagPrefix: "", expected: "v1.2.3", ok: true},
+		{tag: "tag1/v1.2.3", modTagPrefix: "tag1/", expected: "v1.2.3", ok: true},
+		{tag: "go/tag1/v1.2.3-rc.1", modTagPrefix: "go/tag1/", expected: "v1.2.3-rc.1", ok: true},
+		{tag: "tag1/v1.2.3", modTagPrefix: "", ok: false},
+		{tag: "tag2/v1.2.3", modTagPrefix: "tag1/", ok: false},
+		{tag: "tag1/latest", modTagPrefix: "tag1/", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			actual, ok := TagVersion(tc.tag, tc.modTagPrefix)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestModulePathsToTagNames(t *testing.T) {
 	modPaths := []ModulePath{
 		"go.opentelemetry.io/test/test1",
@@ -74,6 +107,41 @@ func TestModulePathsToTagNames(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestModulePathsToTagNamesMajorVersions(t *testing.T) {
+	modPaths := []ModulePath{
+		"go.opentelemetry.io/test/v2",
+		"go.opentelemetry.io/test/sub/v3",
+		"go.opentelemetry.io/branch/v2",
+		"go.opentelemetry.io/v4",
+		"gopkg.in/yaml.v3",
+	}
+
+	modPathMap := ModulePathMap{
+		// major subdirectory layout
+		"go.opentelemetry.io/test/v2":     "root/test/v2/go.mod",
+		"go.opentelemetry.io/test/sub/v3": "root/test/sub/v3/go.mod",
+		// major branch layout
+		"go.opentelemetry.io/branch/v2": "root/branch/go.mod",
+		"go.opentelemetry.io/v4":        "root/v4/go.mod",
+		"gopkg.in/yaml.v3":              "root/yaml/go.mod",
+	}
+
+	expected := []ModuleTagName{
+		"test",
+		"test/sub",
+		"branch",
+		RepoRootTag,
+		"yaml",
+	}
+
+	actual, err := ModulePathsToTagNames(modPaths, modPathMap, "root")
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, []string{"test/v2.0.0", "test/sub/v2.0.0", "branch/v2.0.0", "v2.0.0", "yaml/v2.0.0"},
+		combineModuleTagNamesAndVersion("", actual, "v2.0.0"))
+}
+
 func TestModulePathsToFilePaths(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -239,3 +307,24 @@ func TestModuleFilePathsToTagNames(t *testing.T) {
 		})
 	}
 }
+
+func TestModuleDir(t *testing.T) {
+	assert.Equal(t, "", ModuleDir("root/go.mod", "root"))
+	assert.Equal(t, "test/test1/", ModuleDir("root/test/test1/go.mod", "root"))
+	assert.Equal(t, "foo/v2/", ModuleDir("root/foo/v2/go.mod", "root"))
+}
+
+func TestNestedModuleDirs(t *testing.T) {
+	modPathMap := ModulePathMap{
+		"example.com":           "root/go.mod",
+		"example.com/a":         "root/a/go.mod",
+		"example.com/a/b":       "root/a/b/go.mod",
+		"example.com/a/b/c":     "root/a/b/c/go.mod",
+		"example.com/ab":        "root/ab/go.mod",
+		"example.com/unrelated": "root/unrelated/go.mod",
+	}
+
+	assert.Equal(t, []string{"a/", "a/b/", "a/b/c/", "ab/", "unrelated/"}, NestedModuleDirs("", modPathMap, "root"))
+	assert.Equal(t, []string{"a/b/", "a/b/c/"}, NestedModuleDirs("a/", modPathMap, "root"))
+	assert.Nil(t, NestedModuleDirs("ab/", modPathMap, "root"))
+}
@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+)
+
+// DependencyMap maps each module to the modules of the same repo that it requires.
+type DependencyMap map[ModulePath][]ModulePath
+
+// Dependencies returns the dependencies of each module listed in a module set on the other modules
+// listed in module sets, as defined by the require sections of their go.mod files. Modules
+// without a go.mod file in the repo have no dependencies. The dependencies of each module are
+// sorted.
+func (modVersioning ModuleVersioning) Dependencies() (DependencyMap, error) {
+	dependencies := make(DependencyMap)
+
+	for modPath := range modVersioning.ModInfoMap {
+		modFilePath, ok := modVersioning.ModPathMap[modPath]
+		if !ok {
+			continue
+		}
+		modData, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read mod file: %w", err)
+		}
+
+		modFile, err := modfile.Parse("", modData, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse go.mod file at %v: %w", modFilePath, err)
+		}
+
+		for _, dep := range modFile.Require {
+			// check if dependency is in the same repo (i.e. if it exists in the module versioning file)
+			if _, exists := modVersioning.ModInfoMap[ModulePath(dep.Mod.Path)]; exists {
+				dependencies[modPath] = append(dependencies[modPath], ModulePath(dep.Mod.Path))
+			}
+		}
+		sort.Slice(dependencies[modPath], func(i, j int) bool { return dependencies[modPath][i] < dependencies[modPath][j] })
+	}
+
+	return dependencies, nil
+}
+
+// ModuleSetDependencies returns the module sets that each module set depends on, that is the sets
+// of the modules required by its modules. The dependencies of each set are sorted.
+func (modVersioning ModuleVersioning) ModuleSetDependencies() (map[string][]string, error) {
+	dependencies, err := modVersioning.Dependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[[2]string]bool)
+	setDependencies := make(map[string][]string)
+	for modPath, deps := range dependencies {
+		from := modVersioning.ModInfoMap[modPath].ModuleSetName
+		for _, dep := range deps {
+			to := modVersioning.ModInfoMap[dep].ModuleSetName
+			if from != to && !seen[[2]string{from, to}] {
+				seen[[2]string{from, to}] = true
+				setDependencies[from] = append(setDependencies[from], to)
+			}
+		}
+	}
+	for _, deps := range setDependencies {
+		sort.Strings(deps)
+	}
+
+	return setDependencies, nil
+}
+
+// ModuleSetOrder returns the given module sets in the order they must be released: every set
+// comes after the sets it depends on, directly or through sets that are not given. Sets that do
+// not depend on each other are sorted by name. An error is returned if the sets depend on each
+// other in a cycle.
+func (modVersioning ModuleVersioning) ModuleSetOrder(modSetNames []string) ([]string, error) {
+	setDependencies, err := modVersioning.ModuleSetDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the sets given and the sets they depend on need to be ordered.
+	reachable := make(map[string]bool)
+	queue := make([]string, 0, len(modSetNames))
+	for _, name := range modSetNames {
+		if _, ok := modVersioning.ModSetMap[name]; !ok {
+			return nil, &errModuleSetNotFound{modSetName: name}
+		}
+		if !reachable[name] {
+			reachable[name] = true
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range setDependencies[name] {
+			if !reachable[dep] {
+				reachable[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	// Kahn's algorithm, always releasing the first ready set by name.
+	pending := make(map[string]int, len(reachable))
+	dependents := make(map[string][]string)
+	var ready []string
+	for name := range reachable {
+		pending[name] = len(setDependencies[name])
+		for _, dep := range setDependencies[name] {
+			dependents[dep] = append(dependents[dep], name)
+		}
+		if pending[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	var order []string
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(reachable) {
+		return nil, &errModuleSetCycle{cycle: findCycle(setDependencies, pending)}
+	}
+
+	given := make(map[string]bool, len(modSetNames))
+	for _, name := range modSetNames {
+		given[name] = true
+	}
+	ordered := make([]string, 0, len(modSetNames))
+	for _, name := range order {
+		if given[name] {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered, nil
+}
+
+// findCycle returns a cycle among the sets left with pending dependencies by Kahn's algorithm,
+// starting and ending with the same set. Every such set depends on another one, so following the
+// first pending dependency from any of them eventually leads back to a set already visited.
+func findCycle(setDependencies map[string][]string, pending map[string]int) []string {
+	var start string
+	for name, n := range pending {
+		if n > 0 && (start == "" || name < start) {
+			start = name
+		}
+	}
+
+	visited := make(map[string]int)
+	var path []string
+	for name := start; ; {
+		if i, ok := visited[name]; ok {
+			return append(path[i:], name)
+		}
+		visited[name] = len(path)
+		path = append(path, name)
+
+		for _, dep := range setDependencies[name] {
+			if pending[dep] > 0 {
+				name = dep
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+// newDependencyTestVersioning writes a module set per entry of requires, each with a single
+// module requiring the modules of the listed sets.
+func newDependencyTestVersioning(t *testing.T, requires map[string][]string) ModuleVersioning {
+	tmpRootDir := t.TempDir()
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+
+	files := make(map[string][]byte)
+	var versions strings.Builder
+	versions.WriteString("module-sets:\n")
+	for name, deps := range requires {
+		fmt.Fprintf(&versions, "  %v:\n    version: v1.0.0\n    modules:\n      - example.com/%v\n", name, name)
+
+		var modFile strings.Builder
+		fmt.Fprintf(&modFile, "module example.com/%v\n\ngo 1.19\n", name)
+		for _, dep := range deps {
+			fmt.Fprintf(&modFile, "\nrequire example.com/%v v1.0.0\n", dep)
+		}
+		files[filepath.Join(tmpRootDir, name, "go.mod")] = []byte(modFile.String())
+	}
+	files[versionsFile] = []byte(versions.String())
+	require.NoError(t, commontest.WriteTempFiles(files))
+
+	modVersioning, err := NewModuleVersioning(versionsFile, tmpRootDir)
+	require.NoError(t, err)
+	return modVersioning
+}
+
+func TestModuleSetDependencies(t *testing.T) {
+	modVersioning := newDependencyTestVersioning(t, map[string][]string{
+		"a": {"c", "b"},
+		"b": {"c"},
+		"c": nil,
+	})
+
+	actual, err := modVersioning.ModuleSetDependencies()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"a": {"b", "c"}, "b": {"c"}}, actual)
+}
+
+func TestModuleSetOrder(t *testing.T) {
+	modVersioning := newDependencyTestVersioning(t, map[string][]string{
+		"api":     nil,
+		"sdk":     {"api"},
+		"bridge":  {"sdk", "api"},
+		"contrib": {"bridge"},
+		"tools":   nil,
+		"cycle1":  {"cycle2"},
+		"cycle2":  {"cycle3", "api"},
+		"cycle3":  {"cycle1"},
+		"user":    {"cycle1"},
+	})
+
+	testCases := []struct {
+		name        string
+		modSetNames []string
+		expected    []string
+		expectedErr string
+		expectedIs  error
+	}{
+		{
+			name:        "all acyclic sets",
+			modSetNames: []string{"contrib", "tools", "bridge", "sdk", "api"},
+			expected:    []string{"api", "sdk", "bridge", "contrib", "tools"},
+		},
+		{
+			// bridge is not released, but contrib still depends on sdk through it.
+			name:        "transitive dependency",
+			modSetNames: []string{"contrib", "sdk"},
+			expected:    []string{"sdk", "contrib"},
+		},
+		{
+			name:        "independent sets",
+			modSetNames: []string{"tools", "api"},
+			expected:    []string{"api", "tools"},
+		},
+		{
+			name:        "cycle",
+			modSetNames: []string{"api", "user"},
+			expectedErr: "module sets depend on each other, so no release order exists: cycle1 -> cycle2 -> cycle3 -> cycle1",
+			expectedIs:  ErrModuleSetCycle,
+		},
+		{
+			name:        "unknown set",
+			modSetNames: []string{"missing"},
+			expectedErr: "could not find module set missing in versioning file",
+			expectedIs:  ErrModuleSetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := modVersioning.ModuleSetOrder(tc.modSetNames)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.ErrorIs(t, err, tc.expectedIs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
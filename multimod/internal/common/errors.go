@@ -15,8 +15,22 @@
 package common
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Errors matched with errors.Is by the errors of this package, so that callers can tell failures
+// apart without parsing messages.
+var (
+	ErrInvalidVersioningFile = errors.New("invalid versioning file")
+	ErrModuleSetNotFound     = errors.New("module set not found")
+	ErrWorkingTreeNotClean   = errors.New("working tree not clean")
+	ErrPreTagChecksFailed    = errors.New("pre-tag checks failed")
+	ErrVerificationFailed    = errors.New("verification failed")
+	ErrModuleSetCycle        = errors.New("module sets depend on each other")
 )
 
 type ErrGitTagsAlreadyExist struct {
@@ -48,3 +62,115 @@ type errWorkingTreeNotClean struct{}
 func (e *errWorkingTreeNotClean) Error() string {
 	return "working tree not clean"
 }
+
+func (e *errWorkingTreeNotClean) Is(target error) bool {
+	return target == ErrWorkingTreeNotClean
+}
+
+// errModuleSetNotFound is returned when a module set is not listed in the versioning file.
+type errModuleSetNotFound struct {
+	modSetName string
+	// filename is the versioning file, if known.
+	filename string
+}
+
+func (e *errModuleSetNotFound) Error() string {
+	if e.filename == "" {
+		return fmt.Sprintf("could not find module set %v in versioning file", e.modSetName)
+	}
+	return fmt.Sprintf("could not find module set %v in versioning file %v", e.modSetName, e.filename)
+}
+
+func (e *errModuleSetNotFound) Is(target error) bool {
+	return target == ErrModuleSetNotFound
+}
+
+// NewErrModuleSetNotFound returns the error reported when the module set modSetName is not listed
+// in the versioning file filename, which may be empty if unknown.
+func NewErrModuleSetNotFound(modSetName, filename string) error {
+	return &errModuleSetNotFound{modSetName: modSetName, filename: filename}
+}
+
+// errInvalidVersioningFile is returned when a versioning file does not conform to the expected
+// schema. Every problem found is reported along with its position in the file.
+type errInvalidVersioningFile struct {
+	filename string
+	problems []schemaProblem
+}
+
+func (e *errInvalidVersioningFile) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid versioning file %v:", e.filename)
+	for _, p := range e.problems {
+		fmt.Fprintf(&b, "\n%v:%d:%d: %s", e.filename, p.line, p.column, p.msg)
+	}
+	return b.String()
+}
+
+func (e *errInvalidVersioningFile) Is(target error) bool {
+	return target == ErrInvalidVersioningFile
+}
+
+// errMajorVersionMismatch is returned when the major version of a module set does not match the
+// major version suffix of some of its module paths.
+type errMajorVersionMismatch struct {
+	modSetName string
+	version    string
+	modPaths   []ModulePath
+}
+
+func (e *errMajorVersionMismatch) Error() string {
+	modPaths := make([]string, len(e.modPaths))
+	for i, modPath := range e.modPaths {
+		modPaths[i] = string(modPath)
+	}
+	return fmt.Sprintf("module set %v has version %v, but the major version suffix of these module paths does not match %v:\n%s",
+		e.modSetName, e.version, semver.Major(e.version), strings.Join(modPaths, "\n"))
+}
+
+func (e *errMajorVersionMismatch) Is(target error) bool {
+	return target == ErrVerificationFailed
+}
+
+// errModuleSetCycle is returned when module sets depend on each other, so that they cannot be
+// released one after the other.
+type errModuleSetCycle struct {
+	// cycle lists the module sets of the cycle, starting and ending with the same set.
+	cycle []string
+}
+
+func (e *errModuleSetCycle) Error() string {
+	return fmt.Sprintf("module sets depend on each other, so no release order exists: %v", strings.Join(e.cycle, " -> "))
+}
+
+func (e *errModuleSetCycle) Is(target error) bool {
+	return target == ErrModuleSetCycle
+}
+
+// errPreTagChecksFailed is returned when the modules of a module set fail pre-tag checks. Every
+// problem found is reported along with the check that found it.
+type errPreTagChecksFailed struct {
+	problems []string
+}
+
+func (e *errPreTagChecksFailed) Error() string {
+	return fmt.Sprintf("pre-tag checks failed, fix the problems or skip the checks:\n%s", strings.Join(e.problems, "\n"))
+}
+
+func (e *errPreTagChecksFailed) Is(target error) bool {
+	return target == ErrPreTagChecksFailed
+}
+
+// errModulePatternConflicts is returned when modules are matched by more than one glob pattern of
+// the module sets in a versioning file.
+type errModulePatternConflicts struct {
+	conflicts []string
+}
+
+func (e *errModulePatternConflicts) Error() string {
+	return fmt.Sprintf("modules matched by more than one module set pattern:\n%s", strings.Join(e.conflicts, "\n"))
+}
+
+func (e *errModulePatternConflicts) Is(target error) bool {
+	return target == ErrInvalidVersioningFile
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// textEdit replaces length bytes at offset in a file with text.
+type textEdit struct {
+	offset int
+	length int
+	text   string
+}
+
+// applyTextEdits returns a copy of data with all edits applied. Edits must not overlap.
+func applyTextEdits(data []byte, edits []textEdit) []byte {
+	edits = append([]textEdit(nil), edits...)
+	// Apply from the end of the file so that earlier offsets stay valid.
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+
+	data = append([]byte(nil), data...)
+	for _, edit := range edits {
+		data = append(data[:edit.offset], append([]byte(edit.text), data[edit.offset+edit.length:]...)...)
+	}
+	return data
+}
+
+// FileEdit holds the current and the planned content of a file.
+type FileEdit struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// Changed returns true if the planned content differs from the current content.
+func (e FileEdit) Changed() bool {
+	return !bytes.Equal(e.Old, e.New)
+}
+
+// Diff returns the edit as a unified diff, with the file named relative to repoRoot.
+func (e FileEdit) Diff(repoRoot string) (string, error) {
+	name := e.Path
+	if rel, err := filepath.Rel(repoRoot, e.Path); err == nil {
+		name = filepath.ToSlash(rel)
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(e.Old),
+		B:        splitLines(e.New),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
+
+// splitLines splits data into lines, each ending in a newline.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// Write overwrites the file with the planned content, keeping its permissions.
+func (e FileEdit) Write() error {
+	info, err := os.Stat(e.Path)
+	if err != nil {
+		return fmt.Errorf("could not stat %v: %w", e.Path, err)
+	}
+
+	if err = os.WriteFile(e.Path, e.New, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("error overwriting %v: %w", e.Path, err)
+	}
+
+	return nil
+}
+
+// WriteFileEdits writes every changed file in edits.
+func WriteFileEdits(edits []FileEdit) error {
+	for _, edit := range edits {
+		if !edit.Changed() {
+			continue
+		}
+		if err := edit.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintFileEdits writes the unified diff of every changed file in edits to w.
+func PrintFileEdits(w io.Writer, edits []FileEdit, repoRoot string) error {
+	for _, edit := range edits {
+		if !edit.Changed() {
+			continue
+		}
+
+		diff, err := edit.Diff(repoRoot)
+		if err != nil {
+			return fmt.Errorf("could not diff %v: %w", edit.Path, err)
+		}
+		if _, err = io.WriteString(w, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
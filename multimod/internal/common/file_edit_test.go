@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileEditDiff(t *testing.T) {
+	tmpRootDir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		edit     FileEdit
+		expected string
+	}{
+		{
+			name: "changed line",
+			edit: FileEdit{
+				Path: filepath.Join(tmpRootDir, "test", "go.mod"),
+				Old:  []byte("module test\n\ngo 1.16\n"),
+				New:  []byte("module test\n\ngo 1.19\n"),
+			},
+			expected: "--- a/test/go.mod\n+++ b/test/go.mod\n@@ -1,3 +1,3 @@\n module test\n \n-go 1.16\n+go 1.19\n",
+		},
+		{
+			name: "no trailing newline",
+			edit: FileEdit{
+				Path: filepath.Join(tmpRootDir, "go.mod"),
+				Old:  []byte("module test\nrequire a v1.0.0"),
+				New:  []byte("module test\nrequire a v1.1.0"),
+			},
+			expected: "--- a/go.mod\n+++ b/go.mod\n@@ -1,2 +1,2 @@\n module test\n-require a v1.0.0\n+require a v1.1.0\n",
+		},
+		{
+			name: "unchanged",
+			edit: FileEdit{
+				Path: filepath.Join(tmpRootDir, "go.mod"),
+				Old:  []byte("module test\n"),
+				New:  []byte("module test\n"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected != "", tc.edit.Changed())
+
+			var buf bytes.Buffer
+			require.NoError(t, PrintFileEdits(&buf, []FileEdit{tc.edit}, tmpRootDir))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestWriteFileEdits(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	changedPath := filepath.Join(tmpRootDir, "changed.go")
+	unchangedPath := filepath.Join(tmpRootDir, "unchanged.go")
+	require.NoError(t, os.WriteFile(changedPath, []byte("old"), 0640))
+	require.NoError(t, os.WriteFile(unchangedPath, []byte("on disk"), 0600))
+
+	require.NoError(t, WriteFileEdits([]FileEdit{
+		{Path: changedPath, Old: []byte("old"), New: []byte("new")},
+		// Unchanged edits are not written, even if the file on disk differs.
+		{Path: unchangedPath, Old: []byte("same"), New: []byte("same")},
+	}))
+
+	actual, err := os.ReadFile(changedPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(actual))
+
+	info, err := os.Stat(changedPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	actual, err = os.ReadFile(unchangedPath)
+	require.NoError(t, err)
+	assert.Equal(t, "on disk", string(actual))
+
+	assert.Error(t, WriteFileEdits([]FileEdit{
+		{Path: filepath.Join(tmpRootDir, "missing.go"), Old: []byte("old"), New: []byte("new")},
+	}))
+}
@@ -36,7 +36,7 @@ func CommitChangesToNewBranch(branchName string, commitMessage string, repo *git
 		return plumbing.ZeroHash, errors.New("could not store original head ref")
 	}
 
-	if _, err = checkoutNewBranch(branchName, repo); err != nil {
+	if _, err = CheckoutNewBranch(branchName, repo); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("createPrereleaseBranch failed: %w", err)
 	}
 
@@ -46,12 +46,12 @@ func CommitChangesToNewBranch(branchName string, commitMessage string, repo *git
 	}
 
 	// return to original branch
-	err = checkoutExistingBranch(origRef.Name(), repo)
-	if err != nil {
-		log.Fatal("unable to checkout original branch")
+	if err = CheckoutExistingBranch(origRef.Name(), repo); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("committed %v to branch %v, but could not checkout original branch %v: %w",
+			hash, branchName, origRef.Name().Short(), err)
 	}
 
-	return hash, err
+	return hash, nil
 }
 
 func CommitChanges(commitMessage string, repo *git.Repository, customAuthor *object.Signature) (plumbing.Hash, error) {
@@ -85,7 +85,8 @@ func CommitChanges(commitMessage string, repo *git.Repository, customAuthor *obj
 	return hash, nil
 }
 
-func checkoutExistingBranch(branchRefName plumbing.ReferenceName, repo *git.Repository) error {
+// CheckoutExistingBranch checks out an existing branch, discarding changes to the worktree.
+func CheckoutExistingBranch(branchRefName plumbing.ReferenceName, repo *git.Repository) error {
 	worktree, err := repo.Worktree()
 	if err != nil {
 		return &errGetWorktreeFailed{reason: err}
@@ -105,7 +106,9 @@ func checkoutExistingBranch(branchRefName plumbing.ReferenceName, repo *git.Repo
 	return nil
 }
 
-func checkoutNewBranch(branchName string, repo *git.Repository) (plumbing.ReferenceName, error) {
+// CheckoutNewBranch creates a new branch at the current HEAD and checks it out, keeping changes to
+// the worktree. It returns the reference name of the new branch.
+func CheckoutNewBranch(branchName string, repo *git.Repository) (plumbing.ReferenceName, error) {
 	worktree, err := repo.Worktree()
 	if err != nil {
 		return "", &errGetWorktreeFailed{reason: err}
@@ -155,3 +158,31 @@ func VerifyWorkingTreeClean(repo *git.Repository) error {
 
 	return nil
 }
+
+// TagCommit returns the commit that the tag named tagName points at. Lightweight tags point at the
+// commit directly, while annotated tags point at a tag object, which may in turn point at another
+// tag object. An error wrapping git.ErrTagNotFound is returned if the tag does not exist.
+func TagCommit(repo *git.Repository, tagName string) (*object.Commit, error) {
+	tagRef, err := repo.Tag(tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := repo.Object(plumbing.AnyObject, tagRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("could not get object of tag %v: %w", tagName, err)
+	}
+
+	for {
+		switch o := obj.(type) {
+		case *object.Commit:
+			return o, nil
+		case *object.Tag:
+			if obj, err = o.Object(); err != nil {
+				return nil, fmt.Errorf("could not get target of tag object %v: %w", o.Hash, err)
+			}
+		default:
+			return nil, fmt.Errorf("tag %v points at a %v, not a commit", tagName, obj.Type())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestTagCommit(t *testing.T) {
+	repo, commitHash, err := commontest.InitNewMemoryRepoWithCommit()
+	require.NoError(t, err)
+
+	_, err = repo.CreateTag("lightweight/v1.0.0", commitHash, nil)
+	require.NoError(t, err)
+
+	annotated, err := repo.CreateTag("annotated/v1.0.0", commitHash, &git.CreateTagOptions{
+		Message: "annotated tag",
+		Tagger:  commontest.TestAuthor,
+	})
+	require.NoError(t, err)
+
+	// A tag of the annotated tag object, which must be followed to the commit.
+	_, err = repo.CreateTag("nested/v1.0.0", annotated.Hash(), &git.CreateTagOptions{
+		Message: "tag of a tag",
+		Tagger:  commontest.TestAuthor,
+	})
+	require.NoError(t, err)
+
+	commit, err := repo.CommitObject(commitHash)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("tree/v1.0.0", commit.TreeHash, nil)
+	require.NoError(t, err)
+
+	for _, tagName := range []string{"lightweight/v1.0.0", "annotated/v1.0.0", "nested/v1.0.0"} {
+		t.Run(tagName, func(t *testing.T) {
+			actual, err := TagCommit(repo, tagName)
+			require.NoError(t, err)
+			assert.Equal(t, commitHash, actual.Hash)
+		})
+	}
+
+	_, err = TagCommit(repo, "tree/v1.0.0")
+	assert.ErrorContains(t, err, "not a commit")
+
+	_, err = TagCommit(repo, "missing/v1.0.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
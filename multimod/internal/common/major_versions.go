@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// MajorVersionMatches returns true if version is a valid version for the module at modPath, i.e. if
+// its major version matches the major version suffix of the path. Paths without a suffix only allow
+// v0 and v1 versions; "/vN" (or ".vN" for gopkg.in paths) suffixes require a vN version.
+func MajorVersionMatches(modPath ModulePath, version string) bool {
+	_, pathMajor, ok := module.SplitPathVersion(string(modPath))
+	return ok && module.CheckPathMajor(version, pathMajor) == nil
+}
+
+// CheckModuleSetMajorVersion returns an error if the version of a module set does not match the
+// major version suffix of any of its module paths. Invalid versions are not reported.
+func CheckModuleSetMajorVersion(modSetName string, modSet ModuleSet) error {
+	if !semver.IsValid(modSet.Version) {
+		return nil
+	}
+
+	var mismatched []ModulePath
+	for _, modPath := range modSet.Modules {
+		if !MajorVersionMatches(modPath, modSet.Version) {
+			mismatched = append(mismatched, modPath)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		return &errMajorVersionMismatch{
+			modSetName: modSetName,
+			version:    modSet.Version,
+			modPaths:   mismatched,
+		}
+	}
+	return nil
+}
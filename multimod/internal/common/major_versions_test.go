@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMajorVersionMatches(t *testing.T) {
+	testCases := []struct {
+		modPath  ModulePath
+		version  string
+		expected bool
+	}{
+		{modPath: "go.opentelemetry.io/test", version: "v0.1.0", expected: true},
+		{modPath: "go.opentelemetry.io/test", version: "v1.2.3", expected: true},
+		{modPath: "go.opentelemetry.io/test", version: "v2.0.0", expected: false},
+		{modPath: "go.opentelemetry.io/test/v2", version: "v2.0.0-RC1", expected: true},
+		{modPath: "go.opentelemetry.io/test/v2", version: "v1.2.3", expected: false},
+		{modPath: "go.opentelemetry.io/test/v2", version: "v3.0.0", expected: false},
+		{modPath: "go.opentelemetry.io/test/v1", version: "v1.0.0", expected: false},
+		{modPath: "gopkg.in/yaml.v3", version: "v3.0.1", expected: true},
+		{modPath: "gopkg.in/yaml.v3", version: "v2.0.0", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.modPath)+"@"+tc.version, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MajorVersionMatches(tc.modPath, tc.version))
+		})
+	}
+}
+
+func TestCheckModuleSetMajorVersion(t *testing.T) {
+	modSet := ModuleSet{
+		Version: "v2.1.0",
+		Modules: []ModulePath{
+			"go.opentelemetry.io/test/v2",
+			"go.opentelemetry.io/test/other/v2",
+		},
+	}
+	assert.NoError(t, CheckModuleSetMajorVersion("mod-set-1", modSet))
+
+	modSet.Modules = append(modSet.Modules, "go.opentelemetry.io/test/v3", "go.opentelemetry.io/test/other")
+	err := CheckModuleSetMajorVersion("mod-set-1", modSet)
+	require.Error(t, err)
+	assert.Equal(t, "module set mod-set-1 has version v2.1.0, but the major version suffix of these module paths does not match v2:\n"+
+		"go.opentelemetry.io/test/v3\ngo.opentelemetry.io/test/other", err.Error())
+
+	// Invalid versions are reported by the version checks instead.
+	modSet.Version = "2.1.0"
+	assert.NoError(t, CheckModuleSetMajorVersion("mod-set-1", modSet))
+}
@@ -45,7 +45,11 @@ func NewModuleSetRelease(versioningFilename, modSetToUpdate, repoRoot string) (M
 	// get new version and mod tags to update
 	modSet, exists := modVersioning.ModSetMap[modSetToUpdate]
 	if !exists {
-		return ModuleSetRelease{}, fmt.Errorf("could not find module set %v in versioning file", modSetToUpdate)
+		return ModuleSetRelease{}, &errModuleSetNotFound{modSetName: modSetToUpdate}
+	}
+
+	if err = CheckModuleSetMajorVersion(modSetToUpdate, modSet); err != nil {
+		return ModuleSetRelease{}, err
 	}
 
 	// get tag names of mods to update
@@ -79,7 +83,7 @@ func (modRelease ModuleSetRelease) ModSetPaths() []ModulePath {
 
 // ModuleFullTagNames gets the full tag names (including the version) of all modules in the module set to update.
 func (modRelease ModuleSetRelease) ModuleFullTagNames() []string {
-	return combineModuleTagNamesAndVersion(modRelease.TagNames, modRelease.ModSetVersion())
+	return combineModuleTagNamesAndVersion(modRelease.ModSet.TagPrefix, modRelease.TagNames, modRelease.ModSetVersion())
 }
 
 // CheckGitTagsAlreadyExist checks if Git tags have already been created that match the specific module tag name
@@ -38,6 +38,15 @@ func NewModuleVersioning(versioningFilename string, repoRoot string) (ModuleVers
 		return ModuleVersioning{}, fmt.Errorf("error reading versioning file %v: %w", versioningFilename, err)
 	}
 
+	allModPathMap, err := findModules(repoRoot)
+	if err != nil {
+		return ModuleVersioning{}, fmt.Errorf("error building module path map for NewModuleVersioning: %w", err)
+	}
+
+	if err = vCfg.expandModulePatterns(allModPathMap); err != nil {
+		return ModuleVersioning{}, fmt.Errorf("error expanding module patterns of versioning file %v: %w", versioningFilename, err)
+	}
+
 	modSetMap := vCfg.buildModuleSetsMap()
 
 	modInfoMap, err := vCfg.buildModuleMap()
@@ -45,10 +54,7 @@ func NewModuleVersioning(versioningFilename string, repoRoot string) (ModuleVers
 		return ModuleVersioning{}, fmt.Errorf("error building module info map for NewModuleVersioning: %w", err)
 	}
 
-	modPathMap, err := vCfg.BuildModulePathMap(repoRoot)
-	if err != nil {
-		return ModuleVersioning{}, fmt.Errorf("error building module path map for NewModuleVersioning: %w", err)
-	}
+	modPathMap := vCfg.excludeModules(allModPathMap)
 
 	return ModuleVersioning{
 		ModSetMap:  modSetMap,
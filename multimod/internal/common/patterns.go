@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// moduleGlobChars are the characters that make an entry of modules or excluded-modules a pattern.
+const moduleGlobChars = "*?["
+
+// IsModulePattern returns true if s, listed in modules or excluded-modules, is a glob pattern
+// rather than a module path.
+func IsModulePattern(s string) bool {
+	return strings.ContainsAny(s, moduleGlobChars)
+}
+
+// checkModulePattern verifies the syntax of a module path pattern. Each path element is matched
+// as with path.Match, except for "**", which must make up a whole element.
+func checkModulePattern(pattern string) error {
+	for _, elem := range strings.Split(pattern, "/") {
+		if elem == "**" {
+			continue
+		}
+		if strings.Contains(elem, "**") {
+			return fmt.Errorf("invalid module pattern %q: ** must be a whole path element", pattern)
+		}
+		if _, err := path.Match(elem, ""); err != nil {
+			return fmt.Errorf("invalid module pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchModulePattern returns true if modPath matches pattern. The pattern element "**" matches
+// any number of path elements, including none; other elements are matched as with path.Match.
+func matchModulePattern(pattern string, modPath ModulePath) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(string(modPath), "/"))
+}
+
+func matchElems(pattern, elems []string) bool {
+	if len(pattern) == 0 {
+		return len(elems) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elems); i++ {
+			if matchElems(pattern[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], elems[0]); err != nil || !ok {
+		return false
+	}
+	return matchElems(pattern[1:], elems[1:])
+}
+
+// hasModulePatterns returns true if any entry of modules or excluded-modules is a pattern.
+func (versionCfg versionConfig) hasModulePatterns() bool {
+	for _, mod := range versionCfg.ExcludedModules {
+		if IsModulePattern(string(mod)) {
+			return true
+		}
+	}
+	for _, modSet := range versionCfg.ModuleSets {
+		for _, mod := range modSet.Modules {
+			if IsModulePattern(string(mod)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandModulePatterns replaces the patterns listed in excluded-modules and in the modules of
+// every module set with the paths of the modules in modPathMap that they match, in sorted order.
+// Excluded modules and modules listed by path in any module set are never matched by the patterns
+// of module sets. A module matched by more than one pattern is reported as a conflict.
+func (versionCfg *versionConfig) expandModulePatterns(modPathMap ModulePathMap) error {
+	modPaths := make([]ModulePath, 0, len(modPathMap))
+	for modPath := range modPathMap {
+		modPaths = append(modPaths, modPath)
+	}
+	sort.Slice(modPaths, func(i, j int) bool { return modPaths[i] < modPaths[j] })
+
+	versionCfg.ExcludedModules = expandEntries(versionCfg.ExcludedModules, modPaths, nil, nil)
+
+	// unmatched holds the modules that patterns must not match: the excluded modules and the
+	// modules listed by path, which belong to the module set listing them.
+	unmatched := versionCfg.getExcludedModules()
+	setNames := make([]string, 0, len(versionCfg.ModuleSets))
+	for setName, modSet := range versionCfg.ModuleSets {
+		setNames = append(setNames, setName)
+		for _, entry := range modSet.Modules {
+			if !IsModulePattern(string(entry)) {
+				unmatched[entry] = struct{}{}
+			}
+		}
+	}
+	sort.Strings(setNames)
+
+	// matchedBy lists, for every module, the patterns of the module sets that match it.
+	matchedBy := make(map[ModulePath][]string)
+	for _, setName := range setNames {
+		modSet := versionCfg.ModuleSets[setName]
+		modSet.Modules = expandEntries(modSet.Modules, modPaths, unmatched, func(pattern string, modPath ModulePath) {
+			matchedBy[modPath] = append(matchedBy[modPath], fmt.Sprintf("%q in %v", pattern, setName))
+		})
+		versionCfg.ModuleSets[setName] = modSet
+	}
+
+	var conflicts []string
+	for _, modPath := range modPaths {
+		if len(matchedBy[modPath]) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%v is matched by %v", modPath, strings.Join(matchedBy[modPath], ", ")))
+		}
+	}
+	if len(conflicts) > 0 {
+		return &errModulePatternConflicts{conflicts: conflicts}
+	}
+	return nil
+}
+
+// expandEntries returns entries with every pattern replaced by the paths in modPaths that it
+// matches, leaving out excluded modules and modules already listed. Module path entries are kept
+// as they are. matched, if not nil, is called for every module a pattern matches.
+func expandEntries(entries, modPaths []ModulePath, excluded excludedModulesSet, matched func(pattern string, modPath ModulePath)) []ModulePath {
+	expanded := make([]ModulePath, 0, len(entries))
+	listed := make(map[ModulePath]bool, len(entries))
+	for _, entry := range entries {
+		if !IsModulePattern(string(entry)) {
+			listed[entry] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if !IsModulePattern(string(entry)) {
+			expanded = append(expanded, entry)
+			continue
+		}
+		for _, modPath := range modPaths {
+			if _, ok := excluded[modPath]; ok || !matchModulePattern(string(entry), modPath) {
+				continue
+			}
+			if matched != nil {
+				matched(string(entry), modPath)
+			}
+			if !listed[modPath] {
+				listed[modPath] = true
+				expanded = append(expanded, modPath)
+			}
+		}
+	}
+	return expanded
+}
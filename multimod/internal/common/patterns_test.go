@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchModulePattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		modPath  ModulePath
+		expected bool
+	}{
+		{pattern: "go.opentelemetry.io/contrib/*", modPath: "go.opentelemetry.io/contrib/zpages", expected: true},
+		{pattern: "go.opentelemetry.io/contrib/*", modPath: "go.opentelemetry.io/contrib/instrumentation/net/http", expected: false},
+		{pattern: "go.opentelemetry.io/contrib/**", modPath: "go.opentelemetry.io/contrib/instrumentation/net/http", expected: true},
+		{pattern: "go.opentelemetry.io/contrib/**", modPath: "go.opentelemetry.io/contrib", expected: true},
+		{pattern: "go.opentelemetry.io/contrib/**", modPath: "go.opentelemetry.io/contribution", expected: false},
+		{pattern: "go.opentelemetry.io/**/example", modPath: "go.opentelemetry.io/contrib/example", expected: true},
+		{pattern: "go.opentelemetry.io/**/example", modPath: "go.opentelemetry.io/example", expected: true},
+		{pattern: "go.opentelemetry.io/**/example", modPath: "go.opentelemetry.io/contrib/example/v2", expected: false},
+		{pattern: "go.opentelemetry.io/test/test[12]", modPath: "go.opentelemetry.io/test/test2", expected: true},
+		{pattern: "go.opentelemetry.io/test/test?", modPath: "go.opentelemetry.io/test/test10", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+string(tc.modPath), func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchModulePattern(tc.pattern, tc.modPath))
+		})
+	}
+}
+
+func TestCheckModulePattern(t *testing.T) {
+	assert.NoError(t, checkModulePattern("go.opentelemetry.io/contrib/**"))
+	assert.NoError(t, checkModulePattern("go.opentelemetry.io/contrib/*/test[0-9]"))
+	assert.ErrorContains(t, checkModulePattern("go.opentelemetry.io/contrib**"), "** must be a whole path element")
+	assert.ErrorContains(t, checkModulePattern("go.opentelemetry.io/test[0-9"), "syntax error in pattern")
+}
+
+func TestExpandModulePatterns(t *testing.T) {
+	modPathMap := ModulePathMap{
+		"go.opentelemetry.io/test/test1":          "test/test1/go.mod",
+		"go.opentelemetry.io/test/test2":          "test/test2/go.mod",
+		"go.opentelemetry.io/test/example":        "test/example/go.mod",
+		"go.opentelemetry.io/test/exporter/test3": "test/exporter/test3/go.mod",
+		"go.opentelemetry.io/other":               "other/go.mod",
+	}
+
+	testCases := []struct {
+		name               string
+		versionCfg         versionConfig
+		expectedModuleSets ModuleSetMap
+		expectedExcluded   []ModulePath
+		expectedErr        string
+	}{
+		{
+			name: "patterns expanded",
+			versionCfg: versionConfig{
+				ModuleSets: ModuleSetMap{
+					"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/other", "go.opentelemetry.io/test/test*"}},
+					"mod-set-2": {Version: "v0.1.0", Modules: []ModulePath{"go.opentelemetry.io/test/exporter/**"}},
+				},
+				ExcludedModules: []ModulePath{"go.opentelemetry.io/test/example*"},
+			},
+			expectedModuleSets: ModuleSetMap{
+				"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/other", "go.opentelemetry.io/test/test1", "go.opentelemetry.io/test/test2"}},
+				"mod-set-2": {Version: "v0.1.0", Modules: []ModulePath{"go.opentelemetry.io/test/exporter/test3"}},
+			},
+			expectedExcluded: []ModulePath{"go.opentelemetry.io/test/example"},
+		},
+		{
+			name: "excluded modules left out of patterns",
+			versionCfg: versionConfig{
+				ModuleSets: ModuleSetMap{
+					"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/test/**"}},
+				},
+				ExcludedModules: []ModulePath{"go.opentelemetry.io/test/example", "go.opentelemetry.io/test/exporter/*"},
+			},
+			expectedModuleSets: ModuleSetMap{
+				"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/test/test1", "go.opentelemetry.io/test/test2"}},
+			},
+			expectedExcluded: []ModulePath{"go.opentelemetry.io/test/example", "go.opentelemetry.io/test/exporter/test3"},
+		},
+		{
+			name: "module paths take precedence over patterns",
+			versionCfg: versionConfig{
+				ModuleSets: ModuleSetMap{
+					"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/test/**"}},
+					"mod-set-2": {Version: "v0.1.0", Modules: []ModulePath{"go.opentelemetry.io/test/test1", "go.opentelemetry.io/test/exporter/test3"}},
+				},
+			},
+			expectedModuleSets: ModuleSetMap{
+				"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/test/example", "go.opentelemetry.io/test/test2"}},
+				"mod-set-2": {Version: "v0.1.0", Modules: []ModulePath{"go.opentelemetry.io/test/test1", "go.opentelemetry.io/test/exporter/test3"}},
+			},
+			expectedExcluded: []ModulePath{},
+		},
+		{
+			name: "conflicting patterns",
+			versionCfg: versionConfig{
+				ModuleSets: ModuleSetMap{
+					"mod-set-1": {Version: "v1.0.0", Modules: []ModulePath{"go.opentelemetry.io/test/**"}},
+					"mod-set-2": {Version: "v0.1.0", Modules: []ModulePath{"go.opentelemetry.io/test/exporter/*", "go.opentelemetry.io/test/test1"}},
+				},
+			},
+			expectedErr: "modules matched by more than one module set pattern:\n" +
+				`go.opentelemetry.io/test/exporter/test3 is matched by "go.opentelemetry.io/test/**" in mod-set-1, "go.opentelemetry.io/test/exporter/*" in mod-set-2`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.versionCfg.expandModulePatterns(modPathMap)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedModuleSets, tc.versionCfg.ModuleSets)
+			assert.Equal(t, tc.expectedExcluded, tc.versionCfg.ExcludedModules)
+		})
+	}
+}
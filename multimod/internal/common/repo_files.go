@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RepoFiles gives access to the files of a repo, either in its working tree or in the tree of a
+// commit.
+type RepoFiles struct {
+	root string
+	// tree is the tree of the commit the files are read from, or nil for the working tree.
+	tree *object.Tree
+}
+
+// WorkingTreeFiles returns the files in the working tree of the repo at repoRoot.
+func WorkingTreeFiles(repoRoot string) (RepoFiles, error) {
+	root, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return RepoFiles{}, fmt.Errorf("could not get absolute path of repo root: %w", err)
+	}
+	return RepoFiles{root: root}, nil
+}
+
+// CommitFiles returns the files of the repo at repoRoot as of commit, regardless of what is
+// checked out.
+func CommitFiles(repoRoot string, commit *object.Commit) (RepoFiles, error) {
+	files, err := WorkingTreeFiles(repoRoot)
+	if err != nil {
+		return RepoFiles{}, err
+	}
+
+	if files.tree, err = commit.Tree(); err != nil {
+		return RepoFiles{}, fmt.Errorf("could not get tree of commit %v: %w", commit.Hash, err)
+	}
+	return files, nil
+}
+
+// relPath returns the slash-separated path of the file at p, in the working tree, relative to
+// the repo root.
+func (f RepoFiles) relPath(p string) (string, error) {
+	rel, err := filepath.Rel(f.root, p)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%v is outside of the repo at %v", p, f.root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// ReadFile returns the contents of the file at p, the path the file has in the working tree. A
+// missing file is reported with an error matching fs.ErrNotExist.
+func (f RepoFiles) ReadFile(p string) ([]byte, error) {
+	if f.tree == nil {
+		return os.ReadFile(filepath.Clean(p))
+	}
+
+	rel, err := f.relPath(p)
+	if err != nil {
+		return nil, err
+	}
+	file, err := f.tree.File(rel)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find %v: %w", rel, err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("could not read %v: %w", rel, err)
+	}
+	return []byte(contents), nil
+}
+
+// ignoredDir returns true if the go command ignores the directory named name when loading the
+// packages of a module.
+func ignoredDir(name string) bool {
+	return name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// isGoModuleFile returns true if the file at the slash-separated path rel is read by the go
+// command when loading the packages of a module: go.mod, go.sum and .go files, outside of ignored
+// directories.
+func isGoModuleFile(rel string) bool {
+	elems := strings.Split(rel, "/")
+	for _, dir := range elems[:len(elems)-1] {
+		if ignoredDir(dir) {
+			return false
+		}
+	}
+
+	name := elems[len(elems)-1]
+	return name == "go.mod" || name == "go.sum" || path.Ext(name) == ".go"
+}
+
+// copyModuleFiles copies the go.mod, go.sum and .go files of the repo to dir, keeping their paths
+// relative to the repo root, so that the go command can load the modules of the copy.
+func (f RepoFiles) copyModuleFiles(dir string) error {
+	write := func(rel string, data []byte) error {
+		dst := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0600)
+	}
+
+	if f.tree != nil {
+		return f.tree.Files().ForEach(func(file *object.File) error {
+			if !file.Mode.IsFile() || !isGoModuleFile(file.Name) {
+				return nil
+			}
+			contents, err := file.Contents()
+			if err != nil {
+				return fmt.Errorf("could not read %v: %w", file.Name, err)
+			}
+			return write(file.Name, []byte(contents))
+		})
+	}
+
+	return filepath.WalkDir(f.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != f.root && ignoredDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := f.relPath(p)
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !isGoModuleFile(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(filepath.Clean(p))
+		if err != nil {
+			return err
+		}
+		return write(rel, data)
+	})
+}
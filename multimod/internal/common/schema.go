@@ -0,0 +1,263 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaProblem is a single problem found in a versioning file.
+type schemaProblem struct {
+	line   int
+	column int
+	msg    string
+}
+
+// schemaValidator collects the problems found while walking the YAML nodes of a versioning file.
+type schemaValidator struct {
+	problems []schemaProblem
+}
+
+func (v *schemaValidator) addProblem(node *yaml.Node, format string, args ...interface{}) {
+	v.problems = append(v.problems, schemaProblem{
+		line:   node.Line,
+		column: node.Column,
+		msg:    fmt.Sprintf(format, args...),
+	})
+}
+
+// checkKnownFields walks node alongside the Go type t it will be decoded into, reporting every
+// mapping key that has no corresponding yaml field and every node of the wrong kind.
+func (v *schemaValidator) checkKnownFields(node *yaml.Node, t reflect.Type) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			v.checkKnownFields(n, t)
+		}
+		return
+	case yaml.AliasNode:
+		v.checkKnownFields(node.Alias, t)
+		return
+	}
+
+	// An empty value (e.g. "modules:" with nothing following it) decodes to the zero value.
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.addProblem(node, "expected a mapping, found %v", kindName(node.Kind))
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				v.addProblem(key, "unknown field %q, expected one of: %v", key.Value, strings.Join(sortedKeys(fields), ", "))
+				continue
+			}
+			v.checkKnownFields(value, field.Type)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.addProblem(node, "expected a mapping, found %v", kindName(node.Kind))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.checkKnownFields(node.Content[i+1], t.Elem())
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.addProblem(node, "expected a sequence, found %v", kindName(node.Kind))
+			return
+		}
+		for _, item := range node.Content {
+			v.checkKnownFields(item, t.Elem())
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.addProblem(node, "expected a scalar value, found %v", kindName(node.Kind))
+		}
+	}
+}
+
+// checkValues verifies that every module set version is valid, canonical semver, that every module path
+// listed in the versioning file is well-formed and that version files, tag prefixes and templates
+// are declared correctly. It must only be called once checkKnownFields reported no problems.
+func (v *schemaValidator) checkValues(root *yaml.Node) {
+	doc := documentContent(root)
+	if doc == nil {
+		return
+	}
+
+	if sets := mappingValue(doc, "module-sets"); sets != nil && sets.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(sets.Content); i += 2 {
+			setName, set := sets.Content[i], sets.Content[i+1]
+
+			version := mappingValue(set, "version")
+			switch {
+			case version == nil || version.Value == "":
+				v.addProblem(setName, "module set %q has no version", setName.Value)
+			case !semver.IsValid(version.Value):
+				v.addProblem(version, "module set %q has invalid semver version %q", setName.Value, version.Value)
+			case semver.Canonical(version.Value) != strings.TrimSuffix(version.Value, semver.Build(version.Value)):
+				v.addProblem(version, "module set %q has shorthand version %q, expected the form v1.2.3", setName.Value, version.Value)
+			}
+
+			v.checkModulePaths(mappingValue(set, "modules"))
+			v.checkVersionFiles(mappingValue(set, "version-files"))
+			v.checkTagPrefix(mappingValue(set, "tag-prefix"))
+			v.checkTemplates(set)
+		}
+	}
+
+	v.checkModulePaths(mappingValue(doc, "excluded-modules"))
+}
+
+func (v *schemaValidator) checkModulePaths(modules *yaml.Node) {
+	if modules == nil || modules.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, mod := range modules.Content {
+		if IsModulePattern(mod.Value) {
+			if err := checkModulePattern(mod.Value); err != nil {
+				v.addProblem(mod, "%v", err)
+			}
+			continue
+		}
+		if err := module.CheckPath(mod.Value); err != nil {
+			v.addProblem(mod, "%v", err)
+		}
+	}
+}
+
+func (v *schemaValidator) checkVersionFiles(versionFiles *yaml.Node) {
+	if versionFiles == nil || versionFiles.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, node := range versionFiles.Content {
+		var vf VersionFile
+		if err := node.Decode(&vf); err != nil {
+			v.addProblem(node, "%v", err)
+			continue
+		}
+		for _, problem := range vf.check() {
+			v.addProblem(node, "%v", problem)
+		}
+	}
+}
+
+// checkTagPrefix verifies that the tag prefix of a module set can be used in Git tags.
+func (v *schemaValidator) checkTagPrefix(tagPrefix *yaml.Node) {
+	if tagPrefix == nil {
+		return
+	}
+	if strings.ContainsAny(tagPrefix.Value, invalidRefChars) {
+		v.addProblem(tagPrefix, "tag-prefix %q contains characters not allowed in Git tags", tagPrefix.Value)
+	}
+}
+
+// checkTemplates executes the templates of a module set with sample data, so that invalid
+// templates are reported before any command uses them.
+func (v *schemaValidator) checkTemplates(set *yaml.Node) {
+	data := TemplateData{ModuleSet: "mod-set", Version: "v1.2.3"}
+	for _, key := range []string{"commit-message", "tag-message"} {
+		if node := mappingValue(set, key); node != nil {
+			if _, err := executeTemplate(key, node.Value, "", data); err != nil {
+				v.addProblem(node, "%v", err)
+			}
+		}
+	}
+	if node := mappingValue(set, "branch-name"); node != nil {
+		if _, err := branchName(node.Value, data); err != nil {
+			v.addProblem(node, "%v", err)
+		}
+	}
+}
+
+// documentContent returns the top level node of a parsed YAML document, or nil if it is empty.
+func documentContent(root *yaml.Node) *yaml.Node {
+	if root.Kind != yaml.DocumentNode {
+		return root
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	return root.Content[0]
+}
+
+// mappingValue returns the value associated with key in a mapping node, or nil if it is absent.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlFields maps the yaml key of each field of the struct type t to its field.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func sortedKeys(fields map[string]reflect.StructField) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func kindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a sequence"
+	case yaml.ScalarNode:
+		return "a scalar value"
+	default:
+		return "an unexpected node"
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Templates used for a module set that does not configure its own.
+const (
+	DefaultCommitMessage = "Prepare {{.ModuleSet}} for version {{.Version}}"
+	DefaultBranchName    = "prerelease_{{.ModuleSet}}_{{.Version}}"
+	DefaultTagMessage    = "Module set {{.ModuleSet}}, Version {{.Version}}"
+)
+
+// invalidRefChars are the characters that Git does not allow in branch and tag names.
+const invalidRefChars = " \t\n~^:?*[\\"
+
+// TemplateData holds the values available to the commit message, branch name and tag message
+// templates of a module set.
+type TemplateData struct {
+	// ModuleSet is the name of the module set.
+	ModuleSet string
+	// Version is the version being released.
+	Version string
+	// TagPrefix is the tag prefix of the module set.
+	TagPrefix string
+}
+
+// executeTemplate executes the template text, or def if text is empty, with data.
+func executeTemplate(name, text, def string, data TemplateData) (string, error) {
+	if text == "" {
+		text = def
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %v template: %w", name, err)
+	}
+
+	var b bytes.Buffer
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("could not execute %v template: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// branchName executes the branch name template text, or the default one if text is empty, and
+// checks that the result can be used as a branch name.
+func branchName(text string, data TemplateData) (string, error) {
+	name, err := executeTemplate("branch-name", text, DefaultBranchName, data)
+	if err != nil {
+		return "", err
+	}
+	if name == "" || strings.ContainsAny(name, invalidRefChars) {
+		return "", fmt.Errorf("branch-name template gives invalid branch name %q", name)
+	}
+	return name, nil
+}
+
+// templateData returns the values available to the templates of the module set.
+func (modRelease ModuleSetRelease) templateData() TemplateData {
+	return TemplateData{
+		ModuleSet: modRelease.ModSetName,
+		Version:   modRelease.ModSetVersion(),
+		TagPrefix: modRelease.ModSet.TagPrefix,
+	}
+}
+
+// CommitMessage returns the message of the commit preparing the release of the module set.
+func (modRelease ModuleSetRelease) CommitMessage() (string, error) {
+	return executeTemplate("commit-message", modRelease.ModSet.CommitMessage, DefaultCommitMessage, modRelease.templateData())
+}
+
+// BranchName returns the name of the branch preparing the release of the module set.
+func (modRelease ModuleSetRelease) BranchName() (string, error) {
+	return branchName(modRelease.ModSet.BranchName, modRelease.templateData())
+}
+
+// TagMessage returns the message of the tags of the modules in the module set.
+func (modRelease ModuleSetRelease) TagMessage() (string, error) {
+	return executeTemplate("tag-message", modRelease.ModSet.TagMessage, DefaultTagMessage, modRelease.templateData())
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleSetReleaseTemplates(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		modSet                ModuleSet
+		expectedCommitMessage string
+		expectedBranchName    string
+		expectedTagMessage    string
+		expectedErr           string
+	}{
+		{
+			name:                  "default templates",
+			modSet:                ModuleSet{Version: "v1.2.3"},
+			expectedCommitMessage: "Prepare mod-set-1 for version v1.2.3",
+			expectedBranchName:    "prerelease_mod-set-1_v1.2.3",
+			expectedTagMessage:    "Module set mod-set-1, Version v1.2.3",
+		},
+		{
+			name: "custom templates",
+			modSet: ModuleSet{
+				Version:       "v1.2.3",
+				TagPrefix:     "go/",
+				CommitMessage: "release: {{.ModuleSet}} {{.Version}}",
+				BranchName:    "release/{{.TagPrefix}}{{.ModuleSet}}-{{.Version}}",
+				TagMessage:    "{{.TagPrefix}}{{.Version}}",
+			},
+			expectedCommitMessage: "release: mod-set-1 v1.2.3",
+			expectedBranchName:    "release/go/mod-set-1-v1.2.3",
+			expectedTagMessage:    "go/v1.2.3",
+		},
+		{
+			name:        "invalid branch name",
+			modSet:      ModuleSet{Version: "v1.2.3", BranchName: "release {{.Version}}"},
+			expectedErr: `branch-name template gives invalid branch name "release v1.2.3"`,
+		},
+		{
+			name:        "unknown field",
+			modSet:      ModuleSet{Version: "v1.2.3", BranchName: "{{.Unknown}}"},
+			expectedErr: "could not execute branch-name template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msr := ModuleSetRelease{ModSetName: "mod-set-1", ModSet: tc.modSet}
+
+			branch, err := msr.BranchName()
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBranchName, branch)
+
+			commitMessage, err := msr.CommitMessage()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCommitMessage, commitMessage)
+
+			tagMessage, err := msr.TagMessage()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTagMessage, tagMessage)
+		})
+	}
+}
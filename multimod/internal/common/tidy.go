@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/multierr"
+	"golang.org/x/mod/modfile"
+)
+
+// DefaultGoModTidyCompat is the Go version passed to "go mod tidy -compat" by default.
+const DefaultGoModTidyCompat = "1.17"
+
+// GoModTidyOptions configures RunGoModTidy.
+type GoModTidyOptions struct {
+	// Compat is the Go version passed to the -compat flag. The flag is omitted if Compat is empty.
+	Compat string
+	// Concurrency is the maximum number of modules tidied at once. If it is not positive,
+	// runtime.GOMAXPROCS(0) is used.
+	Concurrency int
+}
+
+// tidyModule is a module to run "go mod tidy" in.
+type tidyModule struct {
+	modPath ModulePath
+	dir     string
+	// deps are the modules that must be tidied before this one.
+	deps []ModulePath
+}
+
+// RunGoModTidy runs "go mod tidy" in the directory of each go.mod file in modFilePaths. Modules
+// are tidied concurrently, but never before the modules they require, so that each module sees
+// the tidied requirements of its dependencies. All failures are returned together, each with the
+// path of the module that failed.
+func RunGoModTidy(modFilePaths []ModuleFilePath, opts GoModTidyOptions) error {
+	modules, err := tidyOrder(modFilePaths)
+	if err != nil {
+		return err
+	}
+	if len(modules) == 0 {
+		return nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	args := []string{"mod", "tidy"}
+	if opts.Compat != "" {
+		args = append(args, "-compat="+opts.Compat)
+	}
+
+	log.Printf("Running 'go %v' in %d modules...\n", strings.Join(args, " "), len(modules))
+
+	done := make(map[ModulePath]chan struct{}, len(modules))
+	for _, mod := range modules {
+		done[mod.modPath] = make(chan struct{})
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(map[ModulePath]error)
+		sem  = make(chan struct{}, concurrency)
+	)
+	for _, mod := range modules {
+		wg.Add(1)
+		go func(mod tidyModule) {
+			defer wg.Done()
+			defer close(done[mod.modPath])
+
+			for _, dep := range mod.deps {
+				<-done[dep]
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// #nosec G204
+			cmd := exec.Command("go", args...)
+			cmd.Dir = mod.dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				mu.Lock()
+				errs[mod.modPath] = fmt.Errorf("go mod tidy failed for %v [%v]: %w", mod.modPath, strings.TrimSpace(string(out)), err)
+				mu.Unlock()
+			}
+		}(mod)
+	}
+	wg.Wait()
+
+	var result error
+	for _, mod := range modules {
+		result = multierr.Append(result, errs[mod.modPath])
+	}
+	return result
+}
+
+// tidyOrder reads the go.mod files in modFilePaths and returns their modules in dependency
+// order, each listing the other modules it requires. Requirement cycles, which Go allows between
+// modules, are broken so that the result has no cycles.
+func tidyOrder(modFilePaths []ModuleFilePath) ([]tidyModule, error) {
+	paths := append([]ModuleFilePath(nil), modFilePaths...)
+	sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+
+	byPath := make(map[ModulePath]*tidyModule, len(paths))
+	requires := make(map[ModulePath][]ModulePath, len(paths))
+	var modPaths []ModulePath
+	for _, modFilePath := range paths {
+		data, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read go.mod file: %w", err)
+		}
+
+		f, err := modfile.ParseLax(string(modFilePath), data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse go.mod file: %w", err)
+		}
+		if f.Module == nil {
+			return nil, fmt.Errorf("go.mod file %v has no module directive", modFilePath)
+		}
+
+		modPath := ModulePath(f.Module.Mod.Path)
+		if _, ok := byPath[modPath]; ok {
+			continue
+		}
+		byPath[modPath] = &tidyModule{modPath: modPath, dir: filepath.Dir(string(modFilePath))}
+		modPaths = append(modPaths, modPath)
+		for _, r := range f.Require {
+			requires[modPath] = append(requires[modPath], ModulePath(r.Mod.Path))
+		}
+	}
+
+	// Depth-first search, adding each module after the modules it requires. An edge to a module
+	// that is still being visited closes a cycle and is dropped.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[ModulePath]int, len(modPaths))
+	var order []tidyModule
+	var visit func(modPath ModulePath)
+	visit = func(modPath ModulePath) {
+		state[modPath] = visiting
+		mod := byPath[modPath]
+		for _, dep := range requires[modPath] {
+			if _, ok := byPath[dep]; !ok || dep == modPath {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+				mod.deps = append(mod.deps, dep)
+			case visited:
+				mod.deps = append(mod.deps, dep)
+			}
+		}
+		state[modPath] = visited
+		order = append(order, *mod)
+	}
+	for _, modPath := range modPaths {
+		if state[modPath] == unvisited {
+			visit(modPath)
+		}
+	}
+
+	return order, nil
+}
+
+// ModuleFilePaths returns the paths of the go.mod files changed by edits.
+func ModuleFilePaths(edits []GoModEdit) []ModuleFilePath {
+	paths := make([]ModuleFilePath, 0, len(edits))
+	for _, edit := range edits {
+		paths = append(paths, ModuleFilePath(edit.Path))
+	}
+	return paths
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestTidyOrder(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	modFiles := map[string][]byte{
+		filepath.Join(tmpRootDir, "a", "go.mod"): []byte("module example.com/a\n\ngo 1.19\n\n" +
+			"require (\n\texample.com/b v1.0.0\n\texample.com/external v1.0.0\n)\n"),
+		filepath.Join(tmpRootDir, "b", "go.mod"): []byte("module example.com/b\n\ngo 1.19\n\nrequire example.com/c v1.0.0\n"),
+		filepath.Join(tmpRootDir, "c", "go.mod"): []byte("module example.com/c\n\ngo 1.19\n"),
+		// d and e require each other.
+		filepath.Join(tmpRootDir, "d", "go.mod"): []byte("module example.com/d\n\ngo 1.19\n\nrequire example.com/e v1.0.0\n"),
+		filepath.Join(tmpRootDir, "e", "go.mod"): []byte("module example.com/e\n\ngo 1.19\n\nrequire example.com/d v1.0.0\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(modFiles))
+
+	var modFilePaths []ModuleFilePath
+	for modFilePath := range modFiles {
+		modFilePaths = append(modFilePaths, ModuleFilePath(modFilePath))
+	}
+
+	order, err := tidyOrder(modFilePaths)
+	require.NoError(t, err)
+
+	actual := make(map[ModulePath][]ModulePath)
+	var actualOrder []ModulePath
+	for _, mod := range order {
+		actualOrder = append(actualOrder, mod.modPath)
+		actual[mod.modPath] = mod.deps
+	}
+	assert.Equal(t, []ModulePath{"example.com/c", "example.com/b", "example.com/a", "example.com/e", "example.com/d"}, actualOrder)
+	assert.Equal(t, map[ModulePath][]ModulePath{
+		"example.com/a": {"example.com/b"},
+		"example.com/b": {"example.com/c"},
+		"example.com/c": nil,
+		"example.com/d": {"example.com/e"},
+		// The cycle is broken here.
+		"example.com/e": nil,
+	}, actual)
+}
+
+func TestRunGoModTidy(t *testing.T) {
+	// Fail fast instead of looking up unknown modules.
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	tmpRootDir := t.TempDir()
+	files := map[string][]byte{
+		filepath.Join(tmpRootDir, "a", "go.mod"): []byte("module example.com/a\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "a", "a.go"):   []byte("package a\n"),
+		filepath.Join(tmpRootDir, "b", "go.mod"): []byte("module example.com/b\n\ngo 1.19\n\n" +
+			"require example.com/a v1.0.0\n\nreplace example.com/a => ../a\n"),
+		filepath.Join(tmpRootDir, "b", "b.go"):         []byte("package b\n\nimport _ \"example.com/a\"\n"),
+		filepath.Join(tmpRootDir, "broken1", "go.mod"): []byte("module example.com/broken1\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "broken1", "x.go"):   []byte("package x\n\nimport _ \"example.com/missing\"\n"),
+		filepath.Join(tmpRootDir, "broken2", "go.mod"): []byte("module example.com/broken2\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "broken2", "x.go"):   []byte("package x\n\nimport _ \"example.com/missing\"\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files))
+
+	err := RunGoModTidy([]ModuleFilePath{
+		ModuleFilePath(filepath.Join(tmpRootDir, "broken2", "go.mod")),
+		ModuleFilePath(filepath.Join(tmpRootDir, "b", "go.mod")),
+		ModuleFilePath(filepath.Join(tmpRootDir, "broken1", "go.mod")),
+		ModuleFilePath(filepath.Join(tmpRootDir, "a", "go.mod")),
+	}, GoModTidyOptions{Compat: "1.19", Concurrency: 2})
+
+	errs := multierr.Errors(err)
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], "go mod tidy failed for example.com/broken1")
+	assert.ErrorContains(t, errs[1], "go mod tidy failed for example.com/broken2")
+
+	// The successful modules are tidied.
+	actual, err := os.ReadFile(filepath.Join(tmpRootDir, "b", "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/b\n\ngo 1.19\n\n"+
+		"require example.com/a v1.0.0\n\nreplace example.com/a => ../a\n", string(actual))
+
+	assert.NoError(t, RunGoModTidy(nil, GoModTidyOptions{}))
+}
@@ -15,15 +15,17 @@
 package common
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
 
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
 )
 
@@ -33,7 +35,7 @@ func IsStableVersion(v string) bool {
 	return semver.Compare(semver.Major(v), "v1") >= 0
 }
 
-// GetAllModuleSetNames returns the name of all module sets given in a versioningFile.
+// GetAllModuleSetNames returns the name of all module sets given in a versioningFile, sorted by name.
 func GetAllModuleSetNames(versioningFile string, repoRoot string) ([]string, error) {
 	modVersioning, err := NewModuleVersioning(versioningFile, repoRoot)
 	if err != nil {
@@ -45,93 +47,268 @@ func GetAllModuleSetNames(versioningFile string, repoRoot string) ([]string, err
 	for modSetName := range modVersioning.ModSetMap {
 		modSetNames = append(modSetNames, modSetName)
 	}
+	sort.Strings(modSetNames)
 
 	return modSetNames, nil
 }
 
-func GetModuleSet(modSetName, versioningFilename string) (ModuleSet, error) {
+// GetModuleSetOrder returns the given module sets of a versioningFile in the order they must be
+// released, as computed by ModuleVersioning.ModuleSetOrder.
+func GetModuleSetOrder(versioningFile string, repoRoot string, modSetNames []string) ([]string, error) {
+	modVersioning, err := NewModuleVersioning(versioningFile, repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("call failed to NewModuleVersioning: %w", err)
+	}
+
+	return modVersioning.ModuleSetOrder(modSetNames)
+}
+
+// GetModuleSet returns the module set modSetName of a versioning file, with its module patterns
+// expanded against the modules found under repoRoot.
+func GetModuleSet(modSetName, versioningFilename, repoRoot string) (ModuleSet, error) {
 	vCfg, err := readVersioningFile(versioningFilename)
 	if err != nil {
 		return ModuleSet{}, fmt.Errorf("error reading versioning file %v: %w", versioningFilename, err)
 	}
 
+	if err = vCfg.expandModulePatternsIn(repoRoot); err != nil {
+		return ModuleSet{}, fmt.Errorf("error expanding module patterns of versioning file %v: %w", versioningFilename, err)
+	}
+
 	modSetMap := vCfg.buildModuleSetsMap()
 	return modSetMap[modSetName], nil
 }
 
-// updateGoModVersions updates one go.mod file, given by modFilePath, by updating all modules listed in
-// newModPaths to use the newVersion given.
-func updateGoModVersions(modFilePath ModuleFilePath, newModPaths []ModulePath, newVersion string) error {
+// ParseModuleSets returns the module sets of a versioning file from its contents, such as a
+// versioning file read from a git tree, with its module patterns expanded against the modules of
+// modPathMap. The file name is only used in error messages.
+func ParseModuleSets(data []byte, versioningFilename string, modPathMap ModulePathMap) (ModuleSetMap, error) {
+	vCfg, err := parseVersioningData(data, versioningFilename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading versioning file %v: %w", versioningFilename, err)
+	}
+
+	if err = vCfg.expandModulePatterns(modPathMap); err != nil {
+		return nil, fmt.Errorf("error expanding module patterns of versioning file %v: %w", versioningFilename, err)
+	}
+
+	return vCfg.buildModuleSetsMap(), nil
+}
+
+// RequirementChange is a change to the version of a module in a go.mod file.
+type RequirementChange struct {
+	// Directive is the go.mod directive that changed, either "require" or "replace".
+	Directive  string
+	ModPath    ModulePath
+	OldVersion string
+	NewVersion string
+}
+
+func (c RequirementChange) String() string {
+	return fmt.Sprintf("%v %v %v => %v", c.Directive, c.ModPath, c.OldVersion, c.NewVersion)
+}
+
+// GoModEdit is the edit to one go.mod file, together with the requirement changes it makes.
+type GoModEdit struct {
+	FileEdit
+	Changes []RequirementChange
+}
+
+// FileEdits returns the file edits of the given go.mod edits.
+func FileEdits(goModEdits []GoModEdit) []FileEdit {
+	edits := make([]FileEdit, 0, len(goModEdits))
+	for _, edit := range goModEdits {
+		edits = append(edits, edit.FileEdit)
+	}
+	return edits
+}
+
+// goModVersionsEdit computes the edit to one go.mod file, given by modFilePath, that updates all
+// modules listed in newModPaths to use the newVersion given. Replace directives are only updated
+// if withReplace is true.
+func goModVersionsEdit(modFilePath ModuleFilePath, newModPaths []ModulePath, newVersion string, withReplace bool) (GoModEdit, error) {
 	if !strings.HasSuffix(string(modFilePath), "go.mod") {
-		return errors.New("cannot update file passed that does not end with go.mod")
+		return GoModEdit{}, errors.New("cannot update file passed that does not end with go.mod")
 	}
 
-	newGoModFile, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+	oldGoModFile, err := os.ReadFile(filepath.Clean(string(modFilePath)))
 	if err != nil {
-		panic(err)
+		return GoModEdit{}, fmt.Errorf("error reading go.mod file: %w", err)
 	}
 
-	for _, modPath := range newModPaths {
-		newGoModFile, err = replaceModVersion(modPath, newVersion, newGoModFile)
+	newGoModFile, changes, err := replaceModVersions(string(modFilePath), oldGoModFile, newModPaths, newVersion, withReplace)
+	if err != nil {
+		return GoModEdit{}, err
+	}
+
+	return GoModEdit{
+		FileEdit: FileEdit{Path: string(modFilePath), Old: oldGoModFile, New: newGoModFile},
+		Changes:  changes,
+	}, nil
+}
+
+// replaceModVersions updates the require directives, and the replace directives if withReplace is
+// true, of the go.mod file content in data so that every module in modPaths uses version. Replace
+// directives are only updated where their target pins a version of one of the modules. The
+// version on the left-hand side selects the requirement a directive applies to, so it is left as
+// it is, as are replacements with a local directory.
+//
+// Only the version tokens are rewritten, so comments and formatting are kept. The setters of
+// modfile.File followed by modfile.Format are not used since Format reprints the whole file: it
+// drops the alignment within blocks, unquotes module paths and adds blank lines between
+// directives, changing lines that do not require the modules.
+func replaceModVersions(filename string, data []byte, modPaths []ModulePath, version string, withReplace bool) ([]byte, []RequirementChange, error) {
+	f, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse go.mod file: %w", err)
+	}
+
+	updated := make(map[string]bool, len(modPaths))
+	for _, modPath := range modPaths {
+		updated[string(modPath)] = true
+	}
+
+	var edits []textEdit
+	var changes []RequirementChange
+	update := func(directive string, line *modfile.Line, tokenIndex int, modPath, oldVersion string) error {
+		newVersion := moduleVersion(modPath, version)
+		edit, err := tokenEdit(data, line, tokenIndex, newVersion)
 		if err != nil {
-			return err
+			return fmt.Errorf("%v:%d: %w", filename, line.Start.Line, err)
+		}
+		// Compare with the token as written, since parsing drops build metadata from versions.
+		if string(data[edit.offset:edit.offset+edit.length]) == newVersion {
+			return nil
 		}
+
+		edits = append(edits, edit)
+		changes = append(changes, RequirementChange{
+			Directive:  directive,
+			ModPath:    ModulePath(modPath),
+			OldVersion: oldVersion,
+			NewVersion: newVersion,
+		})
+		return nil
 	}
 
-	// once all module versions have been updated, overwrite the go.mod file
-	if err := os.WriteFile(string(modFilePath), newGoModFile, 0600); err != nil {
-		return fmt.Errorf("error overwriting go.mod file: %w", err)
+	for _, r := range f.Require {
+		if !updated[r.Mod.Path] {
+			continue
+		}
+		if err = update("require", r.Syntax, len(r.Syntax.Token)-1, r.Mod.Path, r.Mod.Version); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return nil
+	if withReplace {
+		for _, r := range f.Replace {
+			if !updated[r.New.Path] || r.New.Version == "" {
+				continue
+			}
+			if err = update("replace", r.Syntax, len(r.Syntax.Token)-1, r.New.Path, r.New.Version); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return applyTextEdits(data, edits), changes, nil
 }
 
-func replaceModVersion(modPath ModulePath, version string, newGoModFile []byte) ([]byte, error) {
-	oldVersionRegex := `(?m:` + filePathToRegex(string(modPath)) + `\s+` + SemverRegex + `(\s*\/\/\s*indirect\s*?)?$)`
-	r, err := regexp.Compile(oldVersionRegex)
-	if err != nil {
-		return nil, fmt.Errorf("error compiling regex: %w", err)
+// moduleVersion returns version as it must be required for the module at modPath. Versions v2 and
+// above of a module without a major version suffix need the +incompatible suffix.
+func moduleVersion(modPath, version string) string {
+	if module.Check(modPath, version) != nil && module.Check(modPath, version+"+incompatible") == nil {
+		return version + "+incompatible"
 	}
+	return version
+}
+
+// tokenEdit returns the edit replacing the token at tokenIndex of line with text. The tokens of a
+// line are located in order, so a token that also occurs within an earlier token is still found.
+// The replaced text extends to the end of the token as written, which may be longer than the parsed
+// token, e.g. for versions with build metadata.
+func tokenEdit(data []byte, line *modfile.Line, tokenIndex int, text string) (textEdit, error) {
+	offset := line.Start.Byte
+	for i, token := range line.Token {
+		j := bytes.Index(data[offset:line.End.Byte], []byte(token))
+		if j < 0 {
+			return textEdit{}, fmt.Errorf("could not locate %q", token)
+		}
+		offset += j
+
+		length := len(token)
+		for offset+length < line.End.Byte && !isTokenEnd(data[offset+length:line.End.Byte]) {
+			length++
+		}
 
-	newModVersionString := string(modPath) + " " + version
+		if i == tokenIndex {
+			return textEdit{offset: offset, length: length, text: text}, nil
+		}
+		offset += length
+	}
 
-	// ${6} is the capture group that has " // indirect" if it was present in the original
-	newGoModFile = r.ReplaceAll(newGoModFile, []byte(newModVersionString+"${6}"))
-	return newGoModFile, nil
+	return textEdit{}, fmt.Errorf("line has no token %d", tokenIndex)
 }
 
-// UpdateGoModFiles updates the go.mod files in modFilePaths by updating all modules listed in
-// newModPaths to use the newVersion given.
-func UpdateGoModFiles(modFilePaths []ModuleFilePath, newModPaths []ModulePath, newVersion string) error {
-	log.Println("Updating all module versions in go.mod files...")
+// isTokenEnd returns true if rest, the remainder of a go.mod line after some token text, starts
+// with whitespace or a comment.
+func isTokenEnd(rest []byte) bool {
+	switch rest[0] {
+	case ' ', '\t', '\r', '\n':
+		return true
+	}
+	return bytes.HasPrefix(rest, []byte("//"))
+}
+
+// GoModFileEdits computes the edits to the go.mod files in modFilePaths that update all modules
+// listed in newModPaths to use the newVersion given. Only files that change are returned, sorted
+// by path.
+func GoModFileEdits(modFilePaths []ModuleFilePath, newModPaths []ModulePath, newVersion string) ([]GoModEdit, error) {
+	return goModFileEdits(modFilePaths, newModPaths, newVersion, true)
+}
+
+// GoModRequireEdits is like GoModFileEdits, but only updates require directives, leaving replace
+// directives as they are.
+func GoModRequireEdits(modFilePaths []ModuleFilePath, newModPaths []ModulePath, newVersion string) ([]GoModEdit, error) {
+	return goModFileEdits(modFilePaths, newModPaths, newVersion, false)
+}
+
+func goModFileEdits(modFilePaths []ModuleFilePath, newModPaths []ModulePath, newVersion string, withReplace bool) ([]GoModEdit, error) {
+	var edits []GoModEdit
 	for _, modFilePath := range modFilePaths {
-		if err := updateGoModVersions(
-			modFilePath,
-			newModPaths,
-			newVersion,
-		); err != nil {
-			return fmt.Errorf("could not update module versions in file %v: %w", modFilePath, err)
+		edit, err := goModVersionsEdit(modFilePath, newModPaths, newVersion, withReplace)
+		if err != nil {
+			return nil, fmt.Errorf("could not update module versions in file %v: %w", modFilePath, err)
+		}
+		if edit.Changed() {
+			edits = append(edits, edit)
 		}
 	}
-	return nil
-}
 
-func filePathToRegex(fpath string) string {
-	quotedMeta := regexp.QuoteMeta(fpath)
-	replacedSlashes := strings.ReplaceAll(quotedMeta, string(filepath.Separator), `\/`)
-	return replacedSlashes
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Path < edits[j].Path })
+	return edits, nil
 }
 
-// RunGoModTidy takes a ModulePathMap and runs "go mod tidy" at each module file path.
-func RunGoModTidy(modPathMap ModulePathMap) error {
-	for _, modFilePath := range modPathMap {
-		cmd := exec.Command("go", "mod", "tidy", "-compat=1.17")
-		cmd.Dir = filepath.Dir(string(modFilePath))
+// UpdateGoModFiles updates the go.mod files in modFilePaths by updating all modules listed in
+// newModPaths to use the newVersion given. The requirements changed in each file are logged, and
+// the edits made are returned.
+func UpdateGoModFiles(modFilePaths []ModuleFilePath, newModPaths []ModulePath, newVersion string) ([]GoModEdit, error) {
+	log.Println("Updating all module versions in go.mod files...")
+	edits, err := GoModFileEdits(modFilePaths, newModPaths, newVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, edit := range edits {
+		if err = edit.Write(); err != nil {
+			return nil, err
+		}
 
-		if out, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("go mod tidy failed [%v]: %w", string(out), err)
+		log.Printf("... Updated %v\n", edit.Path)
+		for _, change := range edit.Changes {
+			log.Printf("      %v\n", change)
 		}
 	}
 
-	return nil
+	return edits, nil
 }
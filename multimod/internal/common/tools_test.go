@@ -130,7 +130,9 @@ func TestUpdateGoModVersions(t *testing.T) {
 	}
 	newVersion := "v1.2.3-RC1+meta"
 
-	require.NoError(t, UpdateGoModFiles(modFilePaths, newModPaths, newVersion))
+	edits, err := UpdateGoModFiles(modFilePaths, newModPaths, newVersion)
+	require.NoError(t, err)
+	assert.Len(t, edits, 4)
 	for modFilePath, expectedByteOutput := range expectedModFiles {
 		actual, err := os.ReadFile(filepath.Clean(modFilePath))
 		require.NoError(t, err)
@@ -139,34 +141,15 @@ func TestUpdateGoModVersions(t *testing.T) {
 	}
 }
 
-func TestFilePathToRegex(t *testing.T) {
-	testCases := []struct {
-		fpath    string
-		expected string
-	}{
-		{
-			fpath:    "go.opentelemetry.io/test/test1",
-			expected: `go\.opentelemetry\.io\/test\/test1`,
-		},
-		{
-			fpath:    "go.opentelemetry.io/test/hyphen-test1",
-			expected: `go\.opentelemetry\.io\/test\/hyphen-test1`,
-		},
-	}
-
-	for _, tc := range testCases {
-		actual := filePathToRegex(tc.fpath)
-
-		assert.Equal(t, tc.expected, actual)
-	}
-}
-
-func TestReplaceModVersion(t *testing.T) {
+func TestReplaceModVersions(t *testing.T) {
 	for _, s := range []struct {
-		name     string
-		input    []byte
-		expected []byte
-		err      bool
+		name            string
+		input           []byte
+		version         string
+		expected        []byte
+		expectedChanges []RequirementChange
+		requireOnly     bool
+		err             bool
 	}{
 		{
 			name: "simple",
@@ -184,6 +167,9 @@ require (
 	foo.bar/baz v1.2.4
 )
 `),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+			},
 		},
 		{
 			name: "indirect",
@@ -201,6 +187,9 @@ require (
 	foo.bar/baz v1.2.4 // indirect
 )
 `),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+			},
 		},
 		{
 			name: "1.17 style",
@@ -225,17 +214,203 @@ require (
 require (
 	foo.bar/baz v1.2.4 // indirect
 )
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+			},
+		},
+		{
+			name: "single line with comments",
+			input: []byte(`module test
+
+// Pinned until the next release.
+// See the changelog for details.
+require "foo.bar/baz" v0.0.0-20230101000000-abcdefabcdef // keep
+`),
+			expected: []byte(`module test
+
+// Pinned until the next release.
+// See the changelog for details.
+require "foo.bar/baz" v1.2.4 // keep
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v0.0.0-20230101000000-abcdefabcdef", NewVersion: "v1.2.4"},
+			},
+		},
+		{
+			name: "similarly prefixed modules",
+			input: []byte(`module test
+
+require (
+	foo.bar/baz-extra v1.2.3
+	foo.bar/baz/v2 v2.0.0
+	foo.bar/baz/qux v1.2.3
+	foo.bar/baz v1.2.3
+)
+`),
+			expected: []byte(`module test
+
+require (
+	foo.bar/baz-extra v1.2.3
+	foo.bar/baz/v2 v2.0.0
+	foo.bar/baz/qux v1.2.3
+	foo.bar/baz v1.2.4
+)
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+			},
+		},
+		{
+			name:    "incompatible",
+			version: "v3.0.0",
+			input: []byte(`module test
+
+require foo.bar/baz v2.0.0+incompatible
+`),
+			expected: []byte(`module test
+
+require foo.bar/baz v3.0.0+incompatible
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v2.0.0+incompatible", NewVersion: "v3.0.0+incompatible"},
+			},
+		},
+		{
+			name: "replace directives",
+			input: []byte(`module test
+
+require foo.bar/baz v1.2.3
+
+replace (
+	foo.bar/baz v1.2.3 => foo.bar/baz v1.2.3 // pinned
+	foo.bar/baz => ../baz
+	other.com/baz => foo.bar/baz v1.2.3
+)
+
+replace foo.bar/baz v1.0.0 => ./baz
+`),
+			expected: []byte(`module test
+
+require foo.bar/baz v1.2.4
+
+replace (
+	foo.bar/baz v1.2.3 => foo.bar/baz v1.2.4 // pinned
+	foo.bar/baz => ../baz
+	other.com/baz => foo.bar/baz v1.2.4
+)
+
+replace foo.bar/baz v1.0.0 => ./baz
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+				{Directive: "replace", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+				{Directive: "replace", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+			},
+		},
+		{
+			name:        "require only",
+			requireOnly: true,
+			input: []byte(`module test
+
+require foo.bar/baz v1.2.3
+
+replace other.com/baz => foo.bar/baz v1.2.3
+`),
+			expected: []byte(`module test
+
+require foo.bar/baz v1.2.4
+
+replace other.com/baz => foo.bar/baz v1.2.3
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3", NewVersion: "v1.2.4"},
+			},
+		},
+		{
+			name: "already up to date",
+			input: []byte(`module test
+
+require foo.bar/baz v1.2.4
+`),
+			expected: []byte(`module test
+
+require foo.bar/baz v1.2.4
+`),
+		},
+		{
+			name: "build metadata",
+			input: []byte(`module test
+
+require foo.bar/baz v1.2.3-RC1+meta // comment
+`),
+			version: "v1.2.4+meta",
+			expected: []byte(`module test
+
+require foo.bar/baz v1.2.4+meta // comment
+`),
+			expectedChanges: []RequirementChange{
+				{Directive: "require", ModPath: "foo.bar/baz", OldVersion: "v1.2.3-RC1", NewVersion: "v1.2.4+meta"},
+			},
+		},
+		{
+			name: "already up to date with build metadata",
+			input: []byte(`module test
+
+require foo.bar/baz v1.2.4+meta
+`),
+			version: "v1.2.4+meta",
+			expected: []byte(`module test
+
+require foo.bar/baz v1.2.4+meta
 `),
 		},
+		{
+			name: "invalid",
+			input: []byte(`module test
+
+require foo.bar/baz
+`),
+			err: true,
+		},
 	} {
 		t.Run(s.name, func(t *testing.T) {
-			got, err := replaceModVersion("foo.bar/baz", "v1.2.4", s.input)
-			assert.Equal(t, string(s.expected), string(got))
+			version := s.version
+			if version == "" {
+				version = "v1.2.4"
+			}
+
+			got, changes, err := replaceModVersions("go.mod", s.input, []ModulePath{"foo.bar/baz"}, version, !s.requireOnly)
 			if s.err {
 				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
+				return
 			}
+			require.NoError(t, err)
+			assert.Equal(t, string(s.expected), string(got))
+			assert.Equal(t, s.expectedChanges, changes)
 		})
 	}
 }
+
+func TestParseModuleSets(t *testing.T) {
+	modSets, err := ParseModuleSets([]byte("module-sets:\n  stable:\n    version: v1.2.0\n    modules:\n"+
+		"      - go.opentelemetry.io/test/test1\n"), "versions.yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, ModuleSetMap{
+		"stable": {Version: "v1.2.0", Modules: []ModulePath{"go.opentelemetry.io/test/test1"}},
+	}, modSets)
+
+	modSets, err = ParseModuleSets([]byte("module-sets:\n  stable:\n    version: v1.2.0\n    modules:\n"+
+		"      - go.opentelemetry.io/test/*\n"), "versions.yaml", ModulePathMap{
+		"go.opentelemetry.io/test/test1": "/test/test1/go.mod",
+		"go.opentelemetry.io/test/test2": "/test/test2/go.mod",
+		"go.opentelemetry.io/other":      "/other/go.mod",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ModuleSetMap{
+		"stable": {Version: "v1.2.0", Modules: []ModulePath{"go.opentelemetry.io/test/test1", "go.opentelemetry.io/test/test2"}},
+	}, modSets)
+
+	_, err = ParseModuleSets([]byte("module-sets:\n  stable:\n    version: 1.2\n"), "versions.yaml", nil)
+	assert.ErrorContains(t, err, "error reading versioning file versions.yaml")
+}
@@ -0,0 +1,296 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VersionFile declares a file, relative to the directory of each module in a module set, that
+// holds the version of the module set. A path starting with "/" is relative to the repo root
+// instead and is only updated once. Paths may contain glob patterns.
+//
+// Exactly one of Constant and Pattern must be set. Constant names a Go constant, variable or
+// function returning a string literal that holds the version. Pattern is a regular expression
+// with one capture group matching the version, used for any kind of file such as a README badge.
+type VersionFile struct {
+	Path     string `yaml:"path"`
+	Constant string `yaml:"constant"`
+	Pattern  string `yaml:"pattern"`
+}
+
+// check returns the problems with the declaration of the version file.
+func (vf VersionFile) check() []string {
+	var problems []string
+	if vf.Path == "" {
+		problems = append(problems, "version file has no path")
+	} else if _, err := filepath.Match(vf.Path, ""); err != nil {
+		problems = append(problems, fmt.Sprintf("version file path %q is not a valid glob: %v", vf.Path, err))
+	}
+
+	switch {
+	case vf.Constant == "" && vf.Pattern == "":
+		problems = append(problems, fmt.Sprintf("version file %q needs either a constant or a pattern", vf.Path))
+	case vf.Constant != "" && vf.Pattern != "":
+		problems = append(problems, fmt.Sprintf("version file %q has both a constant and a pattern", vf.Path))
+	case vf.Constant != "" && !token.IsIdentifier(vf.Constant):
+		problems = append(problems, fmt.Sprintf("version file %q constant %q is not a Go identifier", vf.Path, vf.Constant))
+	case vf.Pattern != "":
+		r, err := regexp.Compile(vf.Pattern)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("version file %q has invalid pattern: %v", vf.Path, err))
+		} else if r.NumSubexp() != 1 {
+			problems = append(problems, fmt.Sprintf("version file %q pattern must have exactly one capture group, found %d", vf.Path, r.NumSubexp()))
+		}
+	}
+
+	return problems
+}
+
+// versionSpan is the location of a version string within a file.
+type versionSpan struct {
+	offset int
+	length int
+	// value is the version string, without any quotes.
+	value string
+	// quote is the quote character surrounding the version in Go source, if any.
+	quote string
+}
+
+// FindVersions returns every version string held in data, the content of filename, as declared
+// by vf.
+func (vf VersionFile) FindVersions(filename string, data []byte) ([]string, error) {
+	spans, err := vf.versionSpans(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(spans))
+	for _, s := range spans {
+		versions = append(versions, s.value)
+	}
+	return versions, nil
+}
+
+func (vf VersionFile) versionSpans(filename string, data []byte) ([]versionSpan, error) {
+	if vf.Constant != "" {
+		span, err := goConstantSpan(filename, data, vf.Constant)
+		if err != nil {
+			return nil, err
+		}
+		return []versionSpan{span}, nil
+	}
+
+	r, err := regexp.Compile(vf.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	var spans []versionSpan
+	for _, m := range r.FindAllSubmatchIndex(data, -1) {
+		if m[2] < 0 {
+			continue
+		}
+		spans = append(spans, versionSpan{
+			offset: m[2],
+			length: m[3] - m[2],
+			value:  string(data[m[2]:m[3]]),
+		})
+	}
+
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("pattern %q does not match %v", vf.Pattern, filename)
+	}
+	return spans, nil
+}
+
+// goConstantSpan returns the location of the string literal held by the top-level constant,
+// variable or function called name in the Go source file data.
+func goConstantSpan(filename string, data []byte, name string) (versionSpan, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, data, 0)
+	if err != nil {
+		return versionSpan{}, fmt.Errorf("could not parse %v: %w", filename, err)
+	}
+
+	var lit *ast.BasicLit
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.CONST && d.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range d.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, ident := range vs.Names {
+					if ident.Name == name && i < len(vs.Values) {
+						lit, _ = vs.Values[i].(*ast.BasicLit)
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv != nil || d.Name.Name != name || d.Body == nil {
+				continue
+			}
+			ast.Inspect(d.Body, func(n ast.Node) bool {
+				if ret, ok := n.(*ast.ReturnStmt); ok && lit == nil && len(ret.Results) == 1 {
+					lit, _ = ret.Results[0].(*ast.BasicLit)
+				}
+				return lit == nil
+			})
+		}
+		if lit != nil {
+			break
+		}
+	}
+
+	if lit == nil || lit.Kind != token.STRING {
+		return versionSpan{}, fmt.Errorf("%v has no constant, variable or function %v holding a string literal", filename, name)
+	}
+
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return versionSpan{}, fmt.Errorf("could not unquote %v in %v: %w", name, filename, err)
+	}
+
+	return versionSpan{
+		offset: fset.Position(lit.Pos()).Offset,
+		length: len(lit.Value),
+		value:  value,
+		quote:  lit.Value[:1],
+	}, nil
+}
+
+// formatVersion formats newVersion like oldVersion, with or without the "v" prefix.
+func formatVersion(oldVersion, newVersion string) string {
+	if strings.HasPrefix(oldVersion, "v") {
+		return "v" + strings.TrimPrefix(newVersion, "v")
+	}
+	return strings.TrimPrefix(newVersion, "v")
+}
+
+// paths returns the files that vf refers to for the given module directories.
+func (vf VersionFile) paths(repoRoot string, modDirs []string) ([]string, error) {
+	var patterns []string
+	if strings.HasPrefix(vf.Path, "/") {
+		patterns = []string{filepath.Join(repoRoot, filepath.FromSlash(vf.Path))}
+	} else {
+		for _, dir := range modDirs {
+			patterns = append(patterns, filepath.Join(dir, filepath.FromSlash(vf.Path)))
+		}
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// VersionFileEdits computes the edits that set the version of every version file declared for
+// modSet to newVersion. Each version keeps its "v" prefix style. Declared files that do not
+// exist for any module of the set are reported as an error.
+func VersionFileEdits(repoRoot string, modSet ModuleSet, modPathMap ModulePathMap, newVersion string) ([]FileEdit, error) {
+	var modDirs []string
+	for _, modPath := range modSet.Modules {
+		modFilePath, ok := modPathMap[modPath]
+		if !ok {
+			return nil, fmt.Errorf("could not find module path %v in path map", modPath)
+		}
+		modDirs = append(modDirs, filepath.Dir(string(modFilePath)))
+	}
+
+	contents := make(map[string][]byte)
+	edits := make(map[string][]textEdit)
+	for _, vf := range modSet.VersionFiles {
+		paths, err := vf.paths(repoRoot, modDirs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version file path %q: %w", vf.Path, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("version file %q does not exist for any module in the set", vf.Path)
+		}
+
+		for _, path := range paths {
+			data, ok := contents[path]
+			if !ok {
+				if data, err = os.ReadFile(filepath.Clean(path)); err != nil {
+					return nil, fmt.Errorf("could not read version file: %w", err)
+				}
+				contents[path] = data
+			}
+
+			spans, err := vf.versionSpans(path, data)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range spans {
+				text := formatVersion(s.value, newVersion)
+				if s.quote != "" {
+					text = s.quote + text + s.quote
+				}
+				edits[path] = append(edits[path], textEdit{offset: s.offset, length: s.length, text: text})
+			}
+		}
+	}
+
+	fileEdits := make([]FileEdit, 0, len(edits))
+	for path, textEdits := range edits {
+		newData, err := applyNonOverlappingEdits(contents[path], textEdits)
+		if err != nil {
+			return nil, fmt.Errorf("could not update %v: %w", path, err)
+		}
+		fileEdits = append(fileEdits, FileEdit{Path: path, Old: contents[path], New: newData})
+	}
+
+	sort.Slice(fileEdits, func(i, j int) bool { return fileEdits[i].Path < fileEdits[j].Path })
+	return fileEdits, nil
+}
+
+// applyNonOverlappingEdits applies edits to data after dropping duplicates, which occur when
+// several version file declarations match the same version string.
+func applyNonOverlappingEdits(data []byte, edits []textEdit) ([]byte, error) {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+
+	var unique []textEdit
+	for _, e := range edits {
+		if n := len(unique); n > 0 {
+			last := unique[n-1]
+			if last == e {
+				continue
+			}
+			if e.offset < last.offset+last.length {
+				return nil, errors.New("version file declarations overlap")
+			}
+		}
+		unique = append(unique, e)
+	}
+
+	return applyTextEdits(data, unique), nil
+}
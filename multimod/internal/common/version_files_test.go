@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestVersionFileCheck(t *testing.T) {
+	data := []byte(`module-sets:
+  mod-set-1:
+    version: v1.2.3
+    modules:
+      - go.opentelemetry.io/test/test1
+    version-files:
+      - path: version.go
+        constant: Version
+      - path: README.md
+        pattern: 'version-(v[0-9.]+)-blue'
+      - constant: Version
+      - path: version.go
+      - path: version.go
+        constant: Version
+        pattern: '(.*)'
+      - path: version.go
+        constant: not-an-identifier
+      - path: README.md
+        pattern: 'version-v[0-9.]+'
+      - path: README.md
+        pattern: '(unclosed'
+      - path: '[version.go'
+        constant: Version
+`)
+
+	_, err := parseVersioningData(data, "versions.yaml")
+	require.Error(t, err)
+	assert.Equal(t, "invalid versioning file versions.yaml:"+
+		"\nversions.yaml:11:9: version file has no path"+
+		"\nversions.yaml:12:9: version file \"version.go\" needs either a constant or a pattern"+
+		"\nversions.yaml:13:9: version file \"version.go\" has both a constant and a pattern"+
+		"\nversions.yaml:16:9: version file \"version.go\" constant \"not-an-identifier\" is not a Go identifier"+
+		"\nversions.yaml:18:9: version file \"README.md\" pattern must have exactly one capture group, found 0"+
+		"\nversions.yaml:20:9: version file \"README.md\" has invalid pattern: error parsing regexp: missing closing ): `(unclosed`"+
+		"\nversions.yaml:22:9: version file path \"[version.go\" is not a valid glob: syntax error in pattern", err.Error())
+}
+
+func TestVersionFileFindVersions(t *testing.T) {
+	goFile := []byte(`package test
+
+// Version is not "v0.0.1".
+const Version = "v1.2.3"
+
+var (
+	other, version = "other", ` + "`0.1.0`" + `
+)
+
+func SemVer() string {
+	return "1.0.0-RC1"
+}
+
+func (t T) Receiver() string {
+	return "2.0.0"
+}
+`)
+
+	testCases := []struct {
+		name          string
+		versionFile   VersionFile
+		data          []byte
+		expected      []string
+		expectedError string
+	}{
+		{name: "const", versionFile: VersionFile{Constant: "Version"}, data: goFile, expected: []string{"v1.2.3"}},
+		{name: "var", versionFile: VersionFile{Constant: "version"}, data: goFile, expected: []string{"0.1.0"}},
+		{name: "func", versionFile: VersionFile{Constant: "SemVer"}, data: goFile, expected: []string{"1.0.0-RC1"}},
+		{
+			name:          "method is ignored",
+			versionFile:   VersionFile{Constant: "Receiver"},
+			data:          goFile,
+			expectedError: "test.go has no constant, variable or function Receiver holding a string literal",
+		},
+		{
+			name:        "pattern",
+			versionFile: VersionFile{Pattern: `badge/version-v?([0-9][0-9a-zA-Z.+]*)-blue`},
+			data:        []byte("![v](https://img.shields.io/badge/version-v1.2.3-blue)\n![v](https://img.shields.io/badge/version-1.2.3-blue)\n"),
+			expected:    []string{"1.2.3", "1.2.3"},
+		},
+		{
+			name:          "pattern without match",
+			versionFile:   VersionFile{Pattern: `version-(v[0-9.]+)`},
+			data:          []byte("no version here\n"),
+			expectedError: "does not match test.go",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.versionFile.FindVersions("test.go", tc.data)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestVersionFileEdits(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	files := map[string][]byte{
+		filepath.Join(tmpRootDir, "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test1", "internal", "version.go"): []byte("package internal\n\n" +
+			"// The otel dependency is at 1.0.0.\n" +
+			"const Version = `1.0.0`\n"),
+		filepath.Join(tmpRootDir, "test2", "go.mod"): []byte("module go.opentelemetry.io/test/test2\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test2", "version.go"): []byte("package test2\n\n" +
+			"func version() string { return \"v1.0.0\" }\n"),
+		filepath.Join(tmpRootDir, "README.md"): []byte("# Test\n\n![version](https://img.shields.io/badge/version-v1.0.0-blue)\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files))
+
+	modPathMap := ModulePathMap{
+		"go.opentelemetry.io/test/test1": ModuleFilePath(filepath.Join(tmpRootDir, "test1", "go.mod")),
+		"go.opentelemetry.io/test/test2": ModuleFilePath(filepath.Join(tmpRootDir, "test2", "go.mod")),
+	}
+	modSet := ModuleSet{
+		Version: "v1.1.0",
+		Modules: []ModulePath{"go.opentelemetry.io/test/test1", "go.opentelemetry.io/test/test2"},
+		VersionFiles: []VersionFile{
+			{Path: "internal/version.go", Constant: "Version"},
+			{Path: "*.go", Constant: "version"},
+			{Path: "/README.md", Pattern: `badge/version-(v[0-9.]+)-blue`},
+		},
+	}
+
+	edits, err := VersionFileEdits(tmpRootDir, modSet, modPathMap, modSet.Version)
+	require.NoError(t, err)
+
+	actual := make(map[string]string)
+	for _, edit := range edits {
+		actual[edit.Path] = string(edit.New)
+	}
+	assert.Equal(t, map[string]string{
+		filepath.Join(tmpRootDir, "test1", "internal", "version.go"): "package internal\n\n" +
+			"// The otel dependency is at 1.0.0.\n" +
+			"const Version = `1.1.0`\n",
+		filepath.Join(tmpRootDir, "test2", "version.go"): "package test2\n\n" +
+			"func version() string { return \"v1.1.0\" }\n",
+		filepath.Join(tmpRootDir, "README.md"): "# Test\n\n![version](https://img.shields.io/badge/version-v1.1.0-blue)\n",
+	}, actual)
+
+	modSet.VersionFiles = []VersionFile{{Path: "missing.go", Constant: "Version"}}
+	_, err = VersionFileEdits(tmpRootDir, modSet, modPathMap, modSet.Version)
+	assert.ErrorContains(t, err, `version file "missing.go" does not exist for any module in the set`)
+}
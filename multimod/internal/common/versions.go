@@ -19,9 +19,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 
-	"github.com/spf13/viper"
 	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -29,10 +30,10 @@ const (
 	SemverRegex           = `v` + SemverRegexNumberOnly
 )
 
-// versionConfig is needed to parse the versions.yaml file with viper.
+// versionConfig is needed to parse the versions.yaml file.
 type versionConfig struct {
-	ModuleSets      ModuleSetMap `mapstructure:"module-sets"`
-	ExcludedModules []ModulePath `mapstructure:"excluded-modules"`
+	ModuleSets      ModuleSetMap `yaml:"module-sets"`
+	ExcludedModules []ModulePath `yaml:"excluded-modules"`
 }
 
 // excludedModules functions as a set containing all module paths that are excluded
@@ -44,8 +45,20 @@ type ModuleSetMap map[string]ModuleSet
 
 // ModuleSet holds the version that the specified modules within the set will have.
 type ModuleSet struct {
-	Version string       `mapstructure:"version"`
-	Modules []ModulePath `mapstructure:"modules"`
+	Version string       `yaml:"version"`
+	Modules []ModulePath `yaml:"modules"`
+	// VersionFiles lists the files that hold the version of the module set. If it is empty, a
+	// version.go file next to each go.mod file is updated instead.
+	VersionFiles []VersionFile `yaml:"version-files"`
+	// TagPrefix is prepended to the Git tags of every module in the set, for repos that namespace
+	// their tags.
+	TagPrefix string `yaml:"tag-prefix"`
+	// CommitMessage, BranchName and TagMessage are Go templates for the message of the commit
+	// preparing a release of the set, the name of its branch and the message of the tags. They are
+	// executed with a TemplateData. The default templates are used for those that are empty.
+	CommitMessage string `yaml:"commit-message"`
+	BranchName    string `yaml:"branch-name"`
+	TagMessage    string `yaml:"tag-message"`
 }
 
 // ModulePath holds the module import path, such as "go.opentelemetry.io/otel".
@@ -75,24 +88,37 @@ type ModuleTagName string
 // readVersioningFile reads in a versioning file (typically given as versions.yaml) and returns
 // a versionConfig struct.
 func readVersioningFile(versioningFilename string) (versionConfig, error) {
-	viper.SetConfigFile(versioningFilename)
+	data, err := os.ReadFile(filepath.Clean(versioningFilename))
+	if err != nil {
+		return versionConfig{}, fmt.Errorf("error reading versionsConfig file: %w", err)
+	}
 
-	var versionCfg versionConfig
+	return parseVersioningData(data, versioningFilename)
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return versionConfig{}, fmt.Errorf("error reading versionsConfig file: %w", err)
+// parseVersioningData parses the contents of a versioning file. Unknown keys, values of the wrong
+// kind, invalid module set versions and malformed module paths are all reported together, each
+// with the line and column where it occurs.
+func parseVersioningData(data []byte, versioningFilename string) (versionConfig, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return versionConfig{}, fmt.Errorf("unable to parse versionsConfig: %w", err)
+	}
+
+	v := &schemaValidator{}
+	v.checkKnownFields(&root, reflect.TypeOf(versionConfig{}))
+	if len(v.problems) > 0 {
+		return versionConfig{}, &errInvalidVersioningFile{filename: versioningFilename, problems: v.problems}
 	}
 
-	if err := viper.Unmarshal(&versionCfg); err != nil {
+	var versionCfg versionConfig
+	if err := root.Decode(&versionCfg); err != nil {
 		return versionConfig{}, fmt.Errorf("unable to unmarshal versionsConfig: %w", err)
 	}
 
-	if viper.ConfigFileUsed() != versioningFilename {
-		return versionConfig{}, fmt.Errorf(
-			"config file used (%v) does not match input file (%v)",
-			viper.ConfigFileUsed(),
-			versioningFilename,
-		)
+	v.checkValues(&root)
+	if len(v.problems) > 0 {
+		return versionConfig{}, &errInvalidVersioningFile{filename: versioningFilename, problems: v.problems}
 	}
 
 	return versionCfg, nil
@@ -148,6 +174,29 @@ func (versionCfg versionConfig) getExcludedModules() excludedModulesSet {
 
 // BuildModulePathMap creates a map with module paths as keys and go.mod file paths as values.
 func (versionCfg versionConfig) BuildModulePathMap(root string) (ModulePathMap, error) {
+	allModPathMap, err := findModules(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return versionCfg.excludeModules(allModPathMap), nil
+}
+
+// excludeModules returns the entries of modPathMap whose module is not listed in the excluded
+// modules section of the versioning file.
+func (versionCfg versionConfig) excludeModules(modPathMap ModulePathMap) ModulePathMap {
+	excludedModules := versionCfg.getExcludedModules()
+	included := make(ModulePathMap, len(modPathMap))
+	for modPath, modFilePath := range modPathMap {
+		if _, shouldExclude := excludedModules[modPath]; !shouldExclude {
+			included[modPath] = modFilePath
+		}
+	}
+	return included
+}
+
+// findModules returns the go.mod files under root, keyed by module path.
+func findModules(root string) (ModulePathMap, error) {
 	modPathMap := make(ModulePathMap)
 
 	findGoMod := func(filePath string, info fs.FileInfo, err error) error {
@@ -169,10 +218,7 @@ func (versionCfg versionConfig) BuildModulePathMap(root string) (ModulePathMap,
 			modPath := ModulePath(modPathString)
 			modFilePath := ModuleFilePath(filePath)
 
-			excludedModules := versionCfg.getExcludedModules()
-			if _, shouldExclude := excludedModules[modPath]; !shouldExclude {
-				modPathMap[modPath] = modFilePath
-			}
+			modPathMap[modPath] = modFilePath
 		}
 		return nil
 	}
@@ -183,3 +229,17 @@ func (versionCfg versionConfig) BuildModulePathMap(root string) (ModulePathMap,
 
 	return modPathMap, nil
 }
+
+// expandModulePatternsIn expands the module patterns of the versioning file against the modules
+// found under repoRoot. The repo is only walked if the versioning file has patterns.
+func (versionCfg *versionConfig) expandModulePatternsIn(repoRoot string) error {
+	if !versionCfg.hasModulePatterns() {
+		return nil
+	}
+
+	modPathMap, err := findModules(repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not find modules to expand module patterns: %w", err)
+	}
+	return versionCfg.expandModulePatterns(modPathMap)
+}
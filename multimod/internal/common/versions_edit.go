@@ -0,0 +1,309 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// versioningFileEditor makes targeted edits to the text of a versioning file, so that comments
+// and formatting outside of the edited values are left untouched.
+type versioningFileEditor struct {
+	filename string
+	data     []byte
+	doc      *yaml.Node
+	edits    []textEdit
+}
+
+func newVersioningFileEditor(versioningFilename string) (*versioningFileEditor, error) {
+	data, err := os.ReadFile(filepath.Clean(versioningFilename))
+	if err != nil {
+		return nil, fmt.Errorf("error reading versioning file: %w", err)
+	}
+
+	// Only edit files that are valid to begin with.
+	if _, err = parseVersioningData(data, versioningFilename); err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err = yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("unable to parse versioning file: %w", err)
+	}
+
+	return &versioningFileEditor{
+		filename: versioningFilename,
+		data:     data,
+		doc:      documentContent(&root),
+	}, nil
+}
+
+// moduleSetNode returns the mapping node of the named module set.
+func (e *versioningFileEditor) moduleSetNode(modSetName string) (*yaml.Node, error) {
+	set := mappingValue(mappingValue(e.doc, "module-sets"), modSetName)
+	if set == nil {
+		return nil, &errModuleSetNotFound{modSetName: modSetName, filename: e.filename}
+	}
+	return set, nil
+}
+
+// setVersion replaces the version of the named module set.
+func (e *versioningFileEditor) setVersion(modSetName, version string) error {
+	set, err := e.moduleSetNode(modSetName)
+	if err != nil {
+		return err
+	}
+
+	versionNode := mappingValue(set, "version")
+	if versionNode == nil {
+		return fmt.Errorf("module set %v has no version in versioning file %v", modSetName, e.filename)
+	}
+
+	return e.replaceScalar(versionNode, version)
+}
+
+// replaceScalar replaces the value of a single-line scalar node, keeping its quoting style.
+func (e *versioningFileEditor) replaceScalar(node *yaml.Node, value string) error {
+	offset, err := e.offset(node)
+	if err != nil {
+		return err
+	}
+
+	var quote string
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = `'`
+	case 0:
+		// plain scalars are not quoted
+	default:
+		return fmt.Errorf("%v:%d:%d: unsupported YAML style for value %q", e.filename, node.Line, node.Column, node.Value)
+	}
+
+	oldText := quote + node.Value + quote
+	if !bytes.HasPrefix(e.data[offset:], []byte(oldText)) {
+		return fmt.Errorf("%v:%d:%d: could not locate value %q", e.filename, node.Line, node.Column, node.Value)
+	}
+
+	e.edits = append(e.edits, textEdit{
+		offset: offset,
+		length: len(oldText),
+		text:   quote + value + quote,
+	})
+
+	return nil
+}
+
+// addModules inserts modPaths into the modules of the named module set. Each module is inserted
+// before the first listed module that sorts after it and the comments above that module, so that
+// sorted lists stay sorted, with the indentation and quoting style of the listed modules.
+func (e *versioningFileEditor) addModules(modSetName string, modPaths []ModulePath) error {
+	set, err := e.moduleSetNode(modSetName)
+	if err != nil {
+		return err
+	}
+
+	modules := mappingValue(set, "modules")
+	if modules == nil || modules.Kind != yaml.SequenceNode || len(modules.Content) == 0 {
+		return fmt.Errorf("module set %v has no modules to insert next to in versioning file %v", modSetName, e.filename)
+	}
+	if modules.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("%v:%d:%d: cannot insert modules into a flow sequence", e.filename, modules.Line, modules.Column)
+	}
+
+	// The text before each item on its line, such as "      - ", is reused for the new items.
+	last := modules.Content[len(modules.Content)-1]
+	lineStart, err := lineOffset(e.data, last.Line, 1)
+	if err != nil {
+		return err
+	}
+	offset, err := e.offset(last)
+	if err != nil {
+		return err
+	}
+	prefix := string(e.data[lineStart:offset])
+	if strings.TrimSpace(prefix) != "-" {
+		return fmt.Errorf("%v:%d:%d: unsupported layout of module %q", e.filename, last.Line, last.Column, last.Value)
+	}
+	var quote string
+	switch last.Style {
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = `'`
+	}
+
+	// Modules inserted at the same offset are merged into one edit, keeping their order.
+	sorted := append([]ModulePath(nil), modPaths...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var offsets []int
+	texts := make(map[int]string)
+	for _, modPath := range sorted {
+		at, err := e.moduleInsertOffset(modules, modPath)
+		if err != nil {
+			return err
+		}
+		if _, ok := texts[at]; !ok {
+			offsets = append(offsets, at)
+		}
+		text := prefix + quote + string(modPath) + quote + "\n"
+		if at == len(e.data) && len(e.data) > 0 && e.data[len(e.data)-1] != '\n' && texts[at] == "" {
+			text = "\n" + text
+		}
+		texts[at] += text
+	}
+	for _, at := range offsets {
+		e.edits = append(e.edits, textEdit{offset: at, text: texts[at]})
+	}
+
+	return nil
+}
+
+// moduleInsertOffset returns the offset at which modPath is inserted into the sequence of
+// modules: the start of the first module sorting after it, including the comment lines directly
+// above it, or the end of the line of the last module.
+func (e *versioningFileEditor) moduleInsertOffset(modules *yaml.Node, modPath ModulePath) (int, error) {
+	for _, item := range modules.Content {
+		if item.Value <= string(modPath) {
+			continue
+		}
+
+		// The head comment of the module stays above it.
+		line := item.Line
+		for line > 1 {
+			start, err := lineOffset(e.data, line-1, 1)
+			if err != nil {
+				return 0, err
+			}
+			text := e.data[start:]
+			if i := bytes.IndexByte(text, '\n'); i >= 0 {
+				text = text[:i]
+			}
+			if !bytes.HasPrefix(bytes.TrimSpace(text), []byte("#")) {
+				break
+			}
+			line--
+		}
+		return lineOffset(e.data, line, 1)
+	}
+
+	last := modules.Content[len(modules.Content)-1]
+	offset, err := e.offset(last)
+	if err != nil {
+		return 0, err
+	}
+	i := bytes.IndexByte(e.data[offset:], '\n')
+	if i < 0 {
+		return len(e.data), nil
+	}
+	return offset + i + 1, nil
+}
+
+// offset converts the line and column of node into a byte offset in the file.
+func (e *versioningFileEditor) offset(node *yaml.Node) (int, error) {
+	return lineOffset(e.data, node.Line, node.Column)
+}
+
+// lineOffset returns the byte offset of the given 1-based line and (rune) column in data.
+func lineOffset(data []byte, line, column int) (int, error) {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is out of range", line)
+		}
+		offset += i + 1
+	}
+
+	for c := 1; c < column; c++ {
+		if offset >= len(data) || data[offset] == '\n' {
+			return 0, fmt.Errorf("column %d of line %d is out of range", column, line)
+		}
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+
+	return offset, nil
+}
+
+// write applies all edits and overwrites the versioning file, keeping its permissions.
+func (e *versioningFileEditor) write() error {
+	data := applyTextEdits(e.data, e.edits)
+
+	info, err := os.Stat(e.filename)
+	if err != nil {
+		return fmt.Errorf("could not stat versioning file: %w", err)
+	}
+
+	if err = os.WriteFile(e.filename, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("error overwriting versioning file: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateModuleSetVersions sets the version of each module set given in versions, rewriting the
+// versioning file in place. Only the version values change, so comments and formatting are kept.
+func UpdateModuleSetVersions(versioningFilename string, versions map[string]string) error {
+	e, err := newVersioningFileEditor(versioningFilename)
+	if err != nil {
+		return err
+	}
+
+	for modSetName, version := range versions {
+		if err = e.setVersion(modSetName, version); err != nil {
+			return err
+		}
+	}
+
+	return e.write()
+}
+
+// AddModulesToModuleSets inserts each module of modSets into the modules of the module set it maps
+// to, rewriting the versioning file in place. Only the new list items are added, so comments and
+// formatting are kept.
+func AddModulesToModuleSets(versioningFilename string, modSets map[ModulePath]string) error {
+	e, err := newVersioningFileEditor(versioningFilename)
+	if err != nil {
+		return err
+	}
+
+	modPaths := make(map[string][]ModulePath)
+	var modSetNames []string
+	for modPath, modSetName := range modSets {
+		if _, ok := modPaths[modSetName]; !ok {
+			modSetNames = append(modSetNames, modSetName)
+		}
+		modPaths[modSetName] = append(modPaths[modSetName], modPath)
+	}
+	sort.Strings(modSetNames)
+
+	for _, modSetName := range modSetNames {
+		if err = e.addModules(modSetName, modPaths[modSetName]); err != nil {
+			return err
+		}
+	}
+
+	return e.write()
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateModuleSetVersions(t *testing.T) {
+	original := "# Copyright\n\nmodule-sets:\n" +
+		"  mod-set-1:\n    version: v1.2.3  # comment kept\n    modules:\n      - go.opentelemetry.io/test/test1\n" +
+		"  mod-set-2:\n    version: 'v0.1.0'\n    modules:\n      - go.opentelemetry.io/test2\n" +
+		"  mod-set-3:\n    version: \"v2.2.2\"\n    modules:\n      - go.opentelemetry.io/testroot/v2\n" +
+		"excluded-modules:\n  - go.opentelemetry.io/excluded1\n"
+
+	testCases := []struct {
+		name          string
+		versions      map[string]string
+		expected      string
+		expectedError string
+	}{
+		{
+			name: "plain and quoted versions",
+			versions: map[string]string{
+				"mod-set-1": "v1.3.0",
+				"mod-set-2": "v0.10.0",
+				"mod-set-3": "v2.2.3",
+			},
+			expected: "# Copyright\n\nmodule-sets:\n" +
+				"  mod-set-1:\n    version: v1.3.0  # comment kept\n    modules:\n      - go.opentelemetry.io/test/test1\n" +
+				"  mod-set-2:\n    version: 'v0.10.0'\n    modules:\n      - go.opentelemetry.io/test2\n" +
+				"  mod-set-3:\n    version: \"v2.2.3\"\n    modules:\n      - go.opentelemetry.io/testroot/v2\n" +
+				"excluded-modules:\n  - go.opentelemetry.io/excluded1\n",
+		},
+		{
+			name:          "unknown module set",
+			versions:      map[string]string{"mod-set-4": "v1.0.0"},
+			expected:      original,
+			expectedError: "could not find module set mod-set-4",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			versioningFilename := filepath.Join(t.TempDir(), "versions.yaml")
+			require.NoError(t, os.WriteFile(versioningFilename, []byte(original), 0600))
+
+			err := UpdateModuleSetVersions(versioningFilename, tc.versions)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			actual, err := os.ReadFile(versioningFilename)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+		})
+	}
+}
+
+func TestAddModulesToModuleSets(t *testing.T) {
+	original := "# Copyright\n\nmodule-sets:\n" +
+		"  mod-set-1:\n    version: v1.2.3\n    modules:\n      # Core modules.\n      - go.opentelemetry.io/test/test1\n" +
+		"      # Deprecated,\n      # to be removed.\n      - go.opentelemetry.io/test/test3\n" +
+		"  mod-set-2:\n    version: v0.1.0\n    modules:\n    - \"go.opentelemetry.io/test2\"\n" +
+		"  mod-set-3:\n    version: v2.2.2\n    modules: [go.opentelemetry.io/testroot/v2]\n" +
+		"excluded-modules:\n  - go.opentelemetry.io/excluded1"
+
+	testCases := []struct {
+		name          string
+		modSets       map[ModulePath]string
+		expected      string
+		expectedError string
+	}{
+		{
+			name: "sorted insertion",
+			modSets: map[ModulePath]string{
+				"go.opentelemetry.io/test/test0": "mod-set-1",
+				"go.opentelemetry.io/test/test2": "mod-set-1",
+				"go.opentelemetry.io/test/test4": "mod-set-1",
+				"go.opentelemetry.io/test/test5": "mod-set-1",
+				"go.opentelemetry.io/test3":      "mod-set-2",
+			},
+			expected: "# Copyright\n\nmodule-sets:\n" +
+				"  mod-set-1:\n    version: v1.2.3\n    modules:\n" +
+				"      - go.opentelemetry.io/test/test0\n      # Core modules.\n      - go.opentelemetry.io/test/test1\n" +
+				"      - go.opentelemetry.io/test/test2\n      # Deprecated,\n      # to be removed.\n      - go.opentelemetry.io/test/test3\n" +
+				"      - go.opentelemetry.io/test/test4\n      - go.opentelemetry.io/test/test5\n" +
+				"  mod-set-2:\n    version: v0.1.0\n    modules:\n    - \"go.opentelemetry.io/test2\"\n    - \"go.opentelemetry.io/test3\"\n" +
+				"  mod-set-3:\n    version: v2.2.2\n    modules: [go.opentelemetry.io/testroot/v2]\n" +
+				"excluded-modules:\n  - go.opentelemetry.io/excluded1",
+		},
+		{
+			name:          "flow sequence",
+			modSets:       map[ModulePath]string{"go.opentelemetry.io/testroot2/v2": "mod-set-3"},
+			expected:      original,
+			expectedError: "cannot insert modules into a flow sequence",
+		},
+		{
+			name:          "unknown module set",
+			modSets:       map[ModulePath]string{"go.opentelemetry.io/test4": "mod-set-4"},
+			expected:      original,
+			expectedError: "could not find module set mod-set-4",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			versioningFilename := filepath.Join(t.TempDir(), "versions.yaml")
+			require.NoError(t, os.WriteFile(versioningFilename, []byte(original), 0600))
+
+			err := AddModulesToModuleSets(versioningFilename, tc.modSets)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			actual, err := os.ReadFile(versioningFilename)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+		})
+	}
+}
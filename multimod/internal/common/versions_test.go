@@ -42,6 +42,7 @@ func TestReadVersioningFile(t *testing.T) {
 		name                    string
 		versioningFilename      string
 		ShouldError             bool
+		ExpectedErrors          []string
 		ExpectedModuleSets      ModuleSetMap
 		ExpectedExcludedModules []ModulePath
 	}{
@@ -72,6 +73,47 @@ func TestReadVersioningFile(t *testing.T) {
 			name:                    "invalid version file syntax",
 			versioningFilename:      filepath.Join(testDataDir, "read_versioning_filename/versions_invalid_syntax.yaml"),
 			ShouldError:             true,
+			ExpectedErrors:          []string{"versions_invalid_syntax.yaml:16:3: expected a mapping, found a sequence"},
+			ExpectedModuleSets:      nil,
+			ExpectedExcludedModules: nil,
+		},
+		{
+			name:               "unknown fields",
+			versioningFilename: filepath.Join(testDataDir, "read_versioning_filename/versions_unknown_fields.yaml"),
+			ShouldError:        true,
+			ExpectedErrors: []string{
+				`versions_unknown_fields.yaml:15:1: unknown field "module_sets", expected one of: excluded-modules, module-sets`,
+				`versions_unknown_fields.yaml:23:5: unknown field "module", expected one of: branch-name, commit-message, modules, tag-message, tag-prefix, version, version-files`,
+				`versions_unknown_fields.yaml:26:14: expected a sequence, found a scalar value`,
+				`versions_unknown_fields.yaml:27:1: unknown field "exclude-modules", expected one of: excluded-modules, module-sets`,
+			},
+			ExpectedModuleSets:      nil,
+			ExpectedExcludedModules: nil,
+		},
+		{
+			name:               "invalid versions and module paths",
+			versioningFilename: filepath.Join(testDataDir, "read_versioning_filename/versions_invalid_values.yaml"),
+			ShouldError:        true,
+			ExpectedErrors: []string{
+				`versions_invalid_values.yaml:17:14: module set "mod-set-1" has invalid semver version "1.0.0"`,
+				`versions_invalid_values.yaml:20:9: malformed module path "go.opentelemetry.io/Test Module"`,
+				`versions_invalid_values.yaml:21:3: module set "mod-set-2" has no version`,
+				`versions_invalid_values.yaml:25:14: module set "mod-set-3" has shorthand version "v1.2", expected the form v1.2.3`,
+				`versions_invalid_values.yaml:29:5: malformed module path "excluded1"`,
+			},
+			ExpectedModuleSets:      nil,
+			ExpectedExcludedModules: nil,
+		},
+		{
+			name:               "invalid tag prefix and templates",
+			versioningFilename: filepath.Join(testDataDir, "read_versioning_filename/versions_invalid_templates.yaml"),
+			ShouldError:        true,
+			ExpectedErrors: []string{
+				`versions_invalid_templates.yaml:18:17: tag-prefix "go release/" contains characters not allowed in Git tags`,
+				`versions_invalid_templates.yaml:19:21: invalid commit-message template`,
+				`versions_invalid_templates.yaml:24:18: branch-name template gives invalid branch name "release v1.2.3"`,
+				`versions_invalid_templates.yaml:25:18: could not execute tag-message template`,
+			},
 			ExpectedModuleSets:      nil,
 			ExpectedExcludedModules: nil,
 		},
@@ -86,6 +128,9 @@ func TestReadVersioningFile(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 			}
+			for _, expectedErr := range tc.ExpectedErrors {
+				assert.ErrorContains(t, err, expectedErr)
+			}
 
 			assert.IsType(t, versionConfig{}, actual)
 			assert.Equal(t, tc.ExpectedModuleSets, actual.ModuleSets)
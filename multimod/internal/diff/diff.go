@@ -6,19 +6,73 @@ package diff
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"path"
+	"sort"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
 
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
 )
 
+// Status is the kind of change made to a file.
+type Status string
+
+const (
+	StatusAdded    Status = "added"
+	StatusModified Status = "modified"
+	StatusDeleted  Status = "deleted"
+)
+
+// FileChange is a file changed between two commits. Path is relative to the repo root.
+type FileChange struct {
+	Path   string
+	Status Status
+}
+
+// ModuleChanges lists the files of a module changed since Base.
+type ModuleChanges struct {
+	ModulePath common.ModulePath
+	// Base is the ref the module was compared against: either the tag of the module at the
+	// previous version, or the base ref given in the options.
+	Base  string
+	Files []FileChange
+}
+
+// DefaultInclude lists the patterns of the files compared when Options.Include is empty.
+var DefaultInclude = []string{"*.go"}
+
+// Options configures the refs compared by HasChanged and the files included in the comparison.
+//
+// Include and Exclude patterns use the syntax of path.Match and are matched against the paths of
+// files relative to the directory of their module. A pattern without a slash matches any element
+// of the path, so "*.go" matches all Go files and "testdata" matches everything in testdata
+// directories. A pattern with a slash matches the whole path or one of its parent directories,
+// such as "internal/assets".
+type Options struct {
+	// PreviousVersion is the version of the module set whose tags are compared against, one tag
+	// per module. It is ignored if Base is set.
+	PreviousVersion string
+	// Base is a ref compared against for all modules of the set.
+	Base string
+	// Head is the ref compared to the base. HEAD is used if empty.
+	Head string
+	// Include lists the patterns of the files to compare. DefaultInclude is used if empty.
+	Include []string
+	// Exclude lists the patterns of included files to leave out of the comparison.
+	Exclude []string
+}
+
 type Client interface {
 	HeadCommit(r *git.Repository) (*object.Commit, error)
 	TagCommit(r *git.Repository, tag string) (*object.Commit, error)
-	FilesChanged(headCommit *object.Commit, tagCommit *object.Commit, prefix string, suffix string) ([]string, error)
+	RevisionCommit(r *git.Repository, rev string) (*object.Commit, error)
+	FilesChanged(headCommit *object.Commit, baseCommit *object.Commit, prefix string) ([]FileChange, error)
 }
 
 type GitClient struct{}
@@ -31,37 +85,59 @@ func (g GitClient) HeadCommit(r *git.Repository) (*object.Commit, error) {
 	return r.CommitObject(headRef.Hash())
 }
 
+// TagCommit returns the commit that the tag points at, whether it is annotated or lightweight.
 func (g GitClient) TagCommit(r *git.Repository, tag string) (*object.Commit, error) {
-	tagRef, err := r.Tag(tag)
+	return common.TagCommit(r, tag)
+}
+
+// RevisionCommit returns the commit that rev, such as a branch, tag or commit hash, resolves to.
+func (g GitClient) RevisionCommit(r *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := r.ResolveRevision(plumbing.Revision(rev))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not resolve %v: %w", rev, err)
 	}
+	return r.CommitObject(*hash)
+}
 
-	o, err := r.TagObject(tagRef.Hash())
+// FilesChanged returns the files under prefix that changed from baseCommit to headCommit, sorted
+// by path.
+func (g GitClient) FilesChanged(headCommit *object.Commit, baseCommit *object.Commit, prefix string) ([]FileChange, error) {
+	headTree, err := headCommit.Tree()
 	if err != nil {
-		return nil, fmt.Errorf("tag object error %s %w", tagRef.Hash().String(), err)
+		return nil, err
+	}
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, err
 	}
-	return r.CommitObject(o.Target)
-}
 
-// FilesChanged returns a list of files that have changed between two commits.
-func (g GitClient) FilesChanged(headCommit *object.Commit, tagCommit *object.Commit, prefix string, suffix string) ([]string, error) {
-	changedFiles := []string{}
-	p, err := headCommit.Patch(tagCommit)
+	changes, err := object.DiffTree(baseTree, headTree)
 	if err != nil {
-		return changedFiles, err
+		return nil, err
 	}
 
-	for _, f := range p.FilePatches() {
-		from, to := f.Files()
-		if from != nil && strings.HasSuffix(from.Path(), suffix) && strings.HasPrefix(from.Path(), prefix) {
-			changedFiles = append(changedFiles, from.Path())
-			continue
+	changedFiles := []FileChange{}
+	add := func(p string, status Status) {
+		if strings.HasPrefix(p, prefix) {
+			changedFiles = append(changedFiles, FileChange{Path: p, Status: status})
+		}
+	}
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
 		}
-		if to != nil && strings.HasSuffix(to.Path(), suffix) && strings.HasPrefix(to.Path(), prefix) {
-			changedFiles = append(changedFiles, to.Path())
+		switch action {
+		case merkletrie.Insert:
+			add(change.To.Name, StatusAdded)
+		case merkletrie.Delete:
+			add(change.From.Name, StatusDeleted)
+		case merkletrie.Modify:
+			add(change.To.Name, StatusModified)
 		}
 	}
+
+	sort.Slice(changedFiles, func(i, j int) bool { return changedFiles[i].Path < changedFiles[j].Path })
 	return changedFiles, nil
 }
 
@@ -75,29 +151,48 @@ func normalizeVersion(ver string) string {
 	return fmt.Sprintf("v%s", ver)
 }
 
-func normalizeTag(tagName common.ModuleTagName, ver string) string {
-	if tagName == common.RepoRootTag {
-		return ver
-	}
-	return fmt.Sprintf("%s/%s", tagName, ver)
+func normalizeTag(tagPrefix string, tagName common.ModuleTagName, ver string) string {
+	return common.ModuleTagPrefix(tagPrefix, tagName) + ver
+}
+
+// module is a module of the set being compared.
+type module struct {
+	path    common.ModulePath
+	tagName common.ModuleTagName
+	// tagPrefix is the tag prefix of the module set, if any.
+	tagPrefix string
+	// dir is the directory of the module relative to the repo root, as returned by
+	// common.ModuleDir.
+	dir string
+	// nested lists the directories of the modules nested in dir, whose files are not compared.
+	nested []string
 }
 
-func HasChanged(repoRoot string, versioningFile string, ver string, modset string) ([]string, error) {
-	changedFiles := []string{}
-	ver = normalizeVersion(ver)
+// HasChanged returns the modules of the module set modset with files changed between the refs
+// given in opts. Modules without changes are left out.
+func HasChanged(repoRoot string, versioningFile string, modset string, opts Options) ([]ModuleChanges, error) {
+	if opts.Base == "" && opts.PreviousVersion == "" {
+		return nil, errors.New("either a previous version or a base ref must be given")
+	}
+	if err := checkPatterns(opts.Include, opts.Exclude); err != nil {
+		return nil, err
+	}
 
 	r, err := git.PlainOpen(repoRoot)
 	if err != nil {
-		return changedFiles, fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
+		return nil, fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
 	}
 
-	if e := common.VerifyWorkingTreeClean(r); e != nil {
-		return changedFiles, fmt.Errorf("VerifyWorkingTreeClean failed: %w", e)
+	// Uncommitted changes are only missed when comparing HEAD.
+	if opts.Head == "" {
+		if e := common.VerifyWorkingTreeClean(r); e != nil {
+			return nil, fmt.Errorf("VerifyWorkingTreeClean failed: %w", e)
+		}
 	}
 
 	mset, err := common.NewModuleSetRelease(versioningFile, modset, repoRoot)
 	if err != nil {
-		return changedFiles, err
+		return nil, err
 	}
 
 	// get tag names of mods to update
@@ -107,40 +202,149 @@ func HasChanged(repoRoot string, versioningFile string, ver string, modset strin
 		repoRoot,
 	)
 	if err != nil {
-		return changedFiles, fmt.Errorf("could not retrieve tag names from module paths: %w", err)
+		return nil, fmt.Errorf("could not retrieve tag names from module paths: %w", err)
+	}
+
+	mods := make([]module, 0, len(mset.ModSet.Modules))
+	for i, modPath := range mset.ModSet.Modules {
+		dir := common.ModuleDir(mset.ModPathMap[modPath], repoRoot)
+		mods = append(mods, module{
+			path:      modPath,
+			tagName:   tagNames[i],
+			tagPrefix: mset.ModSet.TagPrefix,
+			dir:       dir,
+			nested:    common.NestedModuleDirs(dir, mset.ModPathMap, repoRoot),
+		})
 	}
 
-	return filesChanged(r, modset, ver, tagNames, GitClient{})
+	return filesChanged(r, modset, mods, opts, GitClient{})
 }
 
-func filesChanged(r *git.Repository, modset string, ver string, tagNames []common.ModuleTagName, client Client) ([]string, error) {
-	changedFiles := []string{}
-	headCommit, err := client.HeadCommit(r)
+func filesChanged(r *git.Repository, modset string, mods []module, opts Options, client Client) ([]ModuleChanges, error) {
+	include := opts.Include
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+
+	var headCommit *object.Commit
+	var err error
+	if opts.Head == "" {
+		headCommit, err = client.HeadCommit(r)
+	} else {
+		headCommit, err = client.RevisionCommit(r, opts.Head)
+	}
 	if err != nil {
-		return changedFiles, err
+		return nil, err
 	}
 
-	// get all modules in modset
-	for _, tagName := range tagNames {
-		tag := normalizeTag(tagName, ver)
-		tagCommit, err := client.TagCommit(r, tag)
-		if err != nil {
-			if errors.Is(err, git.ErrTagNotFound) {
-				log.Printf("Module %s does not have a %s tag", tagName, ver)
-				log.Printf("%s release is required.", modset)
-				return changedFiles, fmt.Errorf("tag not found %s", tag)
+	var baseCommit *object.Commit
+	if opts.Base != "" {
+		if baseCommit, err = client.RevisionCommit(r, opts.Base); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []ModuleChanges
+	for _, mod := range mods {
+		base, commit := opts.Base, baseCommit
+		if base == "" {
+			ver := normalizeVersion(opts.PreviousVersion)
+			base = normalizeTag(mod.tagPrefix, mod.tagName, ver)
+			commit, err = client.TagCommit(r, base)
+			if err != nil {
+				if errors.Is(err, git.ErrTagNotFound) {
+					log.Printf("Module %s does not have a %s tag", mod.path, ver)
+					log.Printf("%s release is required.", modset)
+					return nil, fmt.Errorf("tag not found %s", base)
+				}
+				return nil, err
 			}
-			return changedFiles, err
 		}
 
-		files, err := client.FilesChanged(headCommit, tagCommit, string(tagName), ".go")
+		files, err := client.FilesChanged(headCommit, commit, mod.dir)
 		if err != nil {
-			return changedFiles, err
+			return nil, err
+		}
+
+		var matched []FileChange
+		for _, f := range files {
+			if mod.owns(f.Path) && matchFile(f.Path, mod.dir, include, opts.Exclude) {
+				matched = append(matched, f)
+			}
 		}
-		if len(files) > 0 {
-			changedFiles = append(changedFiles, files...)
+		if len(matched) > 0 {
+			changes = append(changes, ModuleChanges{ModulePath: mod.path, Base: base, Files: matched})
 		}
 	}
 
-	return changedFiles, nil
+	return changes, nil
+}
+
+// owns reports whether the file at p, relative to the repo root, belongs to the module rather than
+// to a module nested in its directory.
+func (m module) owns(p string) bool {
+	if !strings.HasPrefix(p, m.dir) {
+		return false
+	}
+	for _, nestedDir := range m.nested {
+		if strings.HasPrefix(p, nestedDir) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPatterns returns an error if any of the patterns is malformed.
+func checkPatterns(patternLists ...[]string) error {
+	for _, patterns := range patternLists {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchFile reports whether the file at p, relative to the repo root, in the module directory dir
+// matches one of the include patterns and none of the exclude patterns.
+func matchFile(p string, dir string, include []string, exclude []string) bool {
+	rel := strings.TrimPrefix(p, dir)
+	return matchAny(rel, include) && !matchAny(rel, exclude)
+}
+
+// matchAny reports whether rel, a path relative to a module directory, matches any of patterns.
+func matchAny(rel string, patterns []string) bool {
+	elems := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if !strings.Contains(pattern, "/") {
+			for _, elem := range elems {
+				if ok, _ := path.Match(pattern, elem); ok {
+					return true
+				}
+			}
+			continue
+		}
+
+		for i := len(elems); i > 0; i-- {
+			if ok, _ := path.Match(pattern, strings.Join(elems[:i], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteReport writes the changes to w, grouped by module.
+func WriteReport(w io.Writer, changes []ModuleChanges) error {
+	var b strings.Builder
+	for _, mod := range changes {
+		fmt.Fprintf(&b, "%s (since %s):\n", mod.ModulePath, mod.Base)
+		for _, f := range mod.Files {
+			fmt.Fprintf(&b, "  %-8s  %s\n", f.Status, f.Path)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
 }
@@ -5,15 +5,19 @@ package diff
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/build-tools/internal/repo"
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
 )
 
 func TestNormalizeVersion(t *testing.T) {
@@ -41,6 +45,7 @@ func TestNormalizeVersion(t *testing.T) {
 func TestNormalizeTag(t *testing.T) {
 	tests := []struct {
 		name   string
+		prefix string
 		input  common.ModuleTagName
 		output string
 	}{
@@ -54,9 +59,21 @@ func TestNormalizeTag(t *testing.T) {
 			input:  "modset",
 			output: "modset/v1.2.3",
 		},
+		{
+			name:   "repo root tag with tag prefix",
+			prefix: "go/",
+			input:  common.RepoRootTag,
+			output: "go/v1.2.3",
+		},
+		{
+			name:   "version prefixed with tag prefix",
+			prefix: "go/",
+			input:  "modset",
+			output: "go/modset/v1.2.3",
+		},
 	}
 	for _, tt := range tests {
-		require.Equal(t, tt.output, normalizeTag(tt.input, "v1.2.3"))
+		require.Equal(t, tt.output, normalizeTag(tt.prefix, tt.input, "v1.2.3"))
 	}
 }
 
@@ -114,22 +131,29 @@ func TestHasChanged(t *testing.T) {
 			} else {
 				versionFile = filepath.Join(repoRoot, "versions.yaml")
 			}
-			changedFiles, err := HasChanged(repoRoot, versionFile, tt.tag, tt.modset)
+			changes, err := HasChanged(repoRoot, versionFile, tt.modset, Options{PreviousVersion: tt.tag})
 			if tt.err != nil {
 				require.Error(t, err)
 				require.ErrorContains(t, err, tt.err.Error())
 			} else {
 				require.NoError(t, err)
 			}
-			require.False(t, len(changedFiles) > 0)
+			require.Empty(t, changes)
 		})
 	}
+
+	_, err := HasChanged("", "versions.yaml", "tools", Options{})
+	require.ErrorContains(t, err, "either a previous version or a base ref must be given")
+
+	_, err = HasChanged("", "versions.yaml", "tools", Options{PreviousVersion: "v0.8.0", Include: []string{"["}})
+	require.ErrorContains(t, err, `invalid file pattern "["`)
 }
 
 type MockClient struct {
-	files         []string
+	files         []FileChange
 	headCommitErr error
 	tagCommitErr  error
+	revisionErr   error
 }
 
 func (c MockClient) HeadCommit(_ *git.Repository) (*object.Commit, error) {
@@ -138,24 +162,28 @@ func (c MockClient) HeadCommit(_ *git.Repository) (*object.Commit, error) {
 func (c MockClient) TagCommit(_ *git.Repository, _ string) (*object.Commit, error) {
 	return nil, c.tagCommitErr
 }
-func (c MockClient) FilesChanged(_ *object.Commit, _ *object.Commit, _ string, _ string) ([]string, error) {
+func (c MockClient) RevisionCommit(_ *git.Repository, _ string) (*object.Commit, error) {
+	return nil, c.revisionErr
+}
+func (c MockClient) FilesChanged(_ *object.Commit, _ *object.Commit, _ string) ([]FileChange, error) {
 	return c.files, nil
 }
 
 func TestFilesChanged(t *testing.T) {
+	tools := module{path: "go.opentelemetry.io/build-tools", tagName: common.RepoRootTag, nested: []string{"multimod/"}}
+	multimod := module{path: "go.opentelemetry.io/build-tools/multimod", tagName: "multimod", dir: "multimod/"}
+
 	tests := []struct {
 		name     string
-		version  string
-		modset   string
-		tagNames []common.ModuleTagName
+		opts     Options
+		mods     []module
 		cli      MockClient
-		expected bool
+		expected []ModuleChanges
 		err      error
 	}{
 		{
-			name:    "error with head commit",
-			version: "v0.8.0",
-			modset:  "tools",
+			name: "error with head commit",
+			opts: Options{PreviousVersion: "v0.8.0"},
 			cli: MockClient{
 				headCommitErr: object.ErrEntryNotFound,
 			},
@@ -163,35 +191,246 @@ func TestFilesChanged(t *testing.T) {
 		},
 		{
 			name: "tag missing",
+			opts: Options{PreviousVersion: "v0.8.0"},
 			cli: MockClient{
 				tagCommitErr: git.ErrTagNotFound,
 			},
-			tagNames: []common.ModuleTagName{"tools"},
-			version:  "v0.8.0",
-			modset:   "tools",
-			err:      git.ErrTagNotFound,
+			mods: []module{multimod},
+			err:  errors.New("tag not found multimod/v0.8.0"),
+		},
+		{
+			name: "invalid base ref",
+			opts: Options{Base: "invalid"},
+			cli: MockClient{
+				revisionErr: plumbing.ErrReferenceNotFound,
+			},
+			mods: []module{multimod},
+			err:  plumbing.ErrReferenceNotFound,
+		},
+		{
+			name: "changes found, tag exists",
+			opts: Options{PreviousVersion: "0.8.0"},
+			cli: MockClient{
+				files: []FileChange{{Path: "file1.go", Status: StatusModified}},
+			},
+			mods: []module{tools},
+			expected: []ModuleChanges{
+				{
+					ModulePath: "go.opentelemetry.io/build-tools",
+					Base:       "v0.8.0",
+					Files:      []FileChange{{Path: "file1.go", Status: StatusModified}},
+				},
+			},
+		},
+		{
+			name: "changes filtered by module and patterns",
+			opts: Options{Base: "main", Include: []string{"*.go", "go.mod"}, Exclude: []string{"testdata"}},
+			cli: MockClient{
+				files: []FileChange{
+					{Path: "go.mod", Status: StatusModified},
+					{Path: "multimod/go.mod", Status: StatusModified},
+					{Path: "multimod/go.sum", Status: StatusModified},
+					{Path: "multimod/new.go", Status: StatusAdded},
+					{Path: "multimod/old.go", Status: StatusDeleted},
+					{Path: "multimod/testdata/data.go", Status: StatusAdded},
+				},
+			},
+			mods: []module{tools, multimod},
+			expected: []ModuleChanges{
+				{
+					ModulePath: "go.opentelemetry.io/build-tools",
+					Base:       "main",
+					Files:      []FileChange{{Path: "go.mod", Status: StatusModified}},
+				},
+				{
+					ModulePath: "go.opentelemetry.io/build-tools/multimod",
+					Base:       "main",
+					Files: []FileChange{
+						{Path: "multimod/go.mod", Status: StatusModified},
+						{Path: "multimod/new.go", Status: StatusAdded},
+						{Path: "multimod/old.go", Status: StatusDeleted},
+					},
+				},
+			},
 		},
 		{
-			name:    "changes found in to, tag exists",
-			version: "v0.8.0",
-			modset:  "tools",
+			name: "no matching changes",
+			opts: Options{PreviousVersion: "v0.8.0"},
 			cli: MockClient{
-				files: []string{"file1.go"},
+				files: []FileChange{{Path: "multimod/README.md", Status: StatusModified}},
 			},
-			tagNames: []common.ModuleTagName{"tools"},
-			expected: true,
+			mods: []module{multimod},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			changedFiles, err := filesChanged(nil, tt.modset, tt.version, tt.tagNames, tt.cli)
+			changes, err := filesChanged(nil, "tools", tt.mods, tt.opts, tt.cli)
 			if tt.err != nil {
 				require.Error(t, err)
 				require.ErrorContains(t, err, tt.err.Error())
 			} else {
 				require.NoError(t, err)
 			}
-			require.Equal(t, len(changedFiles) > 0, tt.expected)
+			require.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		rel      string
+		patterns []string
+		expected bool
+	}{
+		{rel: "file.go", patterns: []string{"*.go"}, expected: true},
+		{rel: "internal/file.go", patterns: []string{"*.go"}, expected: true},
+		{rel: "go.sum", patterns: []string{"*.go"}, expected: false},
+		{rel: "go.sum", patterns: []string{"*.go", "go.sum"}, expected: true},
+		{rel: "testdata/file.go", patterns: []string{"testdata"}, expected: true},
+		{rel: "internal/testdata/file.go", patterns: []string{"testdata/"}, expected: true},
+		{rel: "internal/assets/logo.png", patterns: []string{"internal/assets"}, expected: true},
+		{rel: "internal/assets/logo.png", patterns: []string{"internal/*/*.png"}, expected: true},
+		{rel: "assets/logo.png", patterns: []string{"internal/assets"}, expected: false},
+		{rel: "file.go", patterns: nil, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			require.Equal(t, tt.expected, matchAny(tt.rel, tt.patterns))
 		})
 	}
 }
+
+func TestWriteReport(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, WriteReport(&b, []ModuleChanges{
+		{
+			ModulePath: "go.opentelemetry.io/build-tools/multimod",
+			Base:       "multimod/v0.8.0",
+			Files: []FileChange{
+				{Path: "multimod/go.mod", Status: StatusModified},
+				{Path: "multimod/new.go", Status: StatusAdded},
+			},
+		},
+	}))
+	require.Equal(t, "go.opentelemetry.io/build-tools/multimod (since multimod/v0.8.0):\n"+
+		"  modified  multimod/go.mod\n"+
+		"  added     multimod/new.go\n", b.String())
+}
+
+func TestGitClientTagCommit(t *testing.T) {
+	r, tagHash, err := commontest.InitNewMemoryRepoWithCommit()
+	require.NoError(t, err)
+
+	_, err = r.CreateTag("lightweight/v1.0.0", tagHash, nil)
+	require.NoError(t, err)
+	_, err = r.CreateTag("annotated/v1.0.0", tagHash, &git.CreateTagOptions{
+		Message: "annotated tag",
+		Tagger:  commontest.TestAuthor,
+	})
+	require.NoError(t, err)
+
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+	f, err := worktree.Filesystem.Create("lightweight/file.go")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("package lightweight\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = worktree.Add("lightweight/file.go")
+	require.NoError(t, err)
+	_, err = worktree.Commit("change", &git.CommitOptions{Author: commontest.TestAuthor})
+	require.NoError(t, err)
+
+	client := GitClient{}
+	headCommit, err := client.HeadCommit(r)
+	require.NoError(t, err)
+
+	for _, tag := range []string{"lightweight/v1.0.0", "annotated/v1.0.0"} {
+		t.Run(tag, func(t *testing.T) {
+			tagCommit, err := client.TagCommit(r, tag)
+			require.NoError(t, err)
+			require.Equal(t, tagHash, tagCommit.Hash)
+
+			files, err := client.FilesChanged(headCommit, tagCommit, "lightweight/")
+			require.NoError(t, err)
+			require.Equal(t, []FileChange{{Path: "lightweight/file.go", Status: StatusAdded}}, files)
+		})
+	}
+
+	_, err = client.TagCommit(r, "missing/v1.0.0")
+	require.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestHasChangedRefs(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	r, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versionsFile: []byte("module-sets:\n  all:\n    version: v1.1.0\n    modules:\n" +
+			"      - go.opentelemetry.io/test\n      - go.opentelemetry.io/test/a\n"),
+		filepath.Join(tmpRootDir, "go.mod"):      []byte("module go.opentelemetry.io/test\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "root.go"):     []byte("package test\n"),
+		filepath.Join(tmpRootDir, "a", "go.mod"): []byte("module go.opentelemetry.io/test/a\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "a", "a.go"):   []byte("package a\n"),
+	}))
+	baseHash, err := commontest.CommitAll(r, "base")
+	require.NoError(t, err)
+	for _, tag := range []string{"v1.0.0", "a/v1.0.0"} {
+		_, err = r.CreateTag(tag, baseHash, nil)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, os.Remove(filepath.Join(tmpRootDir, "root.go")))
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, "a", "go.mod"):                    []byte("module go.opentelemetry.io/test/a\n\ngo 1.20\n"),
+		filepath.Join(tmpRootDir, "a", "a.go"):                      []byte("package a\n\nconst A = 1\n"),
+		filepath.Join(tmpRootDir, "a", "testdata", "embedded.json"): []byte("{}\n"),
+	}))
+	headHash, err := commontest.CommitAll(r, "head")
+	require.NoError(t, err)
+
+	expected := []ModuleChanges{
+		{
+			ModulePath: "go.opentelemetry.io/test",
+			Base:       "v1.0.0",
+			Files:      []FileChange{{Path: "root.go", Status: StatusDeleted}},
+		},
+		{
+			ModulePath: "go.opentelemetry.io/test/a",
+			Base:       "a/v1.0.0",
+			Files:      []FileChange{{Path: "a/a.go", Status: StatusModified}},
+		},
+	}
+	changes, err := HasChanged(tmpRootDir, versionsFile, "all", Options{PreviousVersion: "v1.0.0"})
+	require.NoError(t, err)
+	require.Equal(t, expected, changes)
+
+	changes, err = HasChanged(tmpRootDir, versionsFile, "all", Options{
+		Base:    baseHash.String(),
+		Head:    headHash.String(),
+		Include: []string{"*.go", "go.mod", "*.json"},
+		Exclude: []string{"a.go"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ModuleChanges{
+		{
+			ModulePath: "go.opentelemetry.io/test",
+			Base:       baseHash.String(),
+			Files:      []FileChange{{Path: "root.go", Status: StatusDeleted}},
+		},
+		{
+			ModulePath: "go.opentelemetry.io/test/a",
+			Base:       baseHash.String(),
+			Files: []FileChange{
+				{Path: "a/go.mod", Status: StatusModified},
+				{Path: "a/testdata/embedded.json", Status: StatusAdded},
+			},
+		},
+	}, changes)
+
+	changes, err = HasChanged(tmpRootDir, versionsFile, "all", Options{Base: "v1.0.0", Head: "a/v1.0.0"})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -0,0 +1,6 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package graph exports the dependencies between the modules, or between the
+// module sets, of a repo as Graphviz DOT, Mermaid or JSON.
+package graph
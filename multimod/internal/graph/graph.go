@@ -0,0 +1,209 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+)
+
+// Levels of the graph supported by Build.
+const (
+	LevelModule    = "module"
+	LevelModuleSet = "module-set"
+)
+
+// Output formats supported by Write.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJSON    = "json"
+)
+
+// Graph is the dependency graph of the modules or the module sets of a repo.
+type Graph struct {
+	Level string `json:"level"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Node is a module or a module set.
+type Node struct {
+	// ID is the module path of a module, or the name of a module set.
+	ID        string `json:"id"`
+	ModuleSet string `json:"module_set"`
+	Version   string `json:"version"`
+}
+
+// Edge is a dependency of the node From on the node To.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// StableToUnstable is true if From has a stable version and To does not. Stable modules
+	// should not depend on unstable ones.
+	StableToUnstable bool `json:"stable_to_unstable,omitempty"`
+}
+
+func Run(versioningFile string, level string, format string) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+
+	modVersioning, err := common.NewModuleVersioning(versioningFile, repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not read module versioning: %w", err)
+	}
+
+	g, err := Build(modVersioning, level)
+	if err != nil {
+		return fmt.Errorf("could not build dependency graph: %w", err)
+	}
+
+	if err = Write(os.Stdout, g, format); err != nil {
+		return fmt.Errorf("could not write dependency graph: %w", err)
+	}
+	return nil
+}
+
+// Build returns the dependency graph of the modules listed in module sets, or of the module sets
+// themselves, depending on level. Nodes and edges are sorted.
+func Build(modVersioning common.ModuleVersioning, level string) (Graph, error) {
+	dependencies, err := modVersioning.Dependencies()
+	if err != nil {
+		return Graph{}, fmt.Errorf("could not get dependencies of module versioning: %w", err)
+	}
+
+	g := Graph{Level: level, Nodes: []Node{}, Edges: []Edge{}}
+	switch level {
+	case LevelModule:
+		for modPath, info := range modVersioning.ModInfoMap {
+			g.Nodes = append(g.Nodes, Node{ID: string(modPath), ModuleSet: info.ModuleSetName, Version: info.Version})
+		}
+		for modPath, deps := range dependencies {
+			for _, dep := range deps {
+				g.Edges = append(g.Edges, Edge{From: string(modPath), To: string(dep)})
+			}
+		}
+	case LevelModuleSet:
+		for modSetName, modSet := range modVersioning.ModSetMap {
+			g.Nodes = append(g.Nodes, Node{ID: modSetName, ModuleSet: modSetName, Version: modSet.Version})
+		}
+		seen := make(map[Edge]bool)
+		for modPath, deps := range dependencies {
+			from := modVersioning.ModInfoMap[modPath].ModuleSetName
+			for _, dep := range deps {
+				e := Edge{From: from, To: modVersioning.ModInfoMap[dep].ModuleSetName}
+				if e.From != e.To && !seen[e] {
+					seen[e] = true
+					g.Edges = append(g.Edges, e)
+				}
+			}
+		}
+	default:
+		return Graph{}, fmt.Errorf("unknown graph level %q, expected %v or %v", level, LevelModule, LevelModuleSet)
+	}
+
+	versions := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		versions[n.ID] = n.Version
+	}
+	for i, e := range g.Edges {
+		g.Edges[i].StableToUnstable = common.IsStableVersion(versions[e.From]) && !common.IsStableVersion(versions[e.To])
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g, nil
+}
+
+// Write writes the graph to w in the given format: FormatDOT, FormatMermaid or FormatJSON.
+func Write(w io.Writer, g Graph, format string) error {
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, g)
+	case FormatMermaid:
+		return writeMermaid(w, g)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	default:
+		return fmt.Errorf("unknown output format %q, expected %v, %v or %v", format, FormatDOT, FormatMermaid, FormatJSON)
+	}
+}
+
+// label returns the text shown for a node.
+func (n Node) label(level string) string {
+	if level == LevelModuleSet {
+		return n.ID + " " + n.Version
+	}
+	return n.ID + "\n" + n.ModuleSet + " " + n.Version
+}
+
+// writeDOT writes the graph as a Graphviz digraph. Stable to unstable edges are drawn in red.
+func writeDOT(w io.Writer, g Graph) error {
+	var b strings.Builder
+
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %q [label=%q];\n", n.ID, n.label(g.Level))
+	}
+	for _, e := range g.Edges {
+		if e.StableToUnstable {
+			fmt.Fprintf(&b, "  %q -> %q [color=red, fontcolor=red, label=\"stable to unstable\"];\n", e.From, e.To)
+			continue
+		}
+		fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart. Node IDs are replaced by short
+// identifiers, since Mermaid does not allow module paths as IDs. Stable to unstable edges are
+// drawn in red.
+func writeMermaid(w io.Writer, g Graph) error {
+	var b strings.Builder
+
+	ids := make(map[string]string, len(g.Nodes))
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(n.label(g.Level), "\n", "<br/>")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.ID], strings.ReplaceAll(label, `"`, "#quot;"))
+	}
+
+	var highlighted []string
+	for i, e := range g.Edges {
+		if e.StableToUnstable {
+			fmt.Fprintf(&b, "  %s -->|stable to unstable| %s\n", ids[e.From], ids[e.To])
+			highlighted = append(highlighted, fmt.Sprint(i))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+	}
+	if len(highlighted) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red,color:red\n", strings.Join(highlighted, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func newTestModuleVersioning(t *testing.T) common.ModuleVersioning {
+	tmpRootDir := t.TempDir()
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versionsFile: []byte("module-sets:\n" +
+			"  stable:\n    version: v1.2.0\n    modules:\n" +
+			"      - example.com/a\n      - example.com/b\n" +
+			"  unstable:\n    version: v0.3.0\n    modules:\n" +
+			"      - example.com/c\n"),
+		filepath.Join(tmpRootDir, "a", "go.mod"): []byte("module example.com/a\n\ngo 1.19\n\n" +
+			"require (\n\texample.com/b v1.2.0\n\texample.com/c v0.3.0\n\texample.com/external v1.0.0\n)\n"),
+		filepath.Join(tmpRootDir, "b", "go.mod"): []byte("module example.com/b\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "c", "go.mod"): []byte("module example.com/c\n\ngo 1.19\n\nrequire example.com/b v1.2.0\n"),
+	}))
+
+	modVersioning, err := common.NewModuleVersioning(versionsFile, tmpRootDir)
+	require.NoError(t, err)
+	return modVersioning
+}
+
+func TestBuild(t *testing.T) {
+	modVersioning := newTestModuleVersioning(t)
+
+	g, err := Build(modVersioning, LevelModule)
+	require.NoError(t, err)
+	assert.Equal(t, Graph{
+		Level: LevelModule,
+		Nodes: []Node{
+			{ID: "example.com/a", ModuleSet: "stable", Version: "v1.2.0"},
+			{ID: "example.com/b", ModuleSet: "stable", Version: "v1.2.0"},
+			{ID: "example.com/c", ModuleSet: "unstable", Version: "v0.3.0"},
+		},
+		Edges: []Edge{
+			{From: "example.com/a", To: "example.com/b"},
+			{From: "example.com/a", To: "example.com/c", StableToUnstable: true},
+			{From: "example.com/c", To: "example.com/b"},
+		},
+	}, g)
+
+	g, err = Build(modVersioning, LevelModuleSet)
+	require.NoError(t, err)
+	assert.Equal(t, Graph{
+		Level: LevelModuleSet,
+		Nodes: []Node{
+			{ID: "stable", ModuleSet: "stable", Version: "v1.2.0"},
+			{ID: "unstable", ModuleSet: "unstable", Version: "v0.3.0"},
+		},
+		Edges: []Edge{
+			{From: "stable", To: "unstable", StableToUnstable: true},
+			{From: "unstable", To: "stable"},
+		},
+	}, g)
+
+	_, err = Build(modVersioning, "package")
+	assert.ErrorContains(t, err, `unknown graph level "package"`)
+}
+
+func TestWrite(t *testing.T) {
+	g := Graph{
+		Level: LevelModule,
+		Nodes: []Node{
+			{ID: "example.com/a", ModuleSet: "stable", Version: "v1.2.0"},
+			{ID: "example.com/b", ModuleSet: "stable", Version: "v1.2.0"},
+			{ID: "example.com/c", ModuleSet: "unstable", Version: "v0.3.0"},
+		},
+		Edges: []Edge{
+			{From: "example.com/a", To: "example.com/b"},
+			{From: "example.com/a", To: "example.com/c", StableToUnstable: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, g, FormatDOT))
+	assert.Equal(t, "digraph dependencies {\n"+
+		"  rankdir=LR;\n"+
+		"  node [shape=box];\n"+
+		"  \"example.com/a\" [label=\"example.com/a\\nstable v1.2.0\"];\n"+
+		"  \"example.com/b\" [label=\"example.com/b\\nstable v1.2.0\"];\n"+
+		"  \"example.com/c\" [label=\"example.com/c\\nunstable v0.3.0\"];\n"+
+		"  \"example.com/a\" -> \"example.com/b\";\n"+
+		"  \"example.com/a\" -> \"example.com/c\" [color=red, fontcolor=red, label=\"stable to unstable\"];\n"+
+		"}\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, g, FormatMermaid))
+	assert.Equal(t, "flowchart LR\n"+
+		"  n0[\"example.com/a<br/>stable v1.2.0\"]\n"+
+		"  n1[\"example.com/b<br/>stable v1.2.0\"]\n"+
+		"  n2[\"example.com/c<br/>unstable v0.3.0\"]\n"+
+		"  n0 --> n1\n"+
+		"  n0 -->|stable to unstable| n2\n"+
+		"  linkStyle 1 stroke:red,color:red\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, g, FormatJSON))
+	var decoded Graph
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, g, decoded)
+	assert.Contains(t, buf.String(), `"stable_to_unstable": true`)
+
+	assert.ErrorContains(t, Write(&buf, g, "svg"), `unknown output format "svg"`)
+}
@@ -0,0 +1,7 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package plan builds a reviewable summary of the release of a module set:
+// the tags that will be created, the files changed since the previous tags,
+// and the modules in the repo that depend on the set.
+package plan
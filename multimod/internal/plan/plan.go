@@ -0,0 +1,315 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/diff"
+)
+
+// Output formats supported by Write.
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// Plan summarizes the release of a module set.
+type Plan struct {
+	ModuleSet  string      `json:"module_set"`
+	Version    string      `json:"version"`
+	Modules    []Module    `json:"modules"`
+	Dependents []Dependent `json:"dependents"`
+}
+
+// Module describes the release of a single module of the set.
+type Module struct {
+	ModulePath string `json:"module"`
+	// CurrentTag is the latest tag of the module before the new version. It is empty if the
+	// module has never been tagged.
+	CurrentTag string `json:"current_tag,omitempty"`
+	NewTag     string `json:"new_tag"`
+	// ChangedFiles lists the files of the module changed since CurrentTag.
+	ChangedFiles []string `json:"changed_files"`
+}
+
+// Dependent is a module outside of the set that requires modules of the set at a version other
+// than the new one, and therefore needs a new version itself once the set is released.
+type Dependent struct {
+	ModulePath string `json:"module"`
+	// ModuleSet is the name of the module set of the dependent. It is empty for modules not listed
+	// in the versioning file.
+	ModuleSet string        `json:"module_set,omitempty"`
+	Requires  []Requirement `json:"requires"`
+}
+
+// Requirement is a requirement of a dependent on a module of the set.
+type Requirement struct {
+	ModulePath string `json:"module"`
+	Version    string `json:"version"`
+}
+
+func Run(versioningFile string, moduleSetName string, format string) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+
+	p, err := Build(repoRoot, versioningFile, moduleSetName, diff.GitClient{})
+	if err != nil {
+		return fmt.Errorf("could not build release plan: %w", err)
+	}
+
+	if err = Write(os.Stdout, p, format); err != nil {
+		return fmt.Errorf("could not write release plan: %w", err)
+	}
+	return nil
+}
+
+// Build computes the release plan of a module set, using client to compare the current commit with
+// the previous tags of each module.
+func Build(repoRoot string, versioningFile string, moduleSetName string, client diff.Client) (Plan, error) {
+	r, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return Plan{}, fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
+	}
+
+	msr, err := common.NewModuleSetRelease(versioningFile, moduleSetName, repoRoot)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if err = msr.CheckGitTagsAlreadyExist(r); err != nil {
+		return Plan{}, err
+	}
+
+	tags, err := tagNames(r)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	headCommit, err := client.HeadCommit(r)
+	if err != nil {
+		return Plan{}, fmt.Errorf("could not get head commit: %w", err)
+	}
+
+	p := Plan{
+		ModuleSet:  moduleSetName,
+		Version:    msr.ModSetVersion(),
+		Modules:    []Module{},
+		Dependents: []Dependent{},
+	}
+
+	newTags := msr.ModuleFullTagNames()
+	for i, modPath := range msr.ModSetPaths() {
+		mod := Module{
+			ModulePath:   string(modPath),
+			CurrentTag:   previousTag(tags, common.ModuleTagPrefix(msr.ModSet.TagPrefix, msr.TagNames[i]), modPath, msr.ModSetVersion()),
+			NewTag:       newTags[i],
+			ChangedFiles: []string{},
+		}
+		dir := common.ModuleDir(msr.ModPathMap[modPath], repoRoot)
+
+		if mod.CurrentTag != "" {
+			tagCommit, err := client.TagCommit(r, mod.CurrentTag)
+			if err != nil {
+				return Plan{}, fmt.Errorf("could not get commit of tag %v: %w", mod.CurrentTag, err)
+			}
+
+			files, err := client.FilesChanged(headCommit, tagCommit, dir)
+			if err != nil {
+				return Plan{}, fmt.Errorf("could not get files changed since %v: %w", mod.CurrentTag, err)
+			}
+			mod.ChangedFiles = ownFiles(files, common.NestedModuleDirs(dir, msr.ModPathMap, repoRoot))
+		}
+
+		p.Modules = append(p.Modules, mod)
+	}
+
+	if p.Dependents, err = dependents(msr); err != nil {
+		return Plan{}, err
+	}
+
+	return p, nil
+}
+
+// tagNames returns the short names of all tags in the repo.
+func tagNames(r *git.Repository) ([]string, error) {
+	iter, err := r.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("error getting repo tags: %w", err)
+	}
+
+	var names []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		names = append(names, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list git tags: %w", err)
+	}
+	return names, nil
+}
+
+// previousTag returns the tag of the highest version of the module at modPath below version, or an
+// empty string if there is none. The tags of the module start with modTagPrefix, as returned by
+// common.ModuleTagPrefix. Only versions matching the major version suffix of modPath are
+// considered, since modules of different major versions may share a tag name.
+func previousTag(tags []string, modTagPrefix string, modPath common.ModulePath, version string) string {
+	var prev, prevVersion string
+	for _, tag := range tags {
+		v, ok := common.TagVersion(tag, modTagPrefix)
+		if !ok || !common.MajorVersionMatches(modPath, v) || semver.Compare(v, version) >= 0 {
+			continue
+		}
+		if prev == "" || semver.Compare(v, prevVersion) > 0 {
+			prev, prevVersion = tag, v
+		}
+	}
+	return prev
+}
+
+// ownFiles returns the paths of the files that do not belong to the modules nested in the
+// directories nested.
+func ownFiles(files []diff.FileChange, nested []string) []string {
+	own := []string{}
+	for _, file := range files {
+		isNested := false
+		for _, nestedDir := range nested {
+			if strings.HasPrefix(file.Path, nestedDir) {
+				isNested = true
+				break
+			}
+		}
+		if !isNested {
+			own = append(own, file.Path)
+		}
+	}
+	sort.Strings(own)
+	return own
+}
+
+// dependents returns the modules outside of the set that require modules of the set at a version
+// other than the version of the set.
+func dependents(msr common.ModuleSetRelease) ([]Dependent, error) {
+	inSet := make(map[string]bool, len(msr.ModSetPaths()))
+	for _, modPath := range msr.ModSetPaths() {
+		inSet[string(modPath)] = true
+	}
+
+	deps := []Dependent{}
+	for modPath, modFilePath := range msr.ModPathMap {
+		if inSet[string(modPath)] {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read go.mod file: %w", err)
+		}
+
+		modFile, err := modfile.ParseLax(string(modFilePath), data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse go.mod file: %w", err)
+		}
+
+		var requires []Requirement
+		for _, req := range modFile.Require {
+			if inSet[req.Mod.Path] && semver.Compare(req.Mod.Version, msr.ModSetVersion()) != 0 {
+				requires = append(requires, Requirement{ModulePath: req.Mod.Path, Version: req.Mod.Version})
+			}
+		}
+		if len(requires) == 0 {
+			continue
+		}
+
+		deps = append(deps, Dependent{
+			ModulePath: string(modPath),
+			ModuleSet:  msr.ModInfoMap[modPath].ModuleSetName,
+			Requires:   requires,
+		})
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].ModulePath < deps[j].ModulePath })
+	return deps, nil
+}
+
+// Write writes the plan to w in the given format, either FormatMarkdown or FormatJSON.
+func Write(w io.Writer, p Plan, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return writeMarkdown(w, p)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	default:
+		return fmt.Errorf("unknown output format %q, expected %v or %v", format, FormatMarkdown, FormatJSON)
+	}
+}
+
+// writeMarkdown writes the plan as markdown suitable for a pull request description.
+func writeMarkdown(w io.Writer, p Plan) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Release `%s` %s\n\n", p.ModuleSet, p.Version)
+	b.WriteString("| Module | Current tag | New tag | Changed files |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, mod := range p.Modules {
+		current, changed := "(none)", "new module"
+		if mod.CurrentTag != "" {
+			current = "`" + mod.CurrentTag + "`"
+			changed = fmt.Sprint(len(mod.ChangedFiles))
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | `%s` | %s |\n", mod.ModulePath, current, mod.NewTag, changed)
+	}
+
+	for _, mod := range p.Modules {
+		if len(mod.ChangedFiles) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details>\n<summary>Files changed in <code>%s</code> since <code>%s</code></summary>\n\n",
+			mod.ModulePath, mod.CurrentTag)
+		for _, file := range mod.ChangedFiles {
+			fmt.Fprintf(&b, "- `%s`\n", file)
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	b.WriteString("\n### Dependents\n\n")
+	if len(p.Dependents) == 0 {
+		b.WriteString("No modules outside of the set depend on it.\n")
+	} else {
+		b.WriteString("The following modules will need a new version to require the new release:\n\n")
+		b.WriteString("| Module | Module set | Requires |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, dep := range p.Dependents {
+			modSet := "(none)"
+			if dep.ModuleSet != "" {
+				modSet = "`" + dep.ModuleSet + "`"
+			}
+			requires := make([]string, 0, len(dep.Requires))
+			for _, req := range dep.Requires {
+				requires = append(requires, fmt.Sprintf("`%s %s`", req.ModulePath, req.Version))
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", dep.ModulePath, modSet, strings.Join(requires, "<br>"))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+	"go.opentelemetry.io/build-tools/multimod/internal/diff"
+)
+
+// TestMain performs setup for the tests and suppress printing logs.
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestPreviousTag(t *testing.T) {
+	tags := []string{"v1.0.0", "a/v1.0.0", "a/v1.1.0", "a/v1.3.0", "a/b/v1.2.0", "a/not-a-version", "ab/v1.2.0", "a/v2.0.0", "a/v2.1.0", "go/a/v1.1.5"}
+
+	assert.Equal(t, "a/v1.1.0", previousTag(tags, "a/", "example.com/a", "v1.2.0"))
+	assert.Equal(t, "a/b/v1.2.0", previousTag(tags, "a/b/", "example.com/a/b", "v1.3.0"))
+	assert.Equal(t, "v1.0.0", previousTag(tags, "", "example.com", "v1.2.0"))
+	assert.Equal(t, "", previousTag(tags, "a/", "example.com/a", "v1.0.0"))
+	assert.Equal(t, "", previousTag(tags, "c/", "example.com/c", "v1.0.0"))
+	assert.Equal(t, "a/v1.3.0", previousTag(tags, "a/", "example.com/a", "v1.4.0"))
+	assert.Equal(t, "a/v2.1.0", previousTag(tags, "a/", "example.com/a/v2", "v2.2.0"))
+	assert.Equal(t, "", previousTag(tags, "a/", "example.com/a/v3", "v3.0.0"))
+	assert.Equal(t, "go/a/v1.1.5", previousTag(tags, "go/a/", "example.com/a", "v1.2.0"))
+}
+
+func TestBuild(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versionsFile: []byte("module-sets:\n" +
+			"  stable:\n    version: v1.2.0\n    modules:\n" +
+			"      - go.opentelemetry.io/test/test1\n      - go.opentelemetry.io/test/test3\n" +
+			"  other:\n    version: v0.3.0\n    modules:\n" +
+			"      - go.opentelemetry.io/test/test1/nested\n      - go.opentelemetry.io/test/test2\n"),
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"):           []byte("module go.opentelemetry.io/test/test1\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test", "test1", "nested", "go.mod"): []byte("module go.opentelemetry.io/test/test1/nested\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test/test2\n\ngo 1.19\n\n" +
+			"require go.opentelemetry.io/test/test1 v1.1.0\n"),
+	}))
+	releaseHash, err := commontest.CommitAll(repo, "release")
+	require.NoError(t, err)
+
+	for _, tagName := range []string{"test/test1/v1.0.0", "test/test1/v1.1.0"} {
+		_, err = repo.CreateTag(tagName, releaseHash, &git.CreateTagOptions{
+			Message: tagName,
+			Tagger:  commontest.TestAuthor,
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "test1.go"):            []byte("package test1\n"),
+		filepath.Join(tmpRootDir, "test", "test1", "nested", "nested.go"): []byte("package nested\n"),
+		filepath.Join(tmpRootDir, "test", "test3", "go.mod"):              []byte("module go.opentelemetry.io/test/test3\n\ngo 1.19\n"),
+	}))
+	_, err = commontest.CommitAll(repo, "changes")
+	require.NoError(t, err)
+
+	actual, err := Build(tmpRootDir, versionsFile, "stable", diff.GitClient{})
+	require.NoError(t, err)
+
+	expected := Plan{
+		ModuleSet: "stable",
+		Version:   "v1.2.0",
+		Modules: []Module{
+			{
+				ModulePath:   "go.opentelemetry.io/test/test1",
+				CurrentTag:   "test/test1/v1.1.0",
+				NewTag:       "test/test1/v1.2.0",
+				ChangedFiles: []string{"test/test1/test1.go"},
+			},
+			{
+				ModulePath:   "go.opentelemetry.io/test/test3",
+				NewTag:       "test/test3/v1.2.0",
+				ChangedFiles: []string{},
+			},
+		},
+		Dependents: []Dependent{
+			{
+				ModulePath: "go.opentelemetry.io/test/test2",
+				ModuleSet:  "other",
+				Requires:   []Requirement{{ModulePath: "go.opentelemetry.io/test/test1", Version: "v1.1.0"}},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	_, err = repo.CreateTag("test/test1/v1.2.0", releaseHash, &git.CreateTagOptions{
+		Message: "test/test1/v1.2.0",
+		Tagger:  commontest.TestAuthor,
+	})
+	require.NoError(t, err)
+	_, err = Build(tmpRootDir, versionsFile, "stable", diff.GitClient{})
+	assert.ErrorAs(t, err, &common.ErrInconsistentGitTagsExist{})
+}
+
+func TestWrite(t *testing.T) {
+	p := Plan{
+		ModuleSet: "stable",
+		Version:   "v1.2.0",
+		Modules: []Module{
+			{
+				ModulePath:   "go.opentelemetry.io/test/test1",
+				CurrentTag:   "test/test1/v1.1.0",
+				NewTag:       "test/test1/v1.2.0",
+				ChangedFiles: []string{"test/test1/test1.go"},
+			},
+			{
+				ModulePath:   "go.opentelemetry.io/test/test3",
+				NewTag:       "test/test3/v1.2.0",
+				ChangedFiles: []string{},
+			},
+		},
+		Dependents: []Dependent{
+			{
+				ModulePath: "go.opentelemetry.io/test/test2",
+				ModuleSet:  "other",
+				Requires:   []Requirement{{ModulePath: "go.opentelemetry.io/test/test1", Version: "v1.1.0"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, p, FormatMarkdown))
+	assert.Equal(t, "## Release `stable` v1.2.0\n\n"+
+		"| Module | Current tag | New tag | Changed files |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| `go.opentelemetry.io/test/test1` | `test/test1/v1.1.0` | `test/test1/v1.2.0` | 1 |\n"+
+		"| `go.opentelemetry.io/test/test3` | (none) | `test/test3/v1.2.0` | new module |\n"+
+		"\n<details>\n<summary>Files changed in <code>go.opentelemetry.io/test/test1</code> since <code>test/test1/v1.1.0</code></summary>\n\n"+
+		"- `test/test1/test1.go`\n"+
+		"\n</details>\n"+
+		"\n### Dependents\n\n"+
+		"The following modules will need a new version to require the new release:\n\n"+
+		"| Module | Module set | Requires |\n"+
+		"| --- | --- | --- |\n"+
+		"| `go.opentelemetry.io/test/test2` | `other` | `go.opentelemetry.io/test/test1 v1.1.0` |\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, p, FormatJSON))
+	var decoded Plan
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, p, decoded)
+	assert.Contains(t, buf.String(), `"current_tag": "test/test1/v1.1.0"`)
+
+	assert.ErrorContains(t, Write(&buf, p, "yaml"), `unknown output format "yaml"`)
+}
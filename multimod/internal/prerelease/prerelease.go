@@ -17,6 +17,7 @@ package prerelease
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -30,77 +31,174 @@ import (
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
 )
 
-func Run(versioningFile string, moduleSetNames []string, allModuleSets bool, skipModTidy bool, commitToDifferentBranch bool) {
+func Run(versioningFile string, moduleSetNames []string, allModuleSets bool, skipModTidy bool, goModTidyCompat string, commitToDifferentBranch bool, dryRun bool, skipChecks []string) error {
 	repoRoot, err := repo.FindRoot()
 	if err != nil {
-		log.Fatalf("unable to find repo root: %v", err)
+		return fmt.Errorf("unable to find repo root: %w", err)
 	}
 	log.Printf("Using repo with root at %s\n\n", repoRoot)
 
+	skipped, err := common.ParseSkippedChecks(skipChecks)
+	if err != nil {
+		return err
+	}
+
 	if allModuleSets {
 		moduleSetNames, err = common.GetAllModuleSetNames(versioningFile, repoRoot)
 		if err != nil {
-			log.Fatalf("could not automatically get all module set names: %v", err)
+			return fmt.Errorf("could not automatically get all module set names: %w", err)
 		}
 	}
 
+	// Module sets are prepared in the order they must be merged and tagged.
+	moduleSetNames, err = common.GetModuleSetOrder(versioningFile, repoRoot, moduleSetNames)
+	if err != nil {
+		return fmt.Errorf("could not order module sets: %w", err)
+	}
+
 	repo, err := git.PlainOpen(repoRoot)
 	if err != nil {
-		log.Fatalf("could not open repo at %v: %v", repoRoot, err)
+		return fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
 	}
 
 	if err = common.VerifyWorkingTreeClean(repo); err != nil {
-		log.Fatalf("VerifyWorkingTreeClean failed: %v", err)
+		return fmt.Errorf("VerifyWorkingTreeClean failed: %w", err)
 	}
 
+	var prepared []string
 	for _, moduleSetName := range moduleSetNames {
-		p, err := newPrerelease(versioningFile, moduleSetName, repoRoot)
-		if err != nil {
-			log.Fatalf("Error creating new prerelease struct: %v", err)
-		}
-
 		log.Printf("===== Module Set: %v =====\n", moduleSetName)
 
-		modSetUpToDate, err := p.checkModuleSetUpToDate(repo)
-		if err != nil {
-			log.Fatal(err)
+		label := moduleSetName
+		if dryRun {
+			err = DryRunModuleSet(os.Stdout, repo, versioningFile, moduleSetName, repoRoot, skipModTidy, commitToDifferentBranch)
+		} else {
+			var branch string
+			branch, _, err = PrepareModuleSet(repo, versioningFile, moduleSetName, repoRoot, goModTidyCompat, skipModTidy, commitToDifferentBranch, skipped)
+			if branch != "" {
+				label = fmt.Sprintf("%v (branch %v)", moduleSetName, branch)
+			}
 		}
-		if modSetUpToDate {
+		if errors.Is(err, ErrModuleSetUpToDate) {
 			log.Println("Module set already up to date (git tags already exist). Skipping...")
 			continue
 		}
-		log.Println("Updating versions for module set...")
-
-		if err = p.updateAllVersionGo(); err != nil {
-			log.Fatalf("updateAllVersionGo failed: %v", err)
+		if err != nil {
+			return err
 		}
+		prepared = append(prepared, label)
+	}
 
-		if err = p.updateAllGoModFiles(); err != nil {
-			log.Fatalf("updateAllGoModFiles failed: %v", err)
-		}
+	if dryRun {
+		log.Println("=========\nDry run finished. No changes were made.")
+		return nil
+	}
 
-		if skipModTidy {
-			log.Println("Skipping 'go mod tidy'...")
-		} else {
-			if err = common.RunGoModTidy(p.ModuleSetRelease.ModuleVersioning.ModPathMap); err != nil {
-				log.Fatal("could not run Go Mod Tidy: ", err)
-			}
+	var order strings.Builder
+	for i, name := range prepared {
+		fmt.Fprintf(&order, "%d. %v\n", i+1, name)
+	}
+	log.Printf(`=========
+Prerelease finished successfully. Now checkout the new branch(es) and verify the changes.
+
+Then, if necessary, commit changes and push to upstream/make a pull request.
+Module sets depend on the ones listed before them, so merge and tag them in this order:
+%v`, order.String())
+	return nil
+}
+
+// ErrModuleSetUpToDate is returned by PrepareModuleSet when Git tags already exist for the
+// version of the module set.
+var ErrModuleSetUpToDate = errors.New("module set already up to date (git tags already exist)")
+
+// PrepareModuleSet updates the version files and go.mod files for the modules in a single module
+// set, runs "go mod tidy -compat=<goModTidyCompat>" for the modules whose go.mod file changed, runs
+// the pre-tag checks that are not skipped on the modules of the set and commits the changes. The
+// tidy check is skipped along with "go mod tidy". If a check fails, the changes are discarded, so
+// the working tree must be clean beforehand. If commitToDifferentBranch is true, the commit is
+// made on a new branch whose name is returned, and the original branch is checked out again
+// afterwards.
+func PrepareModuleSet(repo *git.Repository, versioningFile, moduleSetName, repoRoot, goModTidyCompat string, skipModTidy, commitToDifferentBranch bool, skipChecks common.SkippedChecks) (string, plumbing.Hash, error) {
+	p, err := newPrerelease(versioningFile, moduleSetName, repoRoot)
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("error creating new prerelease struct: %w", err)
+	}
+
+	modSetUpToDate, err := p.checkModuleSetUpToDate(repo)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if modSetUpToDate {
+		return "", plumbing.ZeroHash, ErrModuleSetUpToDate
+	}
+	log.Println("Updating versions for module set...")
+
+	if err = p.updateAllVersionFiles(); err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("updateAllVersionFiles failed: %w", err)
+	}
+
+	changedModFiles, err := p.updateAllGoModFiles()
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("updateAllGoModFiles failed: %w", err)
+	}
+
+	if skipModTidy {
+		log.Println("Skipping 'go mod tidy'...")
+	} else {
+		if err = common.RunGoModTidy(changedModFiles, common.GoModTidyOptions{Compat: goModTidyCompat}); err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("could not run Go Mod Tidy: %w", err)
 		}
+	}
 
-		if err = commitChanges(p.ModuleSetRelease, commitToDifferentBranch, repo); err != nil {
-			log.Fatalf("commitChangesToNewBranch failed: %v", err)
+	checks := common.PreTagCheckOptions{Skip: common.SkippedChecks{}, GoModTidyCompat: goModTidyCompat}
+	for check, skip := range skipChecks {
+		checks.Skip[check] = skip
+	}
+	if skipModTidy {
+		checks.Skip[common.CheckTidy] = true
+	}
+	files, err := common.WorkingTreeFiles(repoRoot)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if err = p.ModuleSetRelease.RunPreTagChecks(files, checks); err != nil {
+		if discardErr := discardChanges(repo); discardErr != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("module set %v is not ready to be tagged: %w, and its changes could not be discarded: %v", moduleSetName, err, discardErr)
 		}
+		return "", plumbing.ZeroHash, fmt.Errorf("module set %v is not ready to be tagged, its changes were discarded: %w", moduleSetName, err)
 	}
 
-	log.Println(`=========
-Prerelease finished successfully. Now checkout the new branch(es) and verify the changes.
+	branchName, hash, err := commitChanges(p.ModuleSetRelease, commitToDifferentBranch, repo)
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("commitChangesToNewBranch failed: %w", err)
+	}
+
+	return branchName, hash, nil
+}
+
+// DryRunModuleSet writes the version file and go.mod edits, and the commit, that PrepareModuleSet
+// would make for a single module set to w, without modifying the repository.
+func DryRunModuleSet(w io.Writer, repo *git.Repository, versioningFile, moduleSetName, repoRoot string, skipModTidy, commitToDifferentBranch bool) error {
+	p, err := newPrerelease(versioningFile, moduleSetName, repoRoot)
+	if err != nil {
+		return fmt.Errorf("error creating new prerelease struct: %w", err)
+	}
+
+	modSetUpToDate, err := p.checkModuleSetUpToDate(repo)
+	if err != nil {
+		return err
+	}
+	if modSetUpToDate {
+		return ErrModuleSetUpToDate
+	}
 
-Then, if necessary, commit changes and push to upstream/make a pull request.`)
+	return p.dryRun(w, repoRoot, skipModTidy, commitToDifferentBranch)
 }
 
 // prerelease holds fields needed to update one module set at a time.
 type prerelease struct {
 	common.ModuleSetRelease
+	repoRoot string
 }
 
 func newPrerelease(versioningFilename, modSetToUpdate, repoRoot string) (prerelease, error) {
@@ -111,6 +209,7 @@ func newPrerelease(versioningFilename, modSetToUpdate, repoRoot string) (prerele
 
 	return prerelease{
 		ModuleSetRelease: modRelease,
+		repoRoot:         repoRoot,
 	}, nil
 }
 
@@ -129,9 +228,15 @@ func (p prerelease) checkModuleSetUpToDate(repo *git.Repository) (bool, error) {
 	return false, nil
 }
 
-// updateAllVersionGo updates the version.go file containing a hardcoded semver version string
-// for modules within a set, if the file exists.
-func (p prerelease) updateAllVersionGo() error {
+// versionFileEdits computes the edits to the files holding the version of the module set. These
+// are the version files declared for the set in the versioning file or, if none are declared,
+// the version.go files containing a hardcoded semver version string for modules within the set.
+func (p prerelease) versionFileEdits() ([]common.FileEdit, error) {
+	if len(p.ModuleSetRelease.ModSet.VersionFiles) > 0 {
+		return common.VersionFileEdits(p.repoRoot, p.ModuleSetRelease.ModSet, p.ModuleSetRelease.ModuleVersioning.ModPathMap, p.ModuleSetRelease.ModSetVersion())
+	}
+
+	var edits []common.FileEdit
 	for _, modPath := range p.ModuleSetRelease.ModSetPaths() {
 		modFilePath := p.ModuleSetRelease.ModuleVersioning.ModPathMap[modPath]
 
@@ -144,78 +249,158 @@ func (p prerelease) updateAllVersionGo() error {
 			if os.IsNotExist(err) {
 				continue
 			}
-			return fmt.Errorf("could not check existence of %v: %w", versionGoFilePath, err)
+			return nil, fmt.Errorf("could not check existence of %v: %w", versionGoFilePath, err)
 		}
-		if err = updateVersionGoFile(versionGoFilePath, p.ModuleSetRelease.ModSetVersion()); err != nil {
-			return fmt.Errorf("could not update %v: %w", versionGoFilePath, err)
+
+		edit, err := versionGoEdit(versionGoFilePath, p.ModuleSetRelease.ModSetVersion())
+		if err != nil {
+			return nil, fmt.Errorf("could not update %v: %w", versionGoFilePath, err)
+		}
+		if edit.Changed() {
+			edits = append(edits, edit)
 		}
+	}
+	return edits, nil
+}
 
+// updateAllVersionFiles updates the files holding the version of the module set.
+func (p prerelease) updateAllVersionFiles() error {
+	edits, err := p.versionFileEdits()
+	if err != nil {
+		return err
 	}
-	return nil
+
+	for _, edit := range edits {
+		log.Printf("... Updating file %v\n", edit.Path)
+	}
+	return common.WriteFileEdits(edits)
 }
 
-// updateVersionGoFile updates one version.go file.
+// versionGoEdit computes the edit to one version.go file.
 // TODO: a potential improvement is to use an AST package rather than regex to perform replacement.
-func updateVersionGoFile(filePath string, newVersion string) error {
+func versionGoEdit(filePath string, newVersion string) (common.FileEdit, error) {
 	if !strings.HasSuffix(filePath, "version.go") {
-		return errors.New("cannot update file passed that does not end with version.go")
+		return common.FileEdit{}, errors.New("cannot update file passed that does not end with version.go")
 	}
-	log.Printf("... Updating file %v\n", filePath)
 
-	newVersionGoFile, err := os.ReadFile(filepath.Clean(filePath))
+	oldVersionGoFile, err := os.ReadFile(filepath.Clean(filePath))
 	if err != nil {
-		panic(err)
+		return common.FileEdit{}, fmt.Errorf("error reading version.go file: %w", err)
 	}
 
 	oldVersionRegex := common.SemverRegexNumberOnly
 	r, err := regexp.Compile(oldVersionRegex)
 	if err != nil {
-		return fmt.Errorf("error compiling regex: %w", err)
+		return common.FileEdit{}, fmt.Errorf("error compiling regex: %w", err)
 	}
 
 	newVersionNumberOnly := strings.TrimPrefix(newVersion, "v")
 
-	newVersionGoFile = r.ReplaceAll(newVersionGoFile, []byte(newVersionNumberOnly))
+	return common.FileEdit{
+		Path: filePath,
+		Old:  oldVersionGoFile,
+		New:  r.ReplaceAll(oldVersionGoFile, []byte(newVersionNumberOnly)),
+	}, nil
+}
+
+// modFilePaths returns the paths of the go.mod files of all modules in the repo.
+func (p prerelease) modFilePaths() []common.ModuleFilePath {
+	modFilePaths := make([]common.ModuleFilePath, 0, len(p.ModuleSetRelease.ModuleVersioning.ModPathMap))
 
-	// overwrite the version.go file
-	if err := os.WriteFile(filePath, newVersionGoFile, 0600); err != nil {
-		return fmt.Errorf("error overwriting go.mod file: %w", err)
+	for _, filePath := range p.ModuleSetRelease.ModuleVersioning.ModPathMap {
+		modFilePaths = append(modFilePaths, filePath)
 	}
 
-	return nil
+	return modFilePaths
 }
 
 // updateAllGoModFiles updates ALL modules' requires sections to use the newVersion number
-// for the modules given in newModPaths.
-func (p prerelease) updateAllGoModFiles() error {
-	modFilePaths := make([]common.ModuleFilePath, 0, len(p.ModuleSetRelease.ModuleVersioning.ModPathMap))
+// for the modules given in newModPaths. It returns the paths of the go.mod files that changed.
+func (p prerelease) updateAllGoModFiles() ([]common.ModuleFilePath, error) {
+	edits, err := common.UpdateGoModFiles(p.modFilePaths(), p.ModuleSetRelease.ModSetPaths(), p.ModuleSetRelease.ModSetVersion())
+	if err != nil {
+		return nil, fmt.Errorf("could not update all go mod files: %w", err)
+	}
 
-	for _, filePath := range p.ModuleSetRelease.ModuleVersioning.ModPathMap {
-		modFilePaths = append(modFilePaths, filePath)
+	return common.ModuleFilePaths(edits), nil
+}
+
+// dryRun writes the changes that PrepareModuleSet would make for the module set to w, without
+// modifying the repository.
+func (p prerelease) dryRun(w io.Writer, repoRoot string, skipModTidy, commitToDifferentBranch bool) error {
+	versionFileEdits, err := p.versionFileEdits()
+	if err != nil {
+		return fmt.Errorf("versionFileEdits failed: %w", err)
 	}
 
-	if err := common.UpdateGoModFiles(modFilePaths, p.ModuleSetRelease.ModSetPaths(), p.ModuleSetRelease.ModSetVersion()); err != nil {
-		return fmt.Errorf("could not update all go mod files: %w", err)
+	goModEdits, err := common.GoModFileEdits(p.modFilePaths(), p.ModuleSetRelease.ModSetPaths(), p.ModuleSetRelease.ModSetVersion())
+	if err != nil {
+		return fmt.Errorf("could not compute go.mod edits: %w", err)
+	}
+
+	if err = common.PrintFileEdits(w, append(versionFileEdits, common.FileEdits(goModEdits)...), repoRoot); err != nil {
+		return err
+	}
+
+	if !skipModTidy && len(goModEdits) > 0 {
+		fmt.Fprintf(w, "Would run 'go mod tidy' in the %d modules whose go.mod file changed.\n", len(goModEdits))
+	}
+
+	message, err := p.ModuleSetRelease.CommitMessage()
+	if err != nil {
+		return err
+	}
+	if commitToDifferentBranch {
+		branch, err := p.ModuleSetRelease.BranchName()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Would commit %q to new branch %v.\n", message, branch)
+	} else {
+		fmt.Fprintf(w, "Would commit %q to the current branch.\n", message)
 	}
 
 	return nil
 }
 
-func commitChanges(msr common.ModuleSetRelease, commitToDifferentBranch bool, repo *git.Repository) error {
-	commitMessage := fmt.Sprintf("Prepare %v for version %v", msr.ModSetName, msr.ModSetVersion())
+// commitChanges commits the changes made for a module set, with the commit message and branch name
+// configured for the set, returning the name of the new branch
+// (if one was created) and the hash of the commit.
+// discardChanges restores the tracked files of the working tree to their committed content and
+// removes untracked files, undoing the changes made to a clean working tree.
+func discardChanges(repo *git.Repository) error {
+	worktree, err := common.GetWorktree(repo)
+	if err != nil {
+		return err
+	}
+	if err = worktree.Reset(&git.ResetOptions{Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("could not reset working tree: %w", err)
+	}
+	if err = worktree.Clean(&git.CleanOptions{}); err != nil {
+		return fmt.Errorf("could not remove untracked files: %w", err)
+	}
+	return nil
+}
+
+func commitChanges(msr common.ModuleSetRelease, commitToDifferentBranch bool, repo *git.Repository) (string, plumbing.Hash, error) {
+	message, err := msr.CommitMessage()
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
 
+	var newBranchName string
 	var hash plumbing.Hash
-	var err error
 	if commitToDifferentBranch {
-		branchNameElements := []string{"prerelease", msr.ModSetName, msr.ModSetVersion()}
-		branchName := strings.Join(branchNameElements, "_")
-		hash, err = common.CommitChangesToNewBranch(branchName, commitMessage, repo, nil)
+		if newBranchName, err = msr.BranchName(); err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+		hash, err = common.CommitChangesToNewBranch(newBranchName, message, repo, nil)
 	} else {
-		hash, err = common.CommitChanges(commitMessage, repo, nil)
+		hash, err = common.CommitChanges(message, repo, nil)
 	}
 	if err != nil {
-		return err
+		return "", plumbing.ZeroHash, err
 	}
 	log.Printf("Commit successful. Hash of commit: %s\n", hash)
-	return nil
+	return newBranchName, hash, nil
 }
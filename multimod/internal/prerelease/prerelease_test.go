@@ -15,6 +15,7 @@
 package prerelease
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"os"
@@ -270,7 +271,7 @@ func TestUpdateAllVersionGo(t *testing.T) {
 			p, err := newPrerelease(versioningFilename, tc.modSetName, tmpRootDir)
 			require.NoError(t, err)
 
-			err = p.updateAllVersionGo()
+			err = p.updateAllVersionFiles()
 			require.NoError(t, err)
 
 			for versionGoFilePath, expectedByteOutput := range tc.expectedVersionGoOutputs {
@@ -441,7 +442,7 @@ func TestUpdateAllGoModFiles(t *testing.T) {
 			p, err := newPrerelease(versioningFilename, tc.modSetName, tmpRootDir)
 			require.NoError(t, err)
 
-			err = p.updateAllGoModFiles()
+			_, err = p.updateAllGoModFiles()
 			require.NoError(t, err)
 
 			for modFilePathSuffix, expectedByteOutput := range tc.expectedOutputModFiles {
@@ -453,3 +454,156 @@ func TestUpdateAllGoModFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestDryRun(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "dry_run", "versions_valid.yaml")
+
+	tmpRootDir := t.TempDir()
+	files := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3\n\n" +
+			"go 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "version.go"): []byte("package test3\n\n" +
+			"const version = \"0.0.1\"\n"),
+		filepath.Join(tmpRootDir, "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/prerelease/testroot\n\n" +
+			"go 1.16\n\n" +
+			"require go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3 v0.0.1\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files), "could not create file tree")
+
+	p, err := newPrerelease(versioningFilename, "mod-set-2", tmpRootDir)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name                    string
+		skipModTidy             bool
+		commitToDifferentBranch bool
+		expectedTail            string
+	}{
+		{
+			name:                    "new branch",
+			commitToDifferentBranch: true,
+			expectedTail: "Would run 'go mod tidy' in the 1 modules whose go.mod file changed.\n" +
+				"Would commit \"Prepare mod-set-2 for version v0.1.0\" to new branch prerelease_mod-set-2_v0.1.0.\n",
+		},
+		{
+			name:         "current branch without tidy",
+			skipModTidy:  true,
+			expectedTail: "Would commit \"Prepare mod-set-2 for version v0.1.0\" to the current branch.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, p.dryRun(&buf, tmpRootDir, tc.skipModTidy, tc.commitToDifferentBranch))
+
+			expected := "--- a/test/version.go\n" +
+				"+++ b/test/version.go\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" package test3\n" +
+				" \n" +
+				"-const version = \"0.0.1\"\n" +
+				"+const version = \"0.1.0\"\n" +
+				"--- a/go.mod\n" +
+				"+++ b/go.mod\n" +
+				"@@ -2,4 +2,4 @@\n" +
+				" \n" +
+				" go 1.16\n" +
+				" \n" +
+				"-require go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3 v0.0.1\n" +
+				"+require go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3 v0.1.0\n" +
+				tc.expectedTail
+			assert.Equal(t, expected, buf.String())
+
+			// Nothing is written.
+			for path, expected := range files {
+				actual, err := os.ReadFile(filepath.Clean(path))
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual)
+			}
+		})
+	}
+}
+
+func TestPrepareModuleSetChecks(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "dry_run", "versions_valid.yaml")
+
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+	files := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3\n\n" +
+			"go 1.16\n"),
+		filepath.Join(tmpRootDir, "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/prerelease/testroot\n\n" +
+			"go 1.16\n\n" +
+			"require go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3 v0.1.0\n\n" +
+			"replace go.opentelemetry.io/build-tools/multimod/internal/prerelease/test3 => ./test\n"),
+		filepath.Join(tmpRootDir, "version.go"): []byte("package testroot\n\n" +
+			"const version = \"0.0.1\"\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files), "could not create file tree")
+	_, err = commontest.CommitAll(repo, "add modules")
+	require.NoError(t, err)
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name, cfg.User.Email = commontest.TestAuthor.Name, commontest.TestAuthor.Email
+	require.NoError(t, repo.SetConfig(cfg))
+
+	// The changes are discarded if a check fails.
+	_, _, err = PrepareModuleSet(repo, versioningFilename, "mod-set-3", tmpRootDir, "", true, false, nil)
+	assert.ErrorIs(t, err, common.ErrPreTagChecksFailed)
+	assert.ErrorContains(t, err, "points at a local path")
+	assert.NoError(t, common.VerifyWorkingTreeClean(repo))
+	for path, expected := range files {
+		actual, err := os.ReadFile(filepath.Clean(path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, hash, err := PrepareModuleSet(repo, versioningFilename, "mod-set-3", tmpRootDir, "", true, false,
+		common.SkippedChecks{common.CheckLocalReplace: true})
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, hash, head.Hash())
+	actual, err := os.ReadFile(filepath.Join(tmpRootDir, "version.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package testroot\n\nconst version = \"0.2.0\"\n", string(actual))
+}
+
+func TestUpdateAllVersionFilesDeclared(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "update_all_version_files", "versions_valid.yaml")
+
+	tmpRootDir := t.TempDir()
+	files := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/prerelease/test/test1\n\n" +
+			"go 1.16\n"),
+		// Only the declared constant is updated, not other versions in the file.
+		filepath.Join(tmpRootDir, "test", "test1", "internal", "version.go"): []byte("package internal\n\n" +
+			"const Version = \"1.0.0\"\n\n" +
+			"const minGoVersion = \"1.19.0\"\n"),
+		// Undeclared version.go files are left untouched.
+		filepath.Join(tmpRootDir, "test", "test1", "version.go"): []byte("package test1\n\n" +
+			"const version = \"1.0.0\"\n"),
+		filepath.Join(tmpRootDir, "README.md"): []byte("![version](https://img.shields.io/badge/version-v1.0.0-blue)\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files), "could not create file tree")
+
+	p, err := newPrerelease(versioningFilename, "mod-set-1", tmpRootDir)
+	require.NoError(t, err)
+	require.NoError(t, p.updateAllVersionFiles())
+
+	expected := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "internal", "version.go"): []byte("package internal\n\n" +
+			"const Version = \"1.2.3\"\n\n" +
+			"const minGoVersion = \"1.19.0\"\n"),
+		filepath.Join(tmpRootDir, "test", "test1", "version.go"): []byte("package test1\n\n" +
+			"const version = \"1.0.0\"\n"),
+		filepath.Join(tmpRootDir, "README.md"): []byte("![version](https://img.shields.io/badge/version-v1.2.3-blue)\n"),
+	}
+	for path, expectedByteOutput := range expected {
+		actual, err := os.ReadFile(filepath.Clean(path))
+		require.NoError(t, err)
+		assert.Equal(t, string(expectedByteOutput), string(actual))
+	}
+}
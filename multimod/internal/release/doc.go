@@ -0,0 +1,7 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package release orchestrates the prerelease, tag and push steps of a
+// release for one or more module sets, recording its progress in a state file
+// so that an interrupted release can be resumed.
+package release
@@ -0,0 +1,307 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package release
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/prerelease"
+	"go.opentelemetry.io/build-tools/multimod/internal/tag"
+)
+
+func Run(versioningFile string, moduleSetNames []string, allModuleSets bool, commitHash, remote, stateFile string, skipModTidy bool, goModTidyCompat string, signingMode string, skipChecks []string) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+
+	mode, err := tag.ParseSigningMode(signingMode)
+	if err != nil {
+		return err
+	}
+	log.Printf("Using repo with root at %s\n\n", repoRoot)
+
+	skipped, err := common.ParseSkippedChecks(skipChecks)
+	if err != nil {
+		return err
+	}
+
+	if stateFile == "" {
+		stateFile = filepath.Join(repoRoot, ".git", DefaultStateFileName)
+	}
+
+	r, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
+	}
+
+	rel := releaser{
+		versioningFile: versioningFile,
+		repoRoot:       repoRoot,
+		stateFile:      stateFile,
+		runner: gitRunner{
+			repo:           r,
+			versioningFile: versioningFile,
+			repoRoot:       repoRoot,
+			remote:         remote,
+			skipModTidy:    skipModTidy,
+			tidyCompat:     goModTidyCompat,
+			signingMode:    mode,
+			skipChecks:     skipped,
+		},
+	}
+
+	if err = rel.run(moduleSetNames, allModuleSets, commitHash); err != nil {
+		return fmt.Errorf("release failed: %w", err)
+	}
+	return nil
+}
+
+// stepRunner performs the individual steps of a release. It allows the sequencing of the steps
+// to be tested independently of the steps themselves.
+type stepRunner interface {
+	// prepare updates and commits the files of a module set to a new branch, returning its name.
+	prepare(moduleSetName string) (string, error)
+	// tag runs the pre-tag checks of a module set and creates its tags on the given commit, returning
+	// their names.
+	tag(moduleSetName, commitHash string) ([]string, error)
+	// push pushes the given tags to the remote and verifies that they point at the given commit.
+	push(tags []string, commitHash string) error
+}
+
+// gitRunner is the stepRunner used for actual releases.
+type gitRunner struct {
+	repo           *git.Repository
+	versioningFile string
+	repoRoot       string
+	remote         string
+	skipModTidy    bool
+	tidyCompat     string
+	signingMode    tag.SigningMode
+	skipChecks     common.SkippedChecks
+}
+
+func (g gitRunner) prepare(moduleSetName string) (string, error) {
+	if err := common.VerifyWorkingTreeClean(g.repo); err != nil {
+		return "", fmt.Errorf("VerifyWorkingTreeClean failed: %w", err)
+	}
+
+	branchName, _, err := prerelease.PrepareModuleSet(g.repo, g.versioningFile, moduleSetName, g.repoRoot, g.tidyCompat, g.skipModTidy, true, g.skipChecks)
+	return branchName, err
+}
+
+func (g gitRunner) tag(moduleSetName, commitHash string) ([]string, error) {
+	checks := common.PreTagCheckOptions{Skip: common.SkippedChecks{}, GoModTidyCompat: g.tidyCompat}
+	for check, skip := range g.skipChecks {
+		checks.Skip[check] = skip
+	}
+	// The tidy check is skipped along with "go mod tidy".
+	if g.skipModTidy {
+		checks.Skip[common.CheckTidy] = true
+	}
+	return tag.TagModuleSet(g.versioningFile, moduleSetName, g.repoRoot, commitHash, g.signingMode, checks)
+}
+
+func (g gitRunner) push(tags []string, commitHash string) error {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(commitHash))
+	if err != nil {
+		return fmt.Errorf("could not resolve commit %v: %w", commitHash, err)
+	}
+
+	if err = tag.PushTags(g.repoRoot, g.remote, tags); err != nil {
+		return err
+	}
+
+	if err = tag.VerifyRemoteTags(g.repoRoot, g.remote, tags, *hash); err != nil {
+		return fmt.Errorf("pushed tags could not be verified: %w", err)
+	}
+
+	log.Printf("Verified %d tags on %v\n", len(tags), g.remote)
+	return nil
+}
+
+// releaser drives the release of module sets through each step, saving its progress after every
+// step so that a later invocation can continue where a previous one stopped.
+type releaser struct {
+	versioningFile string
+	repoRoot       string
+	stateFile      string
+	runner         stepRunner
+}
+
+func (rel releaser) run(moduleSetNames []string, allModuleSets bool, commitHash string) error {
+	st, err := loadState(rel.stateFile)
+	if err != nil {
+		return err
+	}
+
+	if st == nil {
+		if allModuleSets {
+			moduleSetNames, err = common.GetAllModuleSetNames(rel.versioningFile, rel.repoRoot)
+			if err != nil {
+				return fmt.Errorf("could not automatically get all module set names: %w", err)
+			}
+		}
+		if st, err = rel.newState(moduleSetNames); err != nil {
+			return err
+		}
+		if err = st.save(rel.stateFile); err != nil {
+			return err
+		}
+		log.Printf("Starting release, progress is recorded in %v\n", rel.stateFile)
+	} else {
+		log.Printf("Resuming release recorded in %v\n", rel.stateFile)
+	}
+
+	selected, err := st.selectModuleSets(moduleSetNames)
+	if err != nil {
+		return fmt.Errorf("%w (release in progress is recorded in %v)", err, rel.stateFile)
+	}
+
+	for _, ms := range selected {
+		if ms.Step != stepPending {
+			continue
+		}
+		log.Printf("===== Module Set: %v =====\n", ms.Name)
+
+		branch, err := rel.runner.prepare(ms.Name)
+		if err != nil {
+			return fmt.Errorf("could not prepare module set %v: %w", ms.Name, err)
+		}
+		ms.Branch = branch
+		ms.Step = stepPrepared
+		if err = st.save(rel.stateFile); err != nil {
+			return err
+		}
+	}
+
+	var awaitingMerge []*moduleSetState
+	for _, ms := range selected {
+		if ms.Step == stepPrepared {
+			awaitingMerge = append(awaitingMerge, ms)
+		}
+	}
+
+	if len(awaitingMerge) > 0 && commitHash == "" {
+		var branches []string
+		for _, ms := range awaitingMerge {
+			branches = append(branches, fmt.Sprintf("%v (module set %v, version %v)", ms.Branch, ms.Name, ms.Version))
+		}
+		log.Printf(`=========
+The following prerelease branches are ready, listed in the order they must be merged:
+%v
+
+Push them, open and merge a pull request for each, then run release again with
+--commit-hash set to the merged commit to tag and push the module set(s).
+`, strings.Join(branches, "\n"))
+		return nil
+	}
+
+	for _, ms := range awaitingMerge {
+		if err = rel.checkVersionUnchanged(ms); err != nil {
+			return err
+		}
+
+		log.Printf("===== Tagging Module Set: %v =====\n", ms.Name)
+		tags, err := rel.runner.tag(ms.Name, commitHash)
+		if err != nil {
+			return fmt.Errorf("could not tag module set %v: %w", ms.Name, err)
+		}
+		ms.Commit = commitHash
+		ms.Tags = tags
+		ms.Step = stepTagged
+		if err = st.save(rel.stateFile); err != nil {
+			return err
+		}
+	}
+
+	for _, ms := range selected {
+		if ms.Step != stepTagged {
+			continue
+		}
+
+		log.Printf("===== Pushing Module Set: %v =====\n", ms.Name)
+		if err = rel.runner.push(ms.Tags, ms.Commit); err != nil {
+			return fmt.Errorf("could not push tags of module set %v: %w", ms.Name, err)
+		}
+		ms.Step = stepPushed
+		if err = st.save(rel.stateFile); err != nil {
+			return err
+		}
+	}
+
+	if !st.done() {
+		var remaining []string
+		for _, ms := range st.ModuleSets {
+			if ms.Step != stepPushed {
+				remaining = append(remaining, fmt.Sprintf("%v (%v)", ms.Name, ms.Step))
+			}
+		}
+		log.Printf("=========\nModule sets still to be released: %v\n", strings.Join(remaining, ", "))
+		return nil
+	}
+
+	if err = os.Remove(rel.stateFile); err != nil {
+		return fmt.Errorf("could not remove release state file: %w", err)
+	}
+	log.Println("=========\nRelease finished successfully.")
+
+	return nil
+}
+
+// newState creates the state for a new release of the given module sets, recording the version
+// each is being released at.
+func (rel releaser) newState(moduleSetNames []string) (*state, error) {
+	if len(moduleSetNames) == 0 {
+		return nil, errors.New("no module sets to release")
+	}
+
+	modVersioning, err := common.NewModuleVersioning(rel.versioningFile, rel.repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("call to NewModuleVersioning failed: %w", err)
+	}
+
+	// Module sets are released in the order they must be merged and tagged.
+	if moduleSetNames, err = modVersioning.ModuleSetOrder(moduleSetNames); err != nil {
+		return nil, err
+	}
+
+	st := &state{}
+	for _, name := range moduleSetNames {
+		modSet, exists := modVersioning.ModSetMap[name]
+		if !exists {
+			return nil, common.NewErrModuleSetNotFound(name, "")
+		}
+		st.ModuleSets = append(st.ModuleSets, &moduleSetState{
+			Name:    name,
+			Version: modSet.Version,
+			Step:    stepPending,
+		})
+	}
+
+	return st, nil
+}
+
+// checkVersionUnchanged verifies that the version of a module set in the versioning file is still
+// the one that the release was started with.
+func (rel releaser) checkVersionUnchanged(ms *moduleSetState) error {
+	modSet, err := common.GetModuleSet(ms.Name, rel.versioningFile, rel.repoRoot)
+	if err != nil {
+		return err
+	}
+	if modSet.Version != ms.Version {
+		return fmt.Errorf("version of module set %v changed from %v to %v since the release was started", ms.Name, ms.Version, modSet.Version)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package release
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+var (
+	testDataDir, _ = filepath.Abs("./test_data")
+)
+
+// TestMain performs setup for the tests and suppress printing logs.
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// fakeRunner records the steps run and fails the steps listed in fail.
+type fakeRunner struct {
+	calls []string
+	fail  map[string]bool
+}
+
+func (f *fakeRunner) prepare(moduleSetName string) (string, error) {
+	f.calls = append(f.calls, "prepare "+moduleSetName)
+	if f.fail["prepare"] {
+		return "", errors.New("prepare failed")
+	}
+	return "prerelease_" + moduleSetName, nil
+}
+
+func (f *fakeRunner) tag(moduleSetName, commitHash string) ([]string, error) {
+	f.calls = append(f.calls, "tag "+moduleSetName+" "+commitHash)
+	if f.fail["tag"] {
+		return nil, errors.New("tag failed")
+	}
+	return []string{moduleSetName + "/v1"}, nil
+}
+
+func (f *fakeRunner) push(tags []string, commitHash string) error {
+	f.calls = append(f.calls, "push "+tags[0]+" "+commitHash)
+	if f.fail["push"] {
+		return errors.New("push failed")
+	}
+	return nil
+}
+
+func newTestReleaser(t *testing.T, versionsFile string, runner stepRunner) releaser {
+	tmpRootDir := t.TempDir()
+	return releaser{
+		versioningFile: filepath.Join(testDataDir, "release", versionsFile),
+		repoRoot:       tmpRootDir,
+		stateFile:      filepath.Join(tmpRootDir, DefaultStateFileName),
+		runner:         runner,
+	}
+}
+
+func TestReleaseStopsForMerge(t *testing.T) {
+	runner := &fakeRunner{}
+	rel := newTestReleaser(t, "versions_valid.yaml", runner)
+
+	require.NoError(t, rel.run([]string{"mod-set-1", "mod-set-2"}, false, ""))
+	assert.Equal(t, []string{"prepare mod-set-1", "prepare mod-set-2"}, runner.calls)
+
+	st, err := loadState(rel.stateFile)
+	require.NoError(t, err)
+	assert.Equal(t, &state{
+		ModuleSets: []*moduleSetState{
+			{Name: "mod-set-1", Version: "v1.2.3", Step: stepPrepared, Branch: "prerelease_mod-set-1"},
+			{Name: "mod-set-2", Version: "v0.1.0", Step: stepPrepared, Branch: "prerelease_mod-set-2"},
+		},
+	}, st)
+
+	// Running again without a commit hash does not repeat the prerelease.
+	runner.calls = nil
+	require.NoError(t, rel.run(nil, false, ""))
+	assert.Empty(t, runner.calls)
+}
+
+func TestReleaseResumes(t *testing.T) {
+	runner := &fakeRunner{}
+	rel := newTestReleaser(t, "versions_valid.yaml", runner)
+
+	require.NoError(t, rel.run([]string{"mod-set-1", "mod-set-2"}, false, ""))
+
+	// Continue only mod-set-1, failing to push.
+	runner.calls = nil
+	runner.fail = map[string]bool{"push": true}
+	require.ErrorContains(t, rel.run([]string{"mod-set-1"}, false, "abc123"), "push failed")
+	assert.Equal(t, []string{"tag mod-set-1 abc123", "push mod-set-1/v1 abc123"}, runner.calls)
+
+	st, err := loadState(rel.stateFile)
+	require.NoError(t, err)
+	assert.Equal(t, &moduleSetState{
+		Name:    "mod-set-1",
+		Version: "v1.2.3",
+		Step:    stepTagged,
+		Branch:  "prerelease_mod-set-1",
+		Commit:  "abc123",
+		Tags:    []string{"mod-set-1/v1"},
+	}, st.moduleSet("mod-set-1"))
+	assert.Equal(t, stepPrepared, st.moduleSet("mod-set-2").Step)
+
+	// Resuming only retries the push for mod-set-1.
+	runner.calls = nil
+	runner.fail = nil
+	require.NoError(t, rel.run([]string{"mod-set-1"}, false, ""))
+	assert.Equal(t, []string{"push mod-set-1/v1 abc123"}, runner.calls)
+
+	runner.calls = nil
+	require.NoError(t, rel.run(nil, false, "def456"))
+	assert.Equal(t, []string{"tag mod-set-2 def456", "push mod-set-2/v1 def456"}, runner.calls)
+
+	// The state file is removed once every module set is released.
+	_, err = os.Stat(rel.stateFile)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReleaseErrors(t *testing.T) {
+	t.Run("unknown module set", func(t *testing.T) {
+		rel := newTestReleaser(t, "versions_valid.yaml", &fakeRunner{})
+		require.ErrorContains(t, rel.run([]string{"invalid"}, false, ""), "could not find module set invalid")
+
+		_, err := os.Stat(rel.stateFile)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("module set not in release", func(t *testing.T) {
+		rel := newTestReleaser(t, "versions_valid.yaml", &fakeRunner{})
+		require.NoError(t, rel.run([]string{"mod-set-1"}, false, ""))
+		require.ErrorContains(t, rel.run([]string{"mod-set-2"}, false, ""), "module set mod-set-2 is not part of the release in progress")
+	})
+
+	t.Run("prepare fails", func(t *testing.T) {
+		runner := &fakeRunner{fail: map[string]bool{"prepare": true}}
+		rel := newTestReleaser(t, "versions_valid.yaml", runner)
+		require.ErrorContains(t, rel.run([]string{"mod-set-1"}, false, ""), "prepare failed")
+
+		st, err := loadState(rel.stateFile)
+		require.NoError(t, err)
+		assert.Equal(t, stepPending, st.moduleSet("mod-set-1").Step)
+	})
+
+	t.Run("version changed", func(t *testing.T) {
+		runner := &fakeRunner{}
+		rel := newTestReleaser(t, "versions_valid.yaml", runner)
+		require.NoError(t, rel.run([]string{"mod-set-1"}, false, ""))
+
+		rel.versioningFile = filepath.Join(testDataDir, "release", "versions_changed.yaml")
+		require.ErrorContains(t, rel.run(nil, false, "abc123"), "version of module set mod-set-1 changed from v1.2.3 to v1.2.4")
+	})
+}
+
+func TestReleaseOrdersModuleSets(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versionsFile: []byte("module-sets:\n" +
+			"  api:\n    version: v1.0.0\n    modules:\n      - example.com/api\n" +
+			"  sdk:\n    version: v1.0.0\n    modules:\n      - example.com/sdk\n"),
+		filepath.Join(tmpRootDir, "api", "go.mod"): []byte("module example.com/api\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "sdk", "go.mod"): []byte("module example.com/sdk\n\ngo 1.19\n\nrequire example.com/api v1.0.0\n"),
+	}))
+
+	runner := &fakeRunner{}
+	rel := releaser{
+		versioningFile: versionsFile,
+		repoRoot:       tmpRootDir,
+		stateFile:      filepath.Join(tmpRootDir, DefaultStateFileName),
+		runner:         runner,
+	}
+
+	// sdk requires api, so api is released first.
+	require.NoError(t, rel.run([]string{"sdk", "api"}, false, ""))
+	assert.Equal(t, []string{"prepare api", "prepare sdk"}, runner.calls)
+
+	runner.calls = nil
+	require.NoError(t, rel.run(nil, false, "abc123"))
+	assert.Equal(t, []string{"tag api abc123", "tag sdk abc123", "push api/v1 abc123", "push sdk/v1 abc123"}, runner.calls)
+}
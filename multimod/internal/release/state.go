@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DefaultStateFileName is the name of the state file created in the .git directory of the
+// repository when no state file is specified.
+const DefaultStateFileName = "multimod_release_state.json"
+
+// step is the last release step completed for a module set.
+type step string
+
+const (
+	// stepPending means that nothing has been done for the module set yet.
+	stepPending step = "pending"
+	// stepPrepared means that the prerelease branch has been committed and is waiting to be
+	// reviewed and merged.
+	stepPrepared step = "prepared"
+	// stepTagged means that the merged commit has been tagged locally.
+	stepTagged step = "tagged"
+	// stepPushed means that the tags have been pushed and the module set is released.
+	stepPushed step = "pushed"
+)
+
+// state is the progress of a release, as persisted between invocations of the release command.
+type state struct {
+	ModuleSets []*moduleSetState `json:"module_sets"`
+}
+
+// moduleSetState is the progress of the release of a single module set.
+type moduleSetState struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Step    step     `json:"step"`
+	Branch  string   `json:"branch,omitempty"`
+	Commit  string   `json:"commit,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// loadState reads the state file at path. It returns nil without an error if the file does not
+// exist, meaning that no release is in progress.
+func loadState(path string) (*state, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read release state file: %w", err)
+	}
+
+	st := &state{}
+	if err = json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("could not parse release state file %v: %w", path, err)
+	}
+
+	return st, nil
+}
+
+// save writes the state to path, replacing any previous content.
+func (st *state) save(path string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode release state: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("could not write release state file: %w", err)
+	}
+
+	return nil
+}
+
+// moduleSet returns the state of the named module set, or nil if it is not part of the release.
+func (st *state) moduleSet(name string) *moduleSetState {
+	for _, ms := range st.ModuleSets {
+		if ms.Name == name {
+			return ms
+		}
+	}
+	return nil
+}
+
+// selectModuleSets returns the state of the named module sets, or of every module set in the
+// release if no names are given, in release order.
+func (st *state) selectModuleSets(names []string) ([]*moduleSetState, error) {
+	if len(names) == 0 {
+		return st.ModuleSets, nil
+	}
+
+	given := make(map[string]bool, len(names))
+	for _, name := range names {
+		if st.moduleSet(name) == nil {
+			return nil, fmt.Errorf("module set %v is not part of the release in progress", name)
+		}
+		given[name] = true
+	}
+
+	// Keep the release order, whatever the order of the names given.
+	var selected []*moduleSetState
+	for _, ms := range st.ModuleSets {
+		if given[ms.Name] {
+			selected = append(selected, ms)
+		}
+	}
+
+	return selected, nil
+}
+
+// done returns true once every module set in the release has been pushed.
+func (st *state) done() bool {
+	for _, ms := range st.ModuleSets {
+		if ms.Step != stepPushed {
+			return false
+		}
+	}
+	return true
+}
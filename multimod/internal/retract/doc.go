@@ -0,0 +1,6 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retract adds retract directives for bad releases to the go.mod files
+// of the modules in a module set.
+package retract
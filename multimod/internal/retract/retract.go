@@ -0,0 +1,256 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retract
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/bump"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/prerelease"
+)
+
+// errAlreadyRetracted is returned when every module of the module set already retracts the versions.
+var errAlreadyRetracted = errors.New("versions already retracted by every module of the module set")
+
+// Options configures how the retract directives are committed.
+type Options struct {
+	// Rationale is added as a comment above every retract directive.
+	Rationale string
+	// Patch bumps the module set to its next patch version and runs prerelease for it after
+	// committing the retract directives, so that they can be published.
+	Patch bool
+	// SkipModTidy, GoModTidyCompat and SkipChecks are passed to prerelease if Patch is set.
+	SkipModTidy     bool
+	GoModTidyCompat string
+	SkipChecks      common.SkippedChecks
+	// CommitToDifferentBranch commits the changes to a new branch instead of the current one.
+	CommitToDifferentBranch bool
+}
+
+// Run adds retract directives for versions, a single version such as v1.2.3 or a closed range
+// such as [v1.2.0, v1.2.3], to the go.mod file of every module in the module set and commits them.
+func Run(versioningFile, moduleSetName, versions string, opts Options) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+	log.Printf("Using repo with root at %s\n\n", repoRoot)
+
+	interval, err := parseVersionInterval(versions)
+	if err != nil {
+		return err
+	}
+
+	r, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
+	}
+
+	if err = common.VerifyWorkingTreeClean(r); err != nil {
+		return fmt.Errorf("VerifyWorkingTreeClean failed: %w", err)
+	}
+
+	rt := retracter{
+		versioningFile: versioningFile,
+		repoRoot:       repoRoot,
+		moduleSetName:  moduleSetName,
+		interval:       interval,
+	}
+	branch, _, err := rt.retract(r, opts)
+	if err != nil {
+		return fmt.Errorf("could not retract %v of module set %v: %w", formatInterval(interval), moduleSetName, err)
+	}
+
+	if branch == "" {
+		log.Println("=========\nRetract finished successfully. Review the new commit(s) on the current branch.")
+		return nil
+	}
+	log.Printf("=========\nRetract finished successfully. Now checkout branch %v and verify the changes.\n", branch)
+	return nil
+}
+
+// retracter holds the fields needed to retract versions of a module set.
+type retracter struct {
+	versioningFile string
+	repoRoot       string
+	moduleSetName  string
+	interval       modfile.VersionInterval
+}
+
+// retract adds the retract directives and commits them, followed by the prerelease commit for
+// the next patch version if opts.Patch is set. It returns the name of the new branch, if one was
+// created, and the hash of the last commit.
+func (rt retracter) retract(r *git.Repository, opts Options) (string, plumbing.Hash, error) {
+	msr, err := common.NewModuleSetRelease(rt.versioningFile, rt.moduleSetName, rt.repoRoot)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	edits, err := retractEdits(msr, rt.interval, opts.Rationale)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if len(edits) == 0 {
+		return "", plumbing.ZeroHash, errAlreadyRetracted
+	}
+
+	var patchVersion string
+	if opts.Patch {
+		if patchVersion, err = bump.NextVersion(msr.ModSetVersion(), bump.LevelPatch); err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("could not compute patch version: %w", err)
+		}
+		// The retract directives only take effect once published in a version that is not retracted.
+		if semver.Compare(patchVersion, rt.interval.High) <= 0 {
+			return "", plumbing.ZeroHash, fmt.Errorf("patch version %v is retracted itself", patchVersion)
+		}
+	}
+
+	var origRef *plumbing.Reference
+	var branch string
+	if opts.CommitToDifferentBranch {
+		if origRef, err = r.Head(); err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("could not get repo head: %w", err)
+		}
+		branch = branchName(rt.moduleSetName, rt.interval)
+		if _, err = common.CheckoutNewBranch(branch, r); err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+	}
+
+	for _, edit := range edits {
+		log.Printf("... Retracting %v in %v\n", formatInterval(rt.interval), edit.Path)
+	}
+	if err = common.WriteFileEdits(edits); err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	hash, err := common.CommitChanges(commitMessage(rt.moduleSetName, rt.interval), r, nil)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	if opts.Patch {
+		log.Printf("Preparing patch version %v of module set %v...\n", patchVersion, rt.moduleSetName)
+		if err = common.UpdateModuleSetVersions(rt.versioningFile, map[string]string{rt.moduleSetName: patchVersion}); err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("could not update versioning file: %w", err)
+		}
+		_, hash, err = prerelease.PrepareModuleSet(r, rt.versioningFile, rt.moduleSetName, rt.repoRoot, opts.GoModTidyCompat, opts.SkipModTidy, false, opts.SkipChecks)
+		if err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+	}
+
+	if origRef != nil {
+		if err = common.CheckoutExistingBranch(origRef.Name(), r); err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+	}
+
+	return branch, hash, nil
+}
+
+// retractEdits computes the edits adding a retract directive for interval, commented with
+// rationale, to the go.mod file of every module in the module set. go.mod files that already
+// retract exactly the interval are left unchanged.
+func retractEdits(msr common.ModuleSetRelease, interval modfile.VersionInterval, rationale string) ([]common.FileEdit, error) {
+	var edits []common.FileEdit
+	for _, modPath := range msr.ModSetPaths() {
+		modFilePath, ok := msr.ModuleVersioning.ModPathMap[modPath]
+		if !ok {
+			return nil, fmt.Errorf("could not find go.mod file of module %v", modPath)
+		}
+
+		data, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read mod file: %w", err)
+		}
+		modFile, err := modfile.Parse(string(modFilePath), data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse go.mod file at %v: %w", modFilePath, err)
+		}
+
+		if retracts(modFile, interval) {
+			continue
+		}
+		if err = modFile.AddRetract(interval, rationale); err != nil {
+			return nil, fmt.Errorf("could not add retract directive to %v: %w", modFilePath, err)
+		}
+		newData, err := modFile.Format()
+		if err != nil {
+			return nil, fmt.Errorf("could not format go.mod file at %v: %w", modFilePath, err)
+		}
+
+		edits = append(edits, common.FileEdit{Path: string(modFilePath), Old: data, New: newData})
+	}
+	return edits, nil
+}
+
+// retracts returns true if the go.mod file already has a retract directive for exactly interval.
+func retracts(modFile *modfile.File, interval modfile.VersionInterval) bool {
+	for _, r := range modFile.Retract {
+		if r.VersionInterval == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersionInterval parses a single version such as v1.2.3, or a closed range of versions such
+// as [v1.2.0, v1.2.3], using the syntax of retract directives.
+func parseVersionInterval(s string) (modfile.VersionInterval, error) {
+	s = strings.TrimSpace(s)
+	low, high := s, s
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		bounds := strings.Split(s[1:len(s)-1], ",")
+		if len(bounds) != 2 {
+			return modfile.VersionInterval{}, fmt.Errorf("invalid version range %q, expected [<low>, <high>]", s)
+		}
+		low, high = strings.TrimSpace(bounds[0]), strings.TrimSpace(bounds[1])
+	}
+
+	for _, v := range []string{low, high} {
+		if !semver.IsValid(v) || semver.Canonical(v) != v {
+			return modfile.VersionInterval{}, fmt.Errorf("invalid version %q, expected the form v1.2.3", v)
+		}
+	}
+	if semver.Compare(low, high) > 0 {
+		return modfile.VersionInterval{}, fmt.Errorf("invalid version range %q, %v is above %v", s, low, high)
+	}
+
+	return modfile.VersionInterval{Low: low, High: high}, nil
+}
+
+// formatInterval formats interval as it appears in a retract directive.
+func formatInterval(interval modfile.VersionInterval) string {
+	if interval.Low == interval.High {
+		return interval.Low
+	}
+	return fmt.Sprintf("[%v, %v]", interval.Low, interval.High)
+}
+
+// commitMessage returns the message of the commit adding the retract directives.
+func commitMessage(moduleSetName string, interval modfile.VersionInterval) string {
+	return fmt.Sprintf("Retract %v of %v", formatInterval(interval), moduleSetName)
+}
+
+// branchName returns the name of the branch created for the retract directives.
+func branchName(moduleSetName string, interval modfile.VersionInterval) string {
+	branchNameElements := []string{"retract", moduleSetName, interval.Low}
+	if interval.High != interval.Low {
+		branchNameElements = append(branchNameElements, interval.High)
+	}
+	return strings.Join(branchNameElements, "_")
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retract
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+// TestMain performs setup for the tests and suppress printing logs.
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestParseVersionInterval(t *testing.T) {
+	testCases := []struct {
+		versions string
+		expected modfile.VersionInterval
+		err      bool
+	}{
+		{versions: "v1.2.3", expected: modfile.VersionInterval{Low: "v1.2.3", High: "v1.2.3"}},
+		{versions: "[v1.2.0, v1.2.3]", expected: modfile.VersionInterval{Low: "v1.2.0", High: "v1.2.3"}},
+		{versions: " [v1.2.0,v1.2.3-rc.1] ", expected: modfile.VersionInterval{Low: "v1.2.0", High: "v1.2.3-rc.1"}},
+		{versions: "[v1.2.3, v1.2.3]", expected: modfile.VersionInterval{Low: "v1.2.3", High: "v1.2.3"}},
+		{versions: "", err: true},
+		{versions: "1.2.3", err: true},
+		{versions: "v1.2", err: true},
+		{versions: "[v1.2.0]", err: true},
+		{versions: "[v1.2.0, v1.2.1, v1.2.2]", err: true},
+		{versions: "[v1.2.3, v1.2.0]", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.versions, func(t *testing.T) {
+			actual, err := parseVersionInterval(tc.versions)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	single := modfile.VersionInterval{Low: "v1.2.3", High: "v1.2.3"}
+	assert.Equal(t, "Retract v1.2.3 of mod-set-1", commitMessage("mod-set-1", single))
+	assert.Equal(t, "retract_mod-set-1_v1.2.3", branchName("mod-set-1", single))
+
+	interval := modfile.VersionInterval{Low: "v1.2.0", High: "v1.2.3"}
+	assert.Equal(t, "Retract [v1.2.0, v1.2.3] of mod-set-1", commitMessage("mod-set-1", interval))
+	assert.Equal(t, "retract_mod-set-1_v1.2.0_v1.2.3", branchName("mod-set-1", interval))
+}
+
+// writeRetractRepo creates a repo with a module set of two modules, one of which already retracts
+// v1.0.1, and a module outside of the set that depends on them.
+func writeRetractRepo(t *testing.T) (*git.Repository, string, map[string][]byte) {
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		filepath.Join(tmpRootDir, "versions.yaml"): []byte("module-sets:\n  mod-set-1:\n    version: v1.0.1\n    modules:\n" +
+			"      - go.opentelemetry.io/test/test1\n      - go.opentelemetry.io/test/test2\n" +
+			"  mod-set-2:\n    version: v0.1.0\n    modules:\n      - go.opentelemetry.io/test/test3\n"),
+		filepath.Join(tmpRootDir, "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "test2", "go.mod"): []byte("module go.opentelemetry.io/test/test2\n\ngo 1.19\n\n" +
+			"// Published by mistake.\nretract v1.0.1\n"),
+		filepath.Join(tmpRootDir, "test3", "go.mod"): []byte("module go.opentelemetry.io/test/test3\n\ngo 1.19\n\n" +
+			"require go.opentelemetry.io/test/test1 v1.0.1\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(files))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.AddGlob("."))
+	_, err = worktree.Commit("add modules", &git.CommitOptions{Author: commontest.TestAuthor})
+	require.NoError(t, err)
+
+	// retract commits as the user configured in the repo.
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name, cfg.User.Email = commontest.TestAuthor.Name, commontest.TestAuthor.Email
+	require.NoError(t, repo.SetConfig(cfg))
+
+	return repo, tmpRootDir, files
+}
+
+func TestRetractEdits(t *testing.T) {
+	_, tmpRootDir, _ := writeRetractRepo(t)
+	msr, err := common.NewModuleSetRelease(filepath.Join(tmpRootDir, "versions.yaml"), "mod-set-1", tmpRootDir)
+	require.NoError(t, err)
+
+	edits, err := retractEdits(msr, modfile.VersionInterval{Low: "v1.0.1", High: "v1.0.1"}, "Published by mistake.")
+	require.NoError(t, err)
+	require.Len(t, edits, 1)
+	assert.Equal(t, filepath.Join(tmpRootDir, "test1", "go.mod"), edits[0].Path)
+	assert.Equal(t, "module go.opentelemetry.io/test/test1\n\ngo 1.19\n\n// Published by mistake.\nretract v1.0.1\n", string(edits[0].New))
+
+	edits, err = retractEdits(msr, modfile.VersionInterval{Low: "v1.0.0", High: "v1.0.1"}, "")
+	require.NoError(t, err)
+	require.Len(t, edits, 2)
+	assert.Equal(t, "module go.opentelemetry.io/test/test1\n\ngo 1.19\n\nretract [v1.0.0, v1.0.1]\n", string(edits[0].New))
+	assert.Equal(t, "module go.opentelemetry.io/test/test2\n\ngo 1.19\n\n"+
+		"retract (\n\t// Published by mistake.\n\tv1.0.1\n\t[v1.0.0, v1.0.1]\n)\n", string(edits[1].New))
+
+	// Versions must match the major version of the modules.
+	_, err = retractEdits(msr, modfile.VersionInterval{Low: "v2.0.0", High: "v2.0.0"}, "")
+	assert.Error(t, err)
+}
+
+func TestRetract(t *testing.T) {
+	repo, tmpRootDir, files := writeRetractRepo(t)
+	versioningFile := filepath.Join(tmpRootDir, "versions.yaml")
+	rt := retracter{
+		versioningFile: versioningFile,
+		repoRoot:       tmpRootDir,
+		moduleSetName:  "mod-set-1",
+		interval:       modfile.VersionInterval{Low: "v1.0.1", High: "v1.0.1"},
+	}
+
+	branch, hash, err := rt.retract(repo, Options{
+		Rationale:               "Published by mistake.",
+		Patch:                   true,
+		SkipModTidy:             true,
+		CommitToDifferentBranch: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "retract_mod-set-1_v1.0.1", branch)
+
+	branchRef, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	require.NoError(t, err)
+	assert.Equal(t, hash, branchRef.Hash())
+
+	// The branch has the retract commit followed by the prerelease commit of the patch version.
+	prereleaseCommit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	assert.Equal(t, "Prepare mod-set-1 for version v1.0.2", prereleaseCommit.Message)
+	retractCommit, err := prereleaseCommit.Parent(0)
+	require.NoError(t, err)
+	assert.Equal(t, "Retract v1.0.1 of mod-set-1", retractCommit.Message)
+
+	expected := map[string]string{
+		"test1/go.mod": "module go.opentelemetry.io/test/test1\n\ngo 1.19\n\n// Published by mistake.\nretract v1.0.1\n",
+		"test3/go.mod": "module go.opentelemetry.io/test/test3\n\ngo 1.19\n\nrequire go.opentelemetry.io/test/test1 v1.0.2\n",
+	}
+	for path, contents := range expected {
+		file, err := prereleaseCommit.File(path)
+		require.NoError(t, err)
+		actual, err := file.Contents()
+		require.NoError(t, err)
+		assert.Equal(t, contents, actual)
+	}
+	file, err := prereleaseCommit.File("versions.yaml")
+	require.NoError(t, err)
+	actual, err := file.Contents()
+	require.NoError(t, err)
+	assert.Contains(t, actual, "version: v1.0.2")
+
+	// The original branch is checked out again, unchanged.
+	for path, contents := range files {
+		actual, err := os.ReadFile(filepath.Clean(path))
+		require.NoError(t, err)
+		assert.Equal(t, contents, actual)
+	}
+
+	// The patch version must not be retracted itself.
+	rt.interval = modfile.VersionInterval{Low: "v1.0.0", High: "v1.0.2"}
+	_, _, err = rt.retract(repo, Options{Patch: true, SkipModTidy: true, CommitToDifferentBranch: true})
+	assert.ErrorContains(t, err, "patch version v1.0.2 is retracted itself")
+
+	// Once every module retracts the versions, there is nothing left to do.
+	rt.interval = modfile.VersionInterval{Low: "v1.0.1", High: "v1.0.1"}
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: branchRef.Name()}))
+	_, _, err = rt.retract(repo, Options{CommitToDifferentBranch: true})
+	assert.ErrorIs(t, err, errAlreadyRetracted)
+}
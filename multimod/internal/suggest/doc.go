@@ -0,0 +1,6 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package suggest proposes a module set for every module of a repo that is
+// not listed in the versioning file, and can insert them into it.
+package suggest
@@ -0,0 +1,261 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package suggest
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+)
+
+// Suggestion is the module set proposed for a module that is not listed in any module set.
+type Suggestion struct {
+	ModPath common.ModulePath
+	// ModuleSet is the name of the proposed module set, or empty if no module set fits the module.
+	ModuleSet string
+	// Reasons explains why the module set was proposed.
+	Reasons []string
+}
+
+func Run(versioningFile string, write bool) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+
+	modVersioning, err := common.NewModuleVersioning(versioningFile, repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not read module versioning: %w", err)
+	}
+
+	suggestions, err := Suggest(modVersioning)
+	if err != nil {
+		return fmt.Errorf("could not suggest module sets: %w", err)
+	}
+	if len(suggestions) == 0 {
+		log.Println("All modules are listed in a module set.")
+		return nil
+	}
+
+	Write(os.Stdout, suggestions)
+
+	if !write {
+		return nil
+	}
+	modSets := make(map[common.ModulePath]string)
+	for _, s := range suggestions {
+		if s.ModuleSet != "" {
+			modSets[s.ModPath] = s.ModuleSet
+		}
+	}
+	if len(modSets) == 0 {
+		return nil
+	}
+	if err = common.AddModulesToModuleSets(versioningFile, modSets); err != nil {
+		return fmt.Errorf("could not add modules to versioning file: %w", err)
+	}
+	log.Printf("Added %d module(s) to %v. Review the changes before committing them.\n", len(modSets), versioningFile)
+	return nil
+}
+
+// candidate is a module set considered for a module, with the evidence for it.
+type candidate struct {
+	name string
+	// prefix is the number of leading path elements the module shares with similar, the most
+	// similar module of the set.
+	prefix  int
+	similar common.ModulePath
+	// requires and requiredBy count the modules of the set the module requires and is required by.
+	requires   int
+	requiredBy int
+}
+
+// score ranks the candidates of a module: every shared path element and every dependency between
+// the module and the set counts as one.
+func (c candidate) score() int {
+	return c.prefix + c.requires + c.requiredBy
+}
+
+// hasEvidence returns true if the module has a dependency with the set, or shares more than the
+// rootLen path elements common to all the modules of the repo with a module of the set.
+func (c candidate) hasEvidence(rootLen int) bool {
+	return c.requires > 0 || c.requiredBy > 0 || c.prefix > rootLen
+}
+
+// Suggest returns a suggestion for every module found in the repo that is not listed in any
+// module set, sorted by module path. The proposed module set is the one whose modules share the
+// longest path prefix with the module and have the most dependencies with it, among the sets
+// whose version matches the major version of the module. No module set is proposed if none has
+// more in common with the module than the path prefix shared by all the modules of the repo, or
+// if several sets fit it equally well.
+func Suggest(modVersioning common.ModuleVersioning) ([]Suggestion, error) {
+	var unassigned []common.ModulePath
+	for modPath := range modVersioning.ModPathMap {
+		if _, ok := modVersioning.ModInfoMap[modPath]; !ok {
+			unassigned = append(unassigned, modPath)
+		}
+	}
+	sort.Slice(unassigned, func(i, j int) bool { return unassigned[i] < unassigned[j] })
+
+	var root common.ModulePath
+	rootLen := -1
+	requirements := make(map[common.ModulePath][]common.ModulePath, len(modVersioning.ModPathMap))
+	for modPath, modFilePath := range modVersioning.ModPathMap {
+		reqs, err := requiredModules(modFilePath)
+		if err != nil {
+			return nil, err
+		}
+		requirements[modPath] = reqs
+
+		if rootLen < 0 {
+			root, rootLen = modPath, len(strings.Split(string(modPath), "/"))
+		} else if n := sharedPrefix(root, modPath); n < rootLen {
+			rootLen = n
+		}
+	}
+
+	modSetNames := make([]string, 0, len(modVersioning.ModSetMap))
+	for name := range modVersioning.ModSetMap {
+		modSetNames = append(modSetNames, name)
+	}
+	sort.Strings(modSetNames)
+
+	suggestions := make([]Suggestion, 0, len(unassigned))
+	for _, modPath := range unassigned {
+		var candidates []candidate
+		for _, name := range modSetNames {
+			modSet := modVersioning.ModSetMap[name]
+			if !common.MajorVersionMatches(modPath, modSet.Version) {
+				continue
+			}
+
+			c := candidate{name: name}
+			for _, setModPath := range modSet.Modules {
+				if n := sharedPrefix(modPath, setModPath); n > c.prefix {
+					c.prefix, c.similar = n, setModPath
+				}
+				if contains(requirements[modPath], setModPath) {
+					c.requires++
+				}
+				if contains(requirements[setModPath], modPath) {
+					c.requiredBy++
+				}
+			}
+			candidates = append(candidates, c)
+		}
+
+		suggestions = append(suggestions, newSuggestion(modPath, candidates, rootLen))
+	}
+
+	return suggestions, nil
+}
+
+// newSuggestion returns the suggestion for the module among the candidates, the module sets whose
+// version matches its major version. rootLen is the number of path elements common to all the
+// modules of the repo.
+func newSuggestion(modPath common.ModulePath, candidates []candidate, rootLen int) Suggestion {
+	s := Suggestion{ModPath: modPath}
+	if len(candidates) == 0 {
+		s.Reasons = []string{"no module set has a version matching the major version of the module, add a new module set"}
+		return s
+	}
+
+	// best holds the candidates with the highest score, in the order of their names.
+	var best []candidate
+	for _, c := range candidates {
+		switch {
+		case !c.hasEvidence(rootLen):
+		case len(best) == 0 || c.score() > best[0].score():
+			best = []candidate{c}
+		case c.score() == best[0].score():
+			best = append(best, c)
+		}
+	}
+	if len(best) == 0 {
+		s.Reasons = []string{"no module set shares a dependency, or more than the path prefix common to all modules, with the module, add it to a module set"}
+		return s
+	}
+	if len(best) > 1 {
+		names := make([]string, len(best))
+		for i, c := range best {
+			names[i] = c.name
+		}
+		s.Reasons = []string{fmt.Sprintf("module sets %v fit the module equally well, add it to one of them", strings.Join(names, ", "))}
+		return s
+	}
+
+	c := best[0]
+	s.ModuleSet = c.name
+	if c.prefix > 0 {
+		prefix := strings.Join(strings.Split(string(modPath), "/")[:c.prefix], "/")
+		s.Reasons = append(s.Reasons, fmt.Sprintf("shares the path prefix %v with %v", prefix, c.similar))
+	}
+	if c.requires > 0 {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("requires %d module(s) of %v", c.requires, c.name))
+	}
+	if c.requiredBy > 0 {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("is required by %d module(s) of %v", c.requiredBy, c.name))
+	}
+	return s
+}
+
+// Write prints the suggestions, each followed by its reasons.
+func Write(w io.Writer, suggestions []Suggestion) {
+	for _, s := range suggestions {
+		modSet := s.ModuleSet
+		if modSet == "" {
+			modSet = "(none)"
+		}
+		fmt.Fprintf(w, "%v: %v\n", s.ModPath, modSet)
+		for _, reason := range s.Reasons {
+			fmt.Fprintf(w, "  %v\n", reason)
+		}
+	}
+}
+
+// requiredModules returns the module paths required by the go.mod file.
+func requiredModules(modFilePath common.ModuleFilePath) ([]common.ModulePath, error) {
+	data, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+	if err != nil {
+		return nil, fmt.Errorf("could not read mod file: %w", err)
+	}
+	modFile, err := modfile.Parse(string(modFilePath), data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse go.mod file at %v: %w", modFilePath, err)
+	}
+
+	reqs := make([]common.ModulePath, 0, len(modFile.Require))
+	for _, req := range modFile.Require {
+		reqs = append(reqs, common.ModulePath(req.Mod.Path))
+	}
+	return reqs, nil
+}
+
+// sharedPrefix returns the number of leading path elements that a and b have in common.
+func sharedPrefix(a, b common.ModulePath) int {
+	aElems, bElems := strings.Split(string(a), "/"), strings.Split(string(b), "/")
+	n := 0
+	for n < len(aElems) && n < len(bElems) && aElems[n] == bElems[n] {
+		n++
+	}
+	return n
+}
+
+func contains(modPaths []common.ModulePath, modPath common.ModulePath) bool {
+	for _, p := range modPaths {
+		if p == modPath {
+			return true
+		}
+	}
+	return false
+}
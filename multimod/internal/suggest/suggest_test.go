@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package suggest
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestSuggest(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	versionsFile := filepath.Join(tmpRootDir, "versions.yaml")
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versionsFile: []byte("module-sets:\n" +
+			"  stable:\n    version: v1.2.0\n    modules:\n" +
+			"      - example.com/a\n      - example.com/exporters/b\n" +
+			"  unstable:\n    version: v0.3.0\n    modules:\n" +
+			"      - example.com/exporters/c\n"),
+		filepath.Join(tmpRootDir, "a", "go.mod"):              []byte("module example.com/a\n\ngo 1.19\n\nrequire example.com/new/d v0.1.0\n"),
+		filepath.Join(tmpRootDir, "exporters", "b", "go.mod"): []byte("module example.com/exporters/b\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "exporters", "c", "go.mod"): []byte("module example.com/exporters/c\n\ngo 1.19\n"),
+		// Closer to the stable set by dependencies, though both sets share its path prefix.
+		filepath.Join(tmpRootDir, "exporters", "e", "go.mod"): []byte("module example.com/exporters/e\n\ngo 1.19\n\nrequire example.com/exporters/b v1.2.0\n"),
+		// Only required by the stable set.
+		filepath.Join(tmpRootDir, "new", "d", "go.mod"): []byte("module example.com/new/d\n\ngo 1.19\n"),
+		// No set has major version 2.
+		filepath.Join(tmpRootDir, "f", "go.mod"): []byte("module example.com/f/v2\n\ngo 1.19\n"),
+		// Shares only the path prefix of all modules with the sets.
+		filepath.Join(tmpRootDir, "g", "go.mod"): []byte("module example.com/g\n\ngo 1.19\n"),
+		// Shares the same path prefix with both sets.
+		filepath.Join(tmpRootDir, "exporters", "h", "go.mod"): []byte("module example.com/exporters/h\n\ngo 1.19\n"),
+	}))
+
+	modVersioning, err := common.NewModuleVersioning(versionsFile, tmpRootDir)
+	require.NoError(t, err)
+
+	suggestions, err := Suggest(modVersioning)
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{
+			ModPath:   "example.com/exporters/e",
+			ModuleSet: "stable",
+			Reasons:   []string{"shares the path prefix example.com/exporters with example.com/exporters/b", "requires 1 module(s) of stable"},
+		},
+		{
+			ModPath: "example.com/exporters/h",
+			Reasons: []string{"module sets stable, unstable fit the module equally well, add it to one of them"},
+		},
+		{
+			ModPath: "example.com/f/v2",
+			Reasons: []string{"no module set has a version matching the major version of the module, add a new module set"},
+		},
+		{
+			ModPath: "example.com/g",
+			Reasons: []string{"no module set shares a dependency, or more than the path prefix common to all modules, with the module, add it to a module set"},
+		},
+		{
+			ModPath:   "example.com/new/d",
+			ModuleSet: "stable",
+			Reasons:   []string{"shares the path prefix example.com with example.com/a", "is required by 1 module(s) of stable"},
+		},
+	}, suggestions)
+
+	var buf bytes.Buffer
+	Write(&buf, []Suggestion{suggestions[0], suggestions[2]})
+	assert.Equal(t, "example.com/exporters/e: stable\n"+
+		"  shares the path prefix example.com/exporters with example.com/exporters/b\n"+
+		"  requires 1 module(s) of stable\n"+
+		"example.com/f/v2: (none)\n"+
+		"  no module set has a version matching the major version of the module, add a new module set\n", buf.String())
+}
@@ -0,0 +1,328 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+)
+
+// Other describes the repo whose module set versions are synced.
+type Other struct {
+	// VersioningFile is the path of the versioning file of the other repo. If URL is set, the
+	// path is relative to the root of that repo.
+	VersioningFile string
+	// RepoRoot is the root of a local clone of the other repo. It is ignored if URL is set.
+	RepoRoot string
+	// URL is the git URL, or the path of a local and possibly bare repo, of the other repo. Only
+	// the commits read are cloned, in memory and without a checkout.
+	URL string
+	// Ref is the tag (or any other revision) of the repo at URL to read the versioning file at.
+	// If empty, each module set is read at its latest tag.
+	Ref string
+}
+
+// source provides the module sets of the other repo.
+type source interface {
+	moduleSetNames() ([]string, error)
+	moduleSet(modSetName string) (common.ModuleSet, error)
+}
+
+// newSource returns the source of the module sets described by other.
+func newSource(other Other) (source, error) {
+	if other.URL == "" {
+		return localSource{versioningFile: other.VersioningFile, repoRoot: other.RepoRoot}, nil
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{other.URL}})
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the refs of %v: %w", other.URL, err)
+	}
+
+	var tags []string
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+
+	// Only the commit of the ref, or of HEAD, is cloned. The tags of module sets are fetched
+	// when they are read.
+	opts := &git.CloneOptions{URL: other.URL, Depth: 1, SingleBranch: true, Tags: git.NoTags}
+	fullClone := false
+	if other.Ref != "" {
+		var ok bool
+		if opts.ReferenceName, ok = findRef(refs, other.Ref); !ok {
+			if !plumbing.IsHash(other.Ref) {
+				return nil, fmt.Errorf("could not resolve %v: no such ref in %v", other.Ref, other.URL)
+			}
+			// A commit that is not the tip of a ref cannot be cloned alone, so the whole history
+			// is cloned.
+			opts = &git.CloneOptions{URL: other.URL, Tags: git.NoTags}
+			fullClone = true
+		}
+	}
+
+	r, err := git.Clone(memory.NewStorage(), nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not clone %v: %w", other.URL, err)
+	}
+
+	var head plumbing.Hash
+	if fullClone {
+		head = plumbing.NewHash(other.Ref)
+	} else {
+		ref, err := r.Head()
+		if err != nil {
+			return nil, fmt.Errorf("could not get HEAD: %w", err)
+		}
+		head = ref.Hash()
+	}
+
+	return remoteSource{repo: r, versioningFile: other.VersioningFile, ref: other.Ref, head: head, tags: tags}, nil
+}
+
+// findRef returns the name of the ref among refs that name designates, as a full ref name, a tag
+// or a branch.
+func findRef(refs []*plumbing.Reference, name string) (plumbing.ReferenceName, bool) {
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(name),
+		plumbing.NewTagReferenceName(name),
+		plumbing.NewBranchReferenceName(name),
+	}
+	for _, candidate := range candidates {
+		for _, ref := range refs {
+			if ref.Name() == candidate {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// localSource reads the module sets of a local clone of the other repo.
+type localSource struct {
+	versioningFile string
+	repoRoot       string
+}
+
+func (s localSource) moduleSetNames() ([]string, error) {
+	return common.GetAllModuleSetNames(s.versioningFile, s.repoRoot)
+}
+
+func (s localSource) moduleSet(modSetName string) (common.ModuleSet, error) {
+	return common.GetModuleSet(modSetName, s.versioningFile, s.repoRoot)
+}
+
+// remoteSource reads the module sets of the other repo from its git history, at ref or at the
+// latest tag of each module set.
+type remoteSource struct {
+	repo           *git.Repository
+	versioningFile string
+	ref            string
+	// head is the commit of ref, or of the HEAD of the other repo if ref is empty.
+	head plumbing.Hash
+	// tags lists the names of the tags of the other repo, which are fetched when they are read.
+	tags []string
+}
+
+func (s remoteSource) moduleSetNames() ([]string, error) {
+	commit, err := s.commit(s.ref)
+	if err != nil {
+		return nil, err
+	}
+
+	modSets, err := s.moduleSets(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(modSets))
+	for name := range modSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s remoteSource) moduleSet(modSetName string) (common.ModuleSet, error) {
+	ref := s.ref
+	if ref == "" {
+		var err error
+		if ref, err = s.latestTag(modSetName); err != nil {
+			return common.ModuleSet{}, err
+		}
+	}
+
+	commit, err := s.commit(ref)
+	if err != nil {
+		return common.ModuleSet{}, err
+	}
+
+	modSets, err := s.moduleSets(commit)
+	if err != nil {
+		return common.ModuleSet{}, err
+	}
+
+	modSet, ok := modSets[modSetName]
+	if !ok {
+		return common.ModuleSet{}, fmt.Errorf("%w at %v", common.NewErrModuleSetNotFound(modSetName, s.versioningFile), ref)
+	}
+	return modSet, nil
+}
+
+// commit returns the commit of the tag ref, fetching it first, or the commit of s.ref (or of HEAD)
+// if ref is empty or s.ref.
+func (s remoteSource) commit(ref string) (*object.Commit, error) {
+	if ref == "" || ref == s.ref {
+		commit, err := s.repo.CommitObject(s.head)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %v: %w", s.ref, err)
+		}
+		return commit, nil
+	}
+
+	err := s.repo.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/tags/%[1]v:refs/tags/%[1]v", ref))},
+		Depth:    1,
+		Tags:     git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("could not fetch tag %v: %w", ref, err)
+	}
+
+	return common.TagCommit(s.repo, ref)
+}
+
+// moduleSets returns the module sets of the versioning file in commit.
+func (s remoteSource) moduleSets(commit *object.Commit) (common.ModuleSetMap, error) {
+	file, err := commit.File(s.versioningFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not find %v in commit %v: %w", s.versioningFile, commit.Hash, err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("could not read %v: %w", s.versioningFile, err)
+	}
+
+	modPathMap, err := treeModulePathMap(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ParseModuleSets([]byte(contents), s.versioningFile, modPathMap)
+}
+
+// latestTag returns the tag of the latest version of the module set modSetName, as listed in the
+// versioning file of HEAD. As with the go command, the latest version is the highest release
+// version, or the highest pre-release version if there are no releases.
+func (s remoteSource) latestTag(modSetName string) (string, error) {
+	head, err := s.commit("")
+	if err != nil {
+		return "", err
+	}
+
+	modSets, err := s.moduleSets(head)
+	if err != nil {
+		return "", err
+	}
+	modSet, ok := modSets[modSetName]
+	if !ok || len(modSet.Modules) == 0 {
+		return "", fmt.Errorf("%w at HEAD", common.NewErrModuleSetNotFound(modSetName, s.versioningFile))
+	}
+
+	modPathMap, err := treeModulePathMap(head)
+	if err != nil {
+		return "", err
+	}
+
+	// All modules of a set are tagged together, so the tags of the first module are enough.
+	modPath := modSet.Modules[0]
+	tagNames, err := common.ModulePathsToTagNames([]common.ModulePath{modPath}, modPathMap, "")
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve tag name of module %v: %w", modPath, err)
+	}
+	prefix := common.ModuleTagPrefix(modSet.TagPrefix, tagNames[0])
+
+	var latest, latestVersion string
+	for _, tag := range s.tags {
+		version, ok := common.TagVersion(tag, prefix)
+		if !ok || !common.MajorVersionMatches(modPath, version) {
+			continue
+		}
+		if latest == "" || laterVersion(version, latestVersion) {
+			latest, latestVersion = tag, version
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("could not find any tag of module set %v", modSetName)
+	}
+	return latest, nil
+}
+
+// laterVersion reports whether v is later than w, preferring release versions over pre-release
+// versions.
+func laterVersion(v, w string) bool {
+	vRelease, wRelease := semver.Prerelease(v) == "", semver.Prerelease(w) == ""
+	if vRelease != wRelease {
+		return vRelease
+	}
+	return semver.Compare(v, w) > 0
+}
+
+// treeModulePathMap returns the go.mod files in the tree of commit, keyed by module path. The file
+// paths are rooted at "/", so that an empty repo root can be passed to common functions.
+func treeModulePathMap(commit *object.Commit) (common.ModulePathMap, error) {
+	modPathMap := make(common.ModulePathMap)
+
+	files, err := commit.Files()
+	if err != nil {
+		return nil, err
+	}
+	err = files.ForEach(func(f *object.File) error {
+		if path.Base(f.Name) != "go.mod" {
+			return nil
+		}
+
+		contents, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("could not read %v: %w", f.Name, err)
+		}
+		if modPath := modfile.ModulePath([]byte(contents)); modPath != "" {
+			modPathMap[common.ModulePath(modPath)] = common.ModuleFilePath("/" + f.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list go.mod files: %w", err)
+	}
+
+	return modPathMap, nil
+}
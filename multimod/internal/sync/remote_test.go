@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+// newUpstreamRepo creates a repo with the module sets stable (the root module and module a) and
+// unstable (module b), tagged at several versions.
+func newUpstreamRepo(t *testing.T) string {
+	upstreamRoot := t.TempDir()
+	r, _, err := commontest.InitNewRepoWithCommit(upstreamRoot)
+	require.NoError(t, err)
+	worktree, err := r.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(upstreamRoot, "go.mod"):      []byte("module go.opentelemetry.io/other\n\ngo 1.19\n"),
+		filepath.Join(upstreamRoot, "a", "go.mod"): []byte("module go.opentelemetry.io/other/a\n\ngo 1.19\n"),
+		filepath.Join(upstreamRoot, "b", "go.mod"): []byte("module go.opentelemetry.io/other/b\n\ngo 1.19\n"),
+	}))
+
+	for _, release := range []struct {
+		stable, unstable string
+		tags             []string
+	}{
+		{stable: "v1.0.0", unstable: "v0.1.0-alpha", tags: []string{"v1.0.0", "a/v1.0.0", "b/v0.1.0-alpha"}},
+		{stable: "v1.1.0", unstable: "v0.2.0-alpha", tags: []string{"v1.1.0", "a/v1.1.0", "b/v0.2.0-alpha"}},
+		{stable: "v1.2.0-rc.1", unstable: "v0.2.0-alpha", tags: []string{"v1.2.0-rc.1", "a/v1.2.0-rc.1"}},
+		{stable: "v1.3.0", unstable: "v0.3.0-alpha"},
+	} {
+		require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+			filepath.Join(upstreamRoot, "versions.yaml"): []byte(fmt.Sprintf("module-sets:\n"+
+				"  stable:\n    version: %v\n    modules:\n"+
+				"      - go.opentelemetry.io/other\n      - go.opentelemetry.io/other/a\n"+
+				"  unstable:\n    version: %v\n    modules:\n"+
+				"      - go.opentelemetry.io/other/b\n", release.stable, release.unstable)),
+		}))
+		require.NoError(t, worktree.AddGlob("."))
+		hash, err := worktree.Commit("release "+release.stable, &git.CommitOptions{Author: commontest.TestAuthor})
+		require.NoError(t, err)
+
+		// Tag the root module with a lightweight tag, as some repos do.
+		for i, tag := range release.tags {
+			var opts *git.CreateTagOptions
+			if i > 0 {
+				opts = &git.CreateTagOptions{Message: tag, Tagger: commontest.TestAuthor}
+			}
+			_, err = r.CreateTag(tag, hash, opts)
+			require.NoError(t, err)
+		}
+	}
+
+	return upstreamRoot
+}
+
+func TestRemoteSource(t *testing.T) {
+	upstreamRoot := newUpstreamRepo(t)
+
+	bareRoot := t.TempDir()
+	_, err := git.PlainClone(bareRoot, true, &git.CloneOptions{URL: upstreamRoot})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		ref      string
+		expected map[string]string
+	}{
+		{
+			// The latest release is preferred over a later release candidate, and pre-releases
+			// are only used if there is no release.
+			name:     "latest tags",
+			expected: map[string]string{"stable": "v1.1.0", "unstable": "v0.2.0-alpha"},
+		},
+		{
+			name:     "tag",
+			ref:      "a/v1.0.0",
+			expected: map[string]string{"stable": "v1.0.0", "unstable": "v0.1.0-alpha"},
+		},
+		{
+			name:     "lightweight tag",
+			ref:      "v1.2.0-rc.1",
+			expected: map[string]string{"stable": "v1.2.0-rc.1", "unstable": "v0.2.0-alpha"},
+		},
+		{
+			name:     "branch",
+			ref:      "master",
+			expected: map[string]string{"stable": "v1.3.0", "unstable": "v0.3.0-alpha"},
+		},
+	}
+
+	for _, url := range []string{upstreamRoot, bareRoot} {
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				src, err := newSource(Other{VersioningFile: "versions.yaml", URL: url, Ref: tc.ref})
+				require.NoError(t, err)
+
+				names, err := src.moduleSetNames()
+				require.NoError(t, err)
+				assert.Equal(t, []string{"stable", "unstable"}, names)
+
+				for modSetName, version := range tc.expected {
+					modSet, err := src.moduleSet(modSetName)
+					require.NoError(t, err)
+					assert.Equal(t, version, modSet.Version)
+				}
+			})
+		}
+	}
+
+	src, err := newSource(Other{VersioningFile: "versions.yaml", URL: bareRoot})
+	require.NoError(t, err)
+	stable, err := src.moduleSet("stable")
+	require.NoError(t, err)
+	assert.Equal(t, []common.ModulePath{"go.opentelemetry.io/other", "go.opentelemetry.io/other/a"}, stable.Modules)
+
+	_, err = src.moduleSet("missing")
+	assert.ErrorContains(t, err, "could not find module set missing")
+
+	// Only the commit of HEAD is cloned, and the tags read are fetched alone.
+	shallow, err := src.(remoteSource).repo.Storer.Shallow()
+	require.NoError(t, err)
+	assert.Len(t, shallow, 2)
+
+	// A commit that is not the tip of a ref is read from a full clone.
+	upstream, err := git.PlainOpen(upstreamRoot)
+	require.NoError(t, err)
+	hash, err := upstream.ResolveRevision("a/v1.0.0")
+	require.NoError(t, err)
+	src, err = newSource(Other{VersioningFile: "versions.yaml", URL: bareRoot, Ref: hash.String()})
+	require.NoError(t, err)
+	stable, err = src.moduleSet("stable")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", stable.Version)
+
+	_, err = newSource(Other{VersioningFile: "versions.yaml", URL: bareRoot, Ref: "v9.9.9"})
+	assert.ErrorContains(t, err, "could not resolve v9.9.9: no such ref")
+
+	_, err = newSource(Other{VersioningFile: "versions.yaml", URL: filepath.Join(bareRoot, "missing")})
+	assert.ErrorContains(t, err, "could not list the refs of")
+}
+
+func TestLaterVersion(t *testing.T) {
+	assert.True(t, laterVersion("v1.1.0", "v1.0.0"))
+	assert.False(t, laterVersion("v1.0.0", "v1.1.0"))
+	assert.True(t, laterVersion("v1.0.0", "v1.2.0-rc.1"))
+	assert.False(t, laterVersion("v1.2.0-rc.1", "v1.0.0"))
+	assert.True(t, laterVersion("v1.2.0-rc.2", "v1.2.0-rc.1"))
+}
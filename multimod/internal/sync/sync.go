@@ -15,77 +15,107 @@
 package sync
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/mod/modfile"
 
 	"go.opentelemetry.io/build-tools/internal/repo"
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
 )
 
-func Run(myVersioningFile string, otherVersioningFile string, otherRepoRoot string, otherModuleSetNames []string, allModuleSets bool, skipModTidy bool) {
+func Run(myVersioningFile string, other Other, otherModuleSetNames []string, allModuleSets bool, skipModTidy bool, goModTidyCompat string, commitToDifferentBranch bool, dryRun bool) error {
 	myRepoRoot, err := repo.FindRoot()
 	if err != nil {
-		log.Fatalf("unable to find repo root: %v", err)
+		return fmt.Errorf("unable to find repo root: %w", err)
 	}
 	log.Printf("Using repo with root at %s\n\n", myRepoRoot)
 
+	src, err := newSource(other)
+	if err != nil {
+		return fmt.Errorf("could not read other repo: %w", err)
+	}
+
 	if allModuleSets {
-		otherModuleSetNames, err = common.GetAllModuleSetNames(otherVersioningFile, otherRepoRoot)
+		otherModuleSetNames, err = src.moduleSetNames()
 		if err != nil {
-			log.Fatalf("could not automatically get all module set names: %v", err)
+			return fmt.Errorf("could not automatically get all module set names: %w", err)
 		}
 	}
 
 	repo, err := git.PlainOpen(myRepoRoot)
 	if err != nil {
-		log.Fatalf("could not open repo at %v: %v", myRepoRoot, err)
+		return fmt.Errorf("could not open repo at %v: %w", myRepoRoot, err)
 	}
 
 	if err = common.VerifyWorkingTreeClean(repo); err != nil {
-		log.Fatalf("VerifyWorkingTreeClean failed: %v", err)
+		return fmt.Errorf("VerifyWorkingTreeClean failed: %w", err)
 	}
 
+	var branches []string
 	for _, moduleSetName := range otherModuleSetNames {
-		s, err := newSync(myVersioningFile, otherVersioningFile, moduleSetName, myRepoRoot)
+		otherModuleSet, err := src.moduleSet(moduleSetName)
 		if err != nil {
-			log.Fatalf("error creating new sync struct: %v", err)
+			return fmt.Errorf("could not get module set %v of other repo: %w", moduleSetName, err)
 		}
 
-		log.Printf("===== Module Set: %v =====\n", moduleSetName)
-
-		if err = s.updateAllGoModFiles(); err != nil {
-			log.Fatalf("updateAllGoModFiles failed: %v", err)
+		s, err := newSyncFromModuleSet(myVersioningFile, moduleSetName, otherModuleSet, myRepoRoot)
+		if err != nil {
+			return fmt.Errorf("error creating new sync struct: %w", err)
 		}
 
-		modSetUpToDate, err := checkModuleSetUpToDate(repo)
-		if err != nil {
-			log.Fatal(err)
+		log.Printf("===== Module Set: %v %v =====\n", moduleSetName, otherModuleSet.Version)
+
+		if dryRun {
+			err = s.dryRun(os.Stdout, myRepoRoot, skipModTidy, commitToDifferentBranch)
+		} else {
+			var branch string
+			branch, _, err = s.syncModuleSet(repo, skipModTidy, goModTidyCompat, commitToDifferentBranch)
+			if branch != "" {
+				branches = append(branches, branch)
+			}
 		}
-		if modSetUpToDate {
+		if errors.Is(err, errModuleSetUpToDate) {
 			log.Println("Module set already up to date. Skipping...")
 			continue
 		}
-		log.Println("Updating versions for module set...")
-
-		if skipModTidy {
-			log.Println("Skipping go mod tidy...")
-		} else {
-			if err := common.RunGoModTidy(s.MyModuleVersioning.ModPathMap); err != nil {
-				log.Printf("WARNING: failed to run 'go mod tidy': %v\n", err)
-			}
+		if err != nil {
+			return err
 		}
 	}
 
-	log.Println(`=========
-Prerelease finished successfully. Now run the following to verify the changes:
+	if dryRun {
+		log.Println("=========\nDry run finished. No changes were made.")
+		return nil
+	}
+
+	if len(branches) == 0 {
+		log.Println("=========\nSync finished. All module sets were already up to date.")
+		return nil
+	}
+
+	log.Printf(`=========
+Sync finished successfully. Now checkout the new branch(es) and verify the changes:
 
-git diff main
+%v
 
-Then, if necessary, commit changes and push to upstream/make a pull request.`)
+Then push the branch(es) to upstream and make a pull request.
+`, strings.Join(branches, "\n"))
+	return nil
 }
 
+// errModuleSetUpToDate is returned by syncModuleSet when no go.mod file requires an older version
+// of the module set.
+var errModuleSetUpToDate = errors.New("module set already up to date")
+
 // sync holds fields needed to update one module set at a time.
 type sync struct {
 	OtherModuleSetName string
@@ -93,12 +123,18 @@ type sync struct {
 	MyModuleVersioning common.ModuleVersioning
 }
 
-func newSync(myVersioningFilename, otherVersioningFilename, modSetToUpdate, myRepoRoot string) (sync, error) {
-	otherModuleSet, err := common.GetModuleSet(modSetToUpdate, otherVersioningFilename)
+func newSync(myVersioningFilename, otherVersioningFilename, modSetToUpdate, myRepoRoot, otherRepoRoot string) (sync, error) {
+	otherModuleSet, err := common.GetModuleSet(modSetToUpdate, otherVersioningFilename, otherRepoRoot)
 	if err != nil {
 		return sync{}, fmt.Errorf("error creating new sync struct: %w", err)
 	}
 
+	return newSyncFromModuleSet(myVersioningFilename, modSetToUpdate, otherModuleSet, myRepoRoot)
+}
+
+// newSyncFromModuleSet returns a sync updating the modules of my repo to the version of
+// otherModuleSet, however that module set was read.
+func newSyncFromModuleSet(myVersioningFilename, modSetToUpdate string, otherModuleSet common.ModuleSet, myRepoRoot string) (sync, error) {
 	myModVersioning, err := common.NewModuleVersioning(myVersioningFilename, myRepoRoot)
 	if err != nil {
 		return sync{}, fmt.Errorf("could not get my ModuleVersioning: %w", err)
@@ -111,36 +147,184 @@ func newSync(myVersioningFilename, otherVersioningFilename, modSetToUpdate, myRe
 	}, nil
 }
 
-// updateAllGoModFiles updates ALL modules' requires sections to use the newVersion number
-// for the modules given in newModPaths.
-func (s sync) updateAllGoModFiles() error {
+// modFilePaths returns the paths of the go.mod files of all modules in my repo.
+func (s sync) modFilePaths() []common.ModuleFilePath {
 	modFilePaths := make([]common.ModuleFilePath, 0, len(s.MyModuleVersioning.ModPathMap))
 
 	for _, filePath := range s.MyModuleVersioning.ModPathMap {
 		modFilePaths = append(modFilePaths, filePath)
 	}
 
-	if err := common.UpdateGoModFiles(
-		modFilePaths,
+	return modFilePaths
+}
+
+// updateAllGoModFiles updates ALL modules' requires sections to use the newVersion number
+// for the modules given in newModPaths. It returns the paths of the go.mod files that changed.
+func (s sync) updateAllGoModFiles() ([]common.ModuleFilePath, error) {
+	edits, err := common.UpdateGoModFiles(
+		s.modFilePaths(),
 		s.OtherModuleSet.Modules,
 		s.OtherModuleSet.Version,
-	); err != nil {
-		return fmt.Errorf("could not update all go mod files: %w", err)
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not update all go mod files: %w", err)
 	}
 
-	return nil
+	return common.ModuleFilePaths(edits), nil
 }
 
-func checkModuleSetUpToDate(repo *git.Repository) (bool, error) {
-	worktree, err := common.GetWorktree(repo)
+// syncModuleSet updates the go.mod files requiring an older version of the module set, runs
+// "go mod tidy -compat=<goModTidyCompat>" for them and commits the changes. If
+// commitToDifferentBranch is true, the commit is made on a new branch whose name is returned, and
+// the original branch is checked out again afterwards.
+func (s sync) syncModuleSet(repo *git.Repository, skipModTidy bool, goModTidyCompat string, commitToDifferentBranch bool) (string, plumbing.Hash, error) {
+	changed, current, err := s.requiringModules()
 	if err != nil {
-		return false, err
+		return "", plumbing.ZeroHash, err
 	}
+	logModules(changed, current, s.OtherModuleSet.Version)
+	if len(changed) == 0 {
+		return "", plumbing.ZeroHash, errModuleSetUpToDate
+	}
+	log.Println("Updating versions for module set...")
 
-	status, err := worktree.Status()
+	changedModFiles, err := s.updateAllGoModFiles()
 	if err != nil {
-		return false, fmt.Errorf("could not get worktree status: %w", err)
+		return "", plumbing.ZeroHash, fmt.Errorf("updateAllGoModFiles failed: %w", err)
 	}
 
-	return status.IsClean(), nil
+	if skipModTidy {
+		log.Println("Skipping go mod tidy...")
+	} else {
+		if err := common.RunGoModTidy(changedModFiles, common.GoModTidyOptions{Compat: goModTidyCompat}); err != nil {
+			log.Printf("WARNING: failed to run 'go mod tidy': %v\n", err)
+		}
+	}
+
+	message := s.commitMessage()
+	var branch string
+	var hash plumbing.Hash
+	if commitToDifferentBranch {
+		branch = s.branchName()
+		hash, err = common.CommitChangesToNewBranch(branch, message, repo, nil)
+	} else {
+		hash, err = common.CommitChanges(message, repo, nil)
+	}
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("could not commit changes: %w", err)
+	}
+	log.Printf("Commit successful. Hash of commit: %s\n", hash)
+
+	return branch, hash, nil
+}
+
+// requiringModules returns the modules of my repo that require or replace modules of the other
+// module set, split into the modules whose go.mod file needs to change and the modules that
+// already use the version of the set. Both are sorted.
+func (s sync) requiringModules() (changed, current []common.ModulePath, err error) {
+	edits, err := common.GoModFileEdits(s.modFilePaths(), s.OtherModuleSet.Modules, s.OtherModuleSet.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not compute go.mod edits: %w", err)
+	}
+	edited := make(map[string]bool, len(edits))
+	for _, edit := range edits {
+		edited[edit.Path] = true
+	}
+
+	inSet := make(map[string]bool, len(s.OtherModuleSet.Modules))
+	for _, modPath := range s.OtherModuleSet.Modules {
+		inSet[string(modPath)] = true
+	}
+
+	for modPath, modFilePath := range s.MyModuleVersioning.ModPathMap {
+		if edited[string(modFilePath)] {
+			changed = append(changed, modPath)
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read go.mod file: %w", err)
+		}
+		modFile, err := modfile.ParseLax(string(modFilePath), data, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse go.mod file %v: %w", modFilePath, err)
+		}
+		if requiresAny(modFile, inSet) {
+			current = append(current, modPath)
+		}
+	}
+
+	sort.Slice(changed, func(i, j int) bool { return changed[i] < changed[j] })
+	sort.Slice(current, func(i, j int) bool { return current[i] < current[j] })
+	return changed, current, nil
+}
+
+// requiresAny reports whether modFile requires or replaces any of the modules in modPaths.
+func requiresAny(modFile *modfile.File, modPaths map[string]bool) bool {
+	for _, req := range modFile.Require {
+		if modPaths[req.Mod.Path] {
+			return true
+		}
+	}
+	for _, rep := range modFile.Replace {
+		if modPaths[rep.New.Path] && rep.New.Version != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// logModules logs the modules whose go.mod file changes and the modules already at version.
+func logModules(changed, current []common.ModulePath, version string) {
+	for _, modPath := range changed {
+		log.Printf("Updating %v to %v\n", modPath, version)
+	}
+	for _, modPath := range current {
+		log.Printf("Already current: %v\n", modPath)
+	}
+}
+
+// commitMessage returns the message of the commit made for the synced module set.
+func (s sync) commitMessage() string {
+	return fmt.Sprintf("Sync %v to version %v", s.OtherModuleSetName, s.OtherModuleSet.Version)
+}
+
+// branchName returns the name of the branch created for the synced module set.
+func (s sync) branchName() string {
+	return strings.Join([]string{"sync", s.OtherModuleSetName, s.OtherModuleSet.Version}, "_")
+}
+
+// dryRun writes the go.mod edits and the commit that syncModuleSet would make to w, without
+// modifying the repository.
+func (s sync) dryRun(w io.Writer, myRepoRoot string, skipModTidy, commitToDifferentBranch bool) error {
+	changed, current, err := s.requiringModules()
+	if err != nil {
+		return err
+	}
+	logModules(changed, current, s.OtherModuleSet.Version)
+	if len(changed) == 0 {
+		return errModuleSetUpToDate
+	}
+
+	edits, err := common.GoModFileEdits(s.modFilePaths(), s.OtherModuleSet.Modules, s.OtherModuleSet.Version)
+	if err != nil {
+		return fmt.Errorf("could not compute go.mod edits: %w", err)
+	}
+
+	if err = common.PrintFileEdits(w, common.FileEdits(edits), myRepoRoot); err != nil {
+		return err
+	}
+
+	if !skipModTidy {
+		fmt.Fprintf(w, "Would run 'go mod tidy' in the %d modules whose go.mod file changed.\n", len(edits))
+	}
+
+	if commitToDifferentBranch {
+		fmt.Fprintf(w, "Would commit %q to new branch %v.\n", s.commitMessage(), s.branchName())
+	} else {
+		fmt.Fprintf(w, "Would commit %q to the current branch.\n", s.commitMessage())
+	}
+
+	return nil
 }
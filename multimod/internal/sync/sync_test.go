@@ -15,12 +15,15 @@
 package sync
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -166,6 +169,7 @@ func TestNewSync(t *testing.T) {
 				otherVersioningFilename,
 				tc.modSetName,
 				tmpRootDir,
+				filepath.Dir(otherVersioningFilename),
 			)
 			require.NoError(t, err)
 
@@ -336,10 +340,11 @@ func TestUpdateAllGoModFiles(t *testing.T) {
 				otherVersioningFilename,
 				tc.modSetName,
 				tmpRootDir,
+				filepath.Dir(otherVersioningFilename),
 			)
 			require.NoError(t, err)
 
-			err = s.updateAllGoModFiles()
+			_, err = s.updateAllGoModFiles()
 			require.NoError(t, err)
 
 			for modFilePathSuffix, expectedByteOutput := range tc.expectedOutputModFiles {
@@ -351,3 +356,153 @@ func TestUpdateAllGoModFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestDryRun(t *testing.T) {
+	versionsYamlDir := filepath.Join(testDataDir, "dry_run")
+	myVersioningFilename := filepath.Join(versionsYamlDir, "versions_valid.yaml")
+	otherVersioningFilename := filepath.Join(versionsYamlDir, "other_versions_valid.yaml")
+
+	tmpRootDir := t.TempDir()
+	modFiles := map[string][]byte{
+		filepath.Join(tmpRootDir, "my", "test", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/sync/test3\n\n" +
+			"go 1.16\n\n" +
+			"require go.opentelemetry.io/other/test2 v0.1.0-old\n"),
+		filepath.Join(tmpRootDir, "my", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/sync/testroot/v2\n\n" +
+			"go 1.16\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(modFiles), "could not create go mod file tree")
+
+	testCases := []struct {
+		name                    string
+		modSetName              string
+		skipModTidy             bool
+		commitToDifferentBranch bool
+		expected                string
+		expectedErr             error
+	}{
+		{
+			name:                    "different branch",
+			modSetName:              "other-mod-set-2",
+			commitToDifferentBranch: true,
+			expected: "--- a/my/test/go.mod\n" +
+				"+++ b/my/test/go.mod\n" +
+				"@@ -2,4 +2,4 @@\n" +
+				" \n" +
+				" go 1.16\n" +
+				" \n" +
+				"-require go.opentelemetry.io/other/test2 v0.1.0-old\n" +
+				"+require go.opentelemetry.io/other/test2 v0.1.0\n" +
+				"Would run 'go mod tidy' in the 1 modules whose go.mod file changed.\n" +
+				"Would commit \"Sync other-mod-set-2 to version v0.1.0\" to new branch sync_other-mod-set-2_v0.1.0.\n",
+		},
+		{
+			name:        "skip go mod tidy",
+			modSetName:  "other-mod-set-2",
+			skipModTidy: true,
+			expected: "--- a/my/test/go.mod\n" +
+				"+++ b/my/test/go.mod\n" +
+				"@@ -2,4 +2,4 @@\n" +
+				" \n" +
+				" go 1.16\n" +
+				" \n" +
+				"-require go.opentelemetry.io/other/test2 v0.1.0-old\n" +
+				"+require go.opentelemetry.io/other/test2 v0.1.0\n" +
+				"Would commit \"Sync other-mod-set-2 to version v0.1.0\" to the current branch.\n",
+		},
+		{
+			// No module requires the module set, so nothing would change.
+			name:        "up to date",
+			modSetName:  "other-mod-set-3",
+			expected:    "",
+			expectedErr: errModuleSetUpToDate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := newSync(myVersioningFilename, otherVersioningFilename, tc.modSetName, tmpRootDir, filepath.Dir(otherVersioningFilename))
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = s.dryRun(&buf, tmpRootDir, tc.skipModTidy, tc.commitToDifferentBranch)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, buf.String())
+
+			// Nothing is written.
+			for modFilePath, expected := range modFiles {
+				actual, err := os.ReadFile(filepath.Clean(modFilePath))
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual)
+			}
+		})
+	}
+}
+
+func TestSyncModuleSet(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	myVersioningFilename := filepath.Join(tmpRootDir, "versions.yaml")
+	modFiles := map[string][]byte{
+		myVersioningFilename: []byte("module-sets:\n  my-mod-set:\n    version: v0.1.0\n    modules:\n" +
+			"      - go.opentelemetry.io/my/old\n      - go.opentelemetry.io/my/current\n      - go.opentelemetry.io/my/unrelated\n"),
+		filepath.Join(tmpRootDir, "old", "go.mod"): []byte("module go.opentelemetry.io/my/old\n\ngo 1.19\n\n" +
+			"require go.opentelemetry.io/other/test1 v1.0.0\n"),
+		filepath.Join(tmpRootDir, "current", "go.mod"): []byte("module go.opentelemetry.io/my/current\n\ngo 1.19\n\n" +
+			"require go.opentelemetry.io/other/test1 v1.1.0\n"),
+		filepath.Join(tmpRootDir, "unrelated", "go.mod"): []byte("module go.opentelemetry.io/my/unrelated\n\ngo 1.19\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(modFiles))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.AddGlob("."))
+	_, err = worktree.Commit("add modules", &git.CommitOptions{Author: commontest.TestAuthor})
+	require.NoError(t, err)
+
+	// syncModuleSet commits as the user configured in the repo.
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name, cfg.User.Email = commontest.TestAuthor.Name, commontest.TestAuthor.Email
+	require.NoError(t, repo.SetConfig(cfg))
+
+	s, err := newSyncFromModuleSet(myVersioningFilename, "other-mod-set", common.ModuleSet{
+		Version: "v1.1.0",
+		Modules: []common.ModulePath{"go.opentelemetry.io/other/test1"},
+	}, tmpRootDir)
+	require.NoError(t, err)
+
+	changed, current, err := s.requiringModules()
+	require.NoError(t, err)
+	assert.Equal(t, []common.ModulePath{"go.opentelemetry.io/my/old"}, changed)
+	assert.Equal(t, []common.ModulePath{"go.opentelemetry.io/my/current"}, current)
+
+	branch, hash, err := s.syncModuleSet(repo, true, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, "sync_other-mod-set_v1.1.0", branch)
+
+	// The changes are committed to the new branch only.
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	assert.Equal(t, "Sync other-mod-set to version v1.1.0", commit.Message)
+	branchRef, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	require.NoError(t, err)
+	assert.Equal(t, hash, branchRef.Hash())
+
+	file, err := commit.File("old/go.mod")
+	require.NoError(t, err)
+	contents, err := file.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "module go.opentelemetry.io/my/old\n\ngo 1.19\n\nrequire go.opentelemetry.io/other/test1 v1.1.0\n", contents)
+
+	for modFilePath, expected := range modFiles {
+		actual, err := os.ReadFile(filepath.Clean(modFilePath))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	// Once the branch is checked out, the module set is up to date.
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: branchRef.Name()}))
+	_, _, err = s.syncModuleSet(repo, true, "", true)
+	assert.ErrorIs(t, err, errModuleSetUpToDate)
+}
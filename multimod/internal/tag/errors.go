@@ -37,3 +37,31 @@ type errCouldNotGetCommitHash struct {
 func (e *errCouldNotGetCommitHash) Error() string {
 	return fmt.Sprintf("error getting full hash: %v", e.err)
 }
+
+type errRemoteTagsMismatch struct {
+	remote      string
+	commitHash  plumbing.Hash
+	missing     []string
+	notOnCommit []string
+}
+
+func (e *errRemoteTagsMismatch) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tags on remote %v do not match commit %s:", e.remote, e.commitHash)
+	for _, tag := range e.missing {
+		fmt.Fprintf(&b, "\n%v: missing", tag)
+	}
+	for _, tag := range e.notOnCommit {
+		fmt.Fprintf(&b, "\n%v: not on commit", tag)
+	}
+	return b.String()
+}
+
+type errSigningNotSetUp struct {
+	mode   SigningMode
+	reason string
+}
+
+func (e *errSigningNotSetUp) Error() string {
+	return fmt.Sprintf("tags cannot be signed with signing mode %v: %v", e.mode, e.reason)
+}
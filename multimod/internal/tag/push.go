@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// PushTags pushes exactly the given tags from the repository at repoRoot to the named remote.
+func PushTags(repoRoot, remote string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	args := []string{"push", remote}
+	for _, tag := range tags {
+		args = append(args, fmt.Sprintf("refs/tags/%[1]v:refs/tags/%[1]v", tag))
+	}
+
+	log.Printf("Pushing %d tags to %v\n", len(tags), remote)
+
+	// #nosec G204
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git push to %v failed [%v]: %w", remote, string(out), err)
+	}
+
+	return nil
+}
+
+// VerifyRemoteTags checks that every given tag exists on the named remote and points at commitHash.
+func VerifyRemoteTags(repoRoot, remote string, tags []string, commitHash plumbing.Hash) error {
+	remoteTags, err := lsRemoteTags(repoRoot, remote)
+	if err != nil {
+		return err
+	}
+
+	var missing, notOnCommit []string
+	for _, tag := range tags {
+		hash, ok := remoteTags[tag]
+		switch {
+		case !ok:
+			missing = append(missing, tag)
+		case hash != commitHash:
+			notOnCommit = append(notOnCommit, tag)
+		}
+	}
+
+	if len(missing) > 0 || len(notOnCommit) > 0 {
+		return &errRemoteTagsMismatch{
+			remote:      remote,
+			commitHash:  commitHash,
+			missing:     missing,
+			notOnCommit: notOnCommit,
+		}
+	}
+
+	return nil
+}
+
+// lsRemoteTags returns the commit each tag on the named remote points at, keyed by tag name.
+func lsRemoteTags(repoRoot, remote string) (map[string]plumbing.Hash, error) {
+	// #nosec G204
+	cmd := exec.Command("git", "ls-remote", "--tags", remote)
+	cmd.Dir = repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote of %v failed [%v]: %w", remote, stderr.String(), err)
+	}
+
+	tags := make(map[string]plumbing.Hash)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		hash, ref := plumbing.NewHash(fields[0]), strings.TrimPrefix(fields[1], "refs/tags/")
+
+		// Annotated tags are listed twice: once with the hash of the tag object, and once
+		// peeled (suffixed with ^{}) with the hash of the commit. The peeled entry wins.
+		if strings.HasSuffix(ref, "^{}") {
+			tags[strings.TrimSuffix(ref, "^{}")] = hash
+		} else if _, ok := tags[ref]; !ok {
+			tags[ref] = hash
+		}
+	}
+
+	return tags, scanner.Err()
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+// newRepoWithRemote creates a repo with a local bare repo added to it as the remote "origin".
+// It returns the repo, its root, the hash of its initial commit and the path of the bare repo.
+func newRepoWithRemote(t *testing.T) (*git.Repository, string, plumbing.Hash, string) {
+	tmpRootDir := t.TempDir()
+	repo, initialHash, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	remoteDir := t.TempDir()
+	_, err = git.PlainInit(remoteDir, true)
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	require.NoError(t, err)
+
+	return repo, tmpRootDir, initialHash, remoteDir
+}
+
+func TestPushTags(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "push_tags", "versions_valid.yaml")
+
+	repo, tmpRootDir, _, remoteDir := newRepoWithRemote(t)
+	fullHash, err := common.CommitChangesToNewBranch("test_commit", "commit used in a test", repo, commontest.TestAuthor)
+	require.NoError(t, err)
+
+	modFiles := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test2\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "go.mod"):          []byte("module go.opentelemetry.io/test3\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "go.mod"):                  []byte("module go.opentelemetry.io/testroot/v2\n\ngo 1.16\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(modFiles), "could not create go mod file tree")
+
+	// An unrelated tag must not be pushed.
+	_, err = repo.CreateTag("unrelated", fullHash, &git.CreateTagOptions{Message: "unrelated", Tagger: commontest.TestAuthor})
+	require.NoError(t, err)
+
+	tagger, err := newTagger(versioningFilename, "mod-set-2", tmpRootDir, fullHash.String(), false)
+	require.NoError(t, err)
+	require.NoError(t, tagger.tagAllModules(commontest.TestAuthor))
+
+	require.NoError(t, tagger.pushTags("origin"))
+
+	remoteRepo, err := git.PlainOpen(remoteDir)
+	require.NoError(t, err)
+	for _, tagName := range []string{"test/test2/v0.1.0", "test/v0.1.0"} {
+		_, err = remoteRepo.Tag(tagName)
+		assert.NoErrorf(t, err, "tag %v was not pushed", tagName)
+	}
+	_, err = remoteRepo.Tag("unrelated")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestVerifyRemoteTags(t *testing.T) {
+	repo, tmpRootDir, initialHash, _ := newRepoWithRemote(t)
+	fullHash, err := common.CommitChangesToNewBranch("test_commit", "commit used in a test", repo, commontest.TestAuthor)
+	require.NoError(t, err)
+	require.NotEqual(t, initialHash, fullHash)
+
+	_, err = repo.CreateTag("annotated", fullHash, &git.CreateTagOptions{Message: "annotated", Tagger: commontest.TestAuthor})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("lightweight", fullHash, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("on-initial", initialHash, nil)
+	require.NoError(t, err)
+	require.NoError(t, PushTags(tmpRootDir, "origin", []string{"annotated", "lightweight", "on-initial"}))
+
+	testCases := []struct {
+		name          string
+		tags          []string
+		commitHash    plumbing.Hash
+		expectedError string
+	}{
+		{
+			name:       "annotated and lightweight tags",
+			tags:       []string{"annotated", "lightweight"},
+			commitHash: fullHash,
+		},
+		{
+			name:          "tag on other commit",
+			tags:          []string{"annotated", "on-initial"},
+			commitHash:    fullHash,
+			expectedError: "on-initial: not on commit",
+		},
+		{
+			name:          "tag not pushed",
+			tags:          []string{"lightweight", "not-pushed"},
+			commitHash:    fullHash,
+			expectedError: "not-pushed: missing",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyRemoteTags(tmpRootDir, "origin", tc.tags, tc.commitHash)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
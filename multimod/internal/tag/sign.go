@@ -0,0 +1,255 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// SigningMode selects how the tags of a module set are signed.
+type SigningMode string
+
+const (
+	// SignDefault signs tags with "git tag -s", in the format set by the gpg.format configuration.
+	SignDefault SigningMode = "default"
+	// SignNone creates unsigned annotated tags.
+	SignNone SigningMode = "none"
+	// SignGPG signs tags with a GPG key (gpg.format=openpgp).
+	SignGPG SigningMode = "gpg"
+	// SignSSH signs tags with an SSH key (gpg.format=ssh).
+	SignSSH SigningMode = "ssh"
+)
+
+// DefaultSigningMode is the signing mode used unless another one is specified. Tags have always been
+// signed with "git tag -s" following the Git configuration, so it remains the default.
+const DefaultSigningMode = SignDefault
+
+// minSSHSigningGitVersion is the first Git version supporting SSH signatures.
+var minSSHSigningGitVersion = [2]int{2, 34}
+
+// ParseSigningMode parses a signing mode given as "default", "none", "gpg" or "ssh".
+func ParseSigningMode(s string) (SigningMode, error) {
+	switch mode := SigningMode(s); mode {
+	case SignDefault, SignNone, SignGPG, SignSSH:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid signing mode %q, expected one of %v, %v, %v or %v", s, SignDefault, SignNone, SignGPG, SignSSH)
+	}
+}
+
+// tagArgs returns the arguments of the git command creating the annotated tag tagName on commitHash,
+// signed according to the mode. The gpg and ssh modes set the signature format explicitly so that it
+// does not depend on the gpg.format configuration, which the default mode follows.
+func (m SigningMode) tagArgs(tagName, message string, commitHash plumbing.Hash) []string {
+	var args []string
+	switch m {
+	case SignDefault:
+		args = []string{"tag", "-s"}
+	case SignGPG:
+		args = []string{"-c", "gpg.format=openpgp", "tag", "-s"}
+	case SignSSH:
+		args = []string{"-c", "gpg.format=ssh", "tag", "-s"}
+	default:
+		args = []string{"-c", "tag.gpgSign=false", "tag", "-a"}
+	}
+	return append(args, "-m", message, tagName, commitHash.String())
+}
+
+// CheckSigningSetup returns an error if the repository at repoRoot is not set up to create tags
+// signed according to mode, so that the problem is found before any tag is created.
+func CheckSigningSetup(repoRoot string, mode SigningMode) error {
+	// Annotated tags always need a tagger identity.
+	if out, err := runGit(repoRoot, "var", "GIT_COMMITTER_IDENT"); err != nil {
+		return &errSigningNotSetUp{mode: mode, reason: fmt.Sprintf("no Git identity is configured [%v]", strings.TrimSpace(out))}
+	}
+
+	switch mode {
+	case SignNone:
+		return nil
+	case SignDefault:
+		return checkConfiguredSetup(repoRoot)
+	case SignGPG:
+		return checkGPGSetup(repoRoot)
+	case SignSSH:
+		return checkSSHSetup(repoRoot)
+	default:
+		return fmt.Errorf("invalid signing mode %q", mode)
+	}
+}
+
+// checkConfiguredSetup checks the setup of the signature format set by gpg.format. Formats other
+// than openpgp and ssh, such as x509, are left for Git to check.
+func checkConfiguredSetup(repoRoot string) error {
+	format, err := gitConfig(repoRoot, "gpg.format")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "", "openpgp":
+		return checkGPGSetup(repoRoot)
+	case "ssh":
+		return checkSSHSetup(repoRoot)
+	default:
+		return nil
+	}
+}
+
+// checkGPGSetup checks that the GPG program is installed and has a secret key to sign with.
+func checkGPGSetup(repoRoot string) error {
+	program, err := signingProgram(repoRoot, "gpg", "gpg.openpgp.program", "gpg.program")
+	if err != nil {
+		return &errSigningNotSetUp{mode: SignGPG, reason: err.Error()}
+	}
+
+	signingKey, err := gitConfig(repoRoot, "user.signingkey")
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--batch", "--list-secret-keys"}
+	if signingKey != "" {
+		args = append(args, signingKey)
+	}
+	// #nosec G204
+	cmd := exec.Command(program, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil || len(bytes.TrimSpace(out)) == 0 {
+		reason := "no GPG secret key is available"
+		if signingKey != "" {
+			reason = fmt.Sprintf("GPG secret key %q (user.signingkey) is not available", signingKey)
+		}
+		return &errSigningNotSetUp{mode: SignGPG, reason: reason}
+	}
+
+	return nil
+}
+
+// checkSSHSetup checks that Git supports SSH signatures, that ssh-keygen is installed and that a
+// signing key is configured.
+func checkSSHSetup(repoRoot string) error {
+	version, err := gitVersion(repoRoot)
+	if err != nil {
+		return err
+	}
+	if version[0] < minSSHSigningGitVersion[0] ||
+		version[0] == minSSHSigningGitVersion[0] && version[1] < minSSHSigningGitVersion[1] {
+		return &errSigningNotSetUp{mode: SignSSH, reason: fmt.Sprintf(
+			"Git %d.%d does not support SSH signatures, %d.%d or later is required",
+			version[0], version[1], minSSHSigningGitVersion[0], minSSHSigningGitVersion[1])}
+	}
+
+	if _, err = signingProgram(repoRoot, "ssh-keygen", "gpg.ssh.program"); err != nil {
+		return &errSigningNotSetUp{mode: SignSSH, reason: err.Error()}
+	}
+
+	signingKey, err := gitConfig(repoRoot, "user.signingkey")
+	if err != nil {
+		return err
+	}
+	switch {
+	case signingKey == "":
+		return &errSigningNotSetUp{mode: SignSSH, reason: "user.signingkey is not set to an SSH key"}
+	case strings.HasPrefix(signingKey, "key::") || strings.HasPrefix(signingKey, "ssh-"):
+		// The key is given literally.
+		return nil
+	}
+
+	if strings.HasPrefix(signingKey, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		signingKey = filepath.Join(home, signingKey[2:])
+	}
+	if _, err = os.Stat(signingKey); err != nil {
+		return &errSigningNotSetUp{mode: SignSSH, reason: fmt.Sprintf("SSH key file of user.signingkey is not readable: %v", err)}
+	}
+
+	return nil
+}
+
+// signingProgram returns the path of the signing program configured by the first of configKeys
+// that is set, or of defaultProgram.
+func signingProgram(repoRoot, defaultProgram string, configKeys ...string) (string, error) {
+	program := defaultProgram
+	for _, key := range configKeys {
+		value, err := gitConfig(repoRoot, key)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			program = value
+			break
+		}
+	}
+
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", fmt.Errorf("signing program %v is not installed: %w", program, err)
+	}
+	return path, nil
+}
+
+// gitConfig returns the value of a Git configuration key in the repository at repoRoot, or an empty
+// string if it is not set.
+func gitConfig(repoRoot, key string) (string, error) {
+	out, err := runGit(repoRoot, "config", "--get", key)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read Git config %v [%v]: %w", key, strings.TrimSpace(out), err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+var gitVersionRegex = regexp.MustCompile(`git version (\d+)\.(\d+)`)
+
+// gitVersion returns the major and minor version of the installed Git.
+func gitVersion(repoRoot string) ([2]int, error) {
+	out, err := runGit(repoRoot, "version")
+	if err != nil {
+		return [2]int{}, fmt.Errorf("could not get Git version [%v]: %w", strings.TrimSpace(out), err)
+	}
+
+	m := gitVersionRegex.FindStringSubmatch(out)
+	if m == nil {
+		return [2]int{}, fmt.Errorf("could not parse Git version %q", strings.TrimSpace(out))
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return [2]int{major, minor}, nil
+}
+
+// runGit runs git with args in repoRoot and returns its combined output.
+func runGit(repoRoot string, args ...string) (string, error) {
+	// #nosec G204
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoRoot
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestParseSigningMode(t *testing.T) {
+	for _, s := range []string{"default", "none", "gpg", "ssh"} {
+		mode, err := ParseSigningMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, SigningMode(s), mode)
+	}
+
+	_, err := ParseSigningMode("x509")
+	assert.ErrorContains(t, err, `invalid signing mode "x509"`)
+}
+
+func TestTagArgs(t *testing.T) {
+	hash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+
+	assert.Equal(t, []string{"tag", "-s", "-m", "msg", "v1.0.0", hash.String()},
+		SignDefault.tagArgs("v1.0.0", "msg", hash))
+	assert.Equal(t, []string{"-c", "tag.gpgSign=false", "tag", "-a", "-m", "msg", "v1.0.0", hash.String()},
+		SignNone.tagArgs("v1.0.0", "msg", hash))
+	assert.Equal(t, []string{"-c", "gpg.format=openpgp", "tag", "-s", "-m", "msg", "v1.0.0", hash.String()},
+		SignGPG.tagArgs("v1.0.0", "msg", hash))
+	assert.Equal(t, []string{"-c", "gpg.format=ssh", "tag", "-s", "-m", "msg", "v1.0.0", hash.String()},
+		SignSSH.tagArgs("v1.0.0", "msg", hash))
+}
+
+// newSigningTestRepo creates a repo isolated from the global and system Git configuration, with a
+// committer identity configured.
+func newSigningTestRepo(t *testing.T) (*git.Repository, string) {
+	globalConfig := filepath.Join(t.TempDir(), "gitconfig")
+	require.NoError(t, os.WriteFile(globalConfig, nil, 0600))
+	t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	setGitConfig(t, tmpRootDir, "user.name", commontest.TestAuthor.Name)
+	setGitConfig(t, tmpRootDir, "user.email", commontest.TestAuthor.Email)
+	return repo, tmpRootDir
+}
+
+func setGitConfig(t *testing.T, repoRoot, key, value string) {
+	out, err := runGit(repoRoot, "config", key, value)
+	require.NoError(t, err, out)
+}
+
+// setSSHSigningKey configures a new SSH key as the signing key of the repo.
+func setSSHSigningKey(t *testing.T, repoRoot string) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	// #nosec G204
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyFile).CombinedOutput()
+	require.NoError(t, err, string(out))
+	setGitConfig(t, repoRoot, "user.signingkey", keyFile)
+}
+
+func TestCheckSigningSetup(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		_, repoRoot := newSigningTestRepo(t)
+		assert.NoError(t, CheckSigningSetup(repoRoot, SignNone))
+	})
+
+	t.Run("gpg program not installed", func(t *testing.T) {
+		_, repoRoot := newSigningTestRepo(t)
+		setGitConfig(t, repoRoot, "gpg.program", "multimod-no-such-gpg")
+
+		err := CheckSigningSetup(repoRoot, SignGPG)
+		assert.ErrorContains(t, err, "tags cannot be signed with signing mode gpg: signing program multimod-no-such-gpg is not installed")
+	})
+
+	t.Run("gpg key not available", func(t *testing.T) {
+		if _, err := exec.LookPath("gpg"); err != nil {
+			t.Skip("gpg is not installed")
+		}
+		_, repoRoot := newSigningTestRepo(t)
+		t.Setenv("GNUPGHOME", t.TempDir())
+		setGitConfig(t, repoRoot, "user.signingkey", "0123456789ABCDEF")
+
+		err := CheckSigningSetup(repoRoot, SignGPG)
+		assert.ErrorContains(t, err, `GPG secret key "0123456789ABCDEF" (user.signingkey) is not available`)
+	})
+
+	t.Run("ssh key not set", func(t *testing.T) {
+		_, repoRoot := newSigningTestRepo(t)
+
+		err := CheckSigningSetup(repoRoot, SignSSH)
+		assert.ErrorContains(t, err, "user.signingkey is not set to an SSH key")
+	})
+
+	t.Run("ssh key file missing", func(t *testing.T) {
+		if _, err := exec.LookPath("ssh-keygen"); err != nil {
+			t.Skip("ssh-keygen is not installed")
+		}
+		_, repoRoot := newSigningTestRepo(t)
+		setGitConfig(t, repoRoot, "user.signingkey", filepath.Join(t.TempDir(), "missing"))
+
+		err := CheckSigningSetup(repoRoot, SignSSH)
+		assert.ErrorContains(t, err, "SSH key file of user.signingkey is not readable")
+	})
+
+	t.Run("default follows gpg.format", func(t *testing.T) {
+		_, repoRoot := newSigningTestRepo(t)
+		setGitConfig(t, repoRoot, "gpg.format", "ssh")
+
+		err := CheckSigningSetup(repoRoot, SignDefault)
+		assert.ErrorContains(t, err, "user.signingkey is not set to an SSH key")
+	})
+
+	t.Run("ssh literal key", func(t *testing.T) {
+		if _, err := exec.LookPath("ssh-keygen"); err != nil {
+			t.Skip("ssh-keygen is not installed")
+		}
+		_, repoRoot := newSigningTestRepo(t)
+		setGitConfig(t, repoRoot, "user.signingkey", "key::ssh-ed25519 AAAA")
+
+		assert.NoError(t, CheckSigningSetup(repoRoot, SignSSH))
+	})
+}
+
+func TestTagAllModulesSigningModes(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "tag_all_modules", "versions_valid.yaml")
+
+	testCases := []struct {
+		name string
+		mode SigningMode
+		// setup configures the repo for the signing mode.
+		setup     func(t *testing.T, repoRoot string)
+		signature string
+	}{
+		{
+			name: "unsigned",
+			mode: SignNone,
+			// A configured tag.gpgSign must not make the tags signed.
+			setup: func(t *testing.T, repoRoot string) {
+				setGitConfig(t, repoRoot, "tag.gpgSign", "true")
+			},
+		},
+		{
+			name:      "ssh",
+			mode:      SignSSH,
+			setup:     setSSHSigningKey,
+			signature: "-----BEGIN SSH SIGNATURE-----",
+		},
+		{
+			name: "default with ssh format",
+			mode: SignDefault,
+			// The format configured in Git is used.
+			setup: func(t *testing.T, repoRoot string) {
+				setSSHSigningKey(t, repoRoot)
+				setGitConfig(t, repoRoot, "gpg.format", "ssh")
+			},
+			signature: "-----BEGIN SSH SIGNATURE-----",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, repoRoot := newSigningTestRepo(t)
+			tc.setup(t, repoRoot)
+
+			fullHash, err := common.CommitChangesToNewBranch("test_commit", "commit used in a test", repo, commontest.TestAuthor)
+			require.NoError(t, err)
+
+			require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+				filepath.Join(repoRoot, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.16\n"),
+				filepath.Join(repoRoot, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test2\n\ngo 1.16\n"),
+				filepath.Join(repoRoot, "test", "go.mod"):          []byte("module go.opentelemetry.io/test3\n\ngo 1.16\n"),
+				filepath.Join(repoRoot, "go.mod"):                  []byte("module go.opentelemetry.io/testroot/v2\n\ngo 1.16\n"),
+			}))
+
+			require.NoError(t, CheckSigningSetup(repoRoot, tc.mode))
+
+			tagger, err := newTagger(versioningFilename, "mod-set-2", repoRoot, fullHash.String(), false)
+			require.NoError(t, err)
+			tagger.SigningMode = tc.mode
+			require.NoError(t, tagger.tagAllModules(nil))
+
+			for _, tagName := range tagger.ModuleFullTagNames() {
+				ref, err := repo.Tag(tagName)
+				require.NoError(t, err)
+				tagObj, err := repo.TagObject(ref.Hash())
+				require.NoError(t, err, "tag %v is not annotated", tagName)
+				assert.Equal(t, fullHash, tagObj.Target)
+
+				if tc.signature == "" {
+					assert.Empty(t, tagObj.PGPSignature)
+				} else {
+					assert.True(t, strings.HasPrefix(tagObj.PGPSignature, tc.signature), tagObj.PGPSignature)
+				}
+			}
+		})
+	}
+}
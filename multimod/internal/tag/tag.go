@@ -17,7 +17,9 @@ package tag
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"os/exec"
 
 	"github.com/go-git/go-git/v5"
@@ -29,16 +31,33 @@ import (
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
 )
 
-func Run(versioningFile, moduleSetName, commitHash string, deleteModuleSetTags bool, shouldPrintTags bool) {
+func Run(versioningFile, moduleSetName, commitHash string, deleteModuleSetTags bool, shouldPrintTags bool, push bool, remote string, signingMode string, dryRun bool, skipChecks []string, goModTidyCompat string) error {
 
 	repoRoot, err := repo.FindRoot()
 	if err != nil {
-		log.Fatalf("unable to change to repo root: %v", err)
+		return fmt.Errorf("unable to change to repo root: %w", err)
+	}
+
+	mode, err := ParseSigningMode(signingMode)
+	if err != nil {
+		return err
+	}
+
+	skipped, err := common.ParseSkippedChecks(skipChecks)
+	if err != nil {
+		return err
 	}
 
 	t, err := newTagger(versioningFile, moduleSetName, repoRoot, commitHash, deleteModuleSetTags)
 	if err != nil {
-		log.Fatalf("Error creating new tagger struct: %v", err)
+		return fmt.Errorf("error creating new tagger struct: %w", err)
+	}
+	t.SigningMode = mode
+
+	if dryRun {
+		t.dryRun(os.Stdout, deleteModuleSetTags, push, remote)
+		log.Println("Dry run finished. No changes were made.")
+		return nil
 	}
 
 	// if delete-module-set-tags is specified, then delete all newModTagNames
@@ -46,13 +65,27 @@ func Run(versioningFile, moduleSetName, commitHash string, deleteModuleSetTags b
 	// modules in the given set.
 	if deleteModuleSetTags {
 		if err := t.deleteModuleSetTags(); err != nil {
-			log.Fatalf("Error deleting tags for the specified module set: %v", err)
+			return fmt.Errorf("error deleting tags for the specified module set: %w", err)
 		}
 
 		fmt.Println("Successfully deleted module tags")
 	} else {
+		if err := t.runPreTagChecks(common.PreTagCheckOptions{Skip: skipped, GoModTidyCompat: goModTidyCompat}); err != nil {
+			return fmt.Errorf("module set %v is not ready to be tagged: %w", moduleSetName, err)
+		}
+
+		if err := CheckSigningSetup(repoRoot, t.SigningMode); err != nil {
+			return fmt.Errorf("signing setup check failed: %w", err)
+		}
+
 		if err := t.tagAllModules(nil); err != nil {
-			log.Fatalf("unable to tag modules: %v", err)
+			return fmt.Errorf("unable to tag modules: %w", err)
+		}
+
+		if push {
+			if err := t.pushTags(remote); err != nil {
+				return fmt.Errorf("unable to push tags: %w", err)
+			}
 		}
 	}
 
@@ -61,12 +94,40 @@ func Run(versioningFile, moduleSetName, commitHash string, deleteModuleSetTags b
 			fmt.Println(tag)
 		}
 	}
+	return nil
+}
+
+// TagModuleSet runs the pre-tag checks that are not skipped on the committed modules of the module
+// set, then creates Git tags on the given commit for every module in the set, signed according to
+// signingMode, and returns the names of the created tags.
+func TagModuleSet(versioningFile, moduleSetName, repoRoot, commitHash string, signingMode SigningMode, checks common.PreTagCheckOptions) ([]string, error) {
+	t, err := newTagger(versioningFile, moduleSetName, repoRoot, commitHash, false)
+	if err != nil {
+		return nil, fmt.Errorf("error creating new tagger struct: %w", err)
+	}
+	t.SigningMode = signingMode
+
+	if err = t.runPreTagChecks(checks); err != nil {
+		return nil, fmt.Errorf("module set %v is not ready to be tagged: %w", moduleSetName, err)
+	}
+
+	if err = CheckSigningSetup(repoRoot, signingMode); err != nil {
+		return nil, err
+	}
+
+	if err = t.tagAllModules(nil); err != nil {
+		return nil, fmt.Errorf("unable to tag modules: %w", err)
+	}
+
+	return t.ModuleFullTagNames(), nil
 }
 
 type tagger struct {
 	common.ModuleSetRelease
 	CommitHash plumbing.Hash
 	Repo       *git.Repository
+	// SigningMode selects how tags created with the git command are signed.
+	SigningMode SigningMode
 }
 
 func newTagger(versioningFilename, modSetToUpdate, repoRoot, hash string, deleteModuleSetTags bool) (tagger, error) {
@@ -101,31 +162,45 @@ func newTagger(versioningFilename, modSetToUpdate, repoRoot, hash string, delete
 		ModuleSetRelease: modRelease,
 		CommitHash:       fullCommitHash,
 		Repo:             repo,
+		SigningMode:      DefaultSigningMode,
 	}, nil
 }
 
+// runPreTagChecks runs the pre-tag checks that are not skipped on the modules of the set, as
+// committed in the commit to tag.
+func (t tagger) runPreTagChecks(opts common.PreTagCheckOptions) error {
+	if opts.Skip.All() {
+		log.Println("Skipping pre-tag checks...")
+		return nil
+	}
+
+	worktree, err := t.Repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("could not get worktree: %w", err)
+	}
+	commit, err := t.Repo.CommitObject(t.CommitHash)
+	if err != nil {
+		return fmt.Errorf("could not get commit %v: %w", t.CommitHash, err)
+	}
+	files, err := common.CommitFiles(worktree.Filesystem.Root(), commit)
+	if err != nil {
+		return err
+	}
+
+	return t.ModuleSetRelease.RunPreTagChecks(files, opts)
+}
+
 func verifyTagsOnCommit(modFullTagNames []string, repo *git.Repository, targetCommitHash plumbing.Hash) error {
 	var tagsNotOnCommit []string
 
 	for _, tagName := range modFullTagNames {
-		tagRef, tagRefErr := repo.Tag(tagName)
-
-		if tagRefErr != nil {
-			if errors.Is(tagRefErr, git.ErrTagNotFound) {
+		tagCommit, tagCommitErr := common.TagCommit(repo, tagName)
+		if tagCommitErr != nil {
+			if errors.Is(tagCommitErr, git.ErrTagNotFound) {
 				tagsNotOnCommit = append(tagsNotOnCommit, tagName)
 				continue
 			}
-			return fmt.Errorf("unable to fetch git tag ref for %v: %w", tagName, tagRefErr)
-		}
-
-		tagObj, tagObjErr := repo.TagObject(tagRef.Hash())
-		if tagObjErr != nil {
-			return fmt.Errorf("unable to get tag object: %w", tagObjErr)
-		}
-
-		tagCommit, tagCommitErr := tagObj.Commit()
-		if tagCommitErr != nil {
-			return fmt.Errorf("could not get tag object commit: %w", tagCommitErr)
+			return fmt.Errorf("could not get commit of git tag %v: %w", tagName, tagCommitErr)
 		}
 
 		if targetCommitHash != tagCommit.Hash {
@@ -175,35 +250,77 @@ func deleteTags(modFullTags []string, repo *git.Repository) error {
 	return nil
 }
 
+// dryRun writes the tags that would be created or deleted, and pushed, to w.
+func (t tagger) dryRun(w io.Writer, deleteModuleSetTags, push bool, remote string) {
+	if deleteModuleSetTags {
+		for _, tag := range t.ModuleSetRelease.ModuleFullTagNames() {
+			fmt.Fprintf(w, "Would delete tag %v\n", tag)
+		}
+		return
+	}
+
+	for _, tag := range t.ModuleSetRelease.ModuleFullTagNames() {
+		fmt.Fprintf(w, "Would create tag %v on commit %s\n", tag, t.CommitHash)
+	}
+	fmt.Fprintf(w, "Would sign the tags above with signing mode %v\n", t.SigningMode)
+	if push {
+		fmt.Fprintf(w, "Would push the tags above to %v\n", remote)
+	}
+}
+
+// pushTags pushes the tags of the module set to the named remote and verifies that each of them
+// exists there and points at the tagged commit.
+func (t tagger) pushTags(remote string) error {
+	worktree, err := t.Repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("could not get worktree: %w", err)
+	}
+	repoRoot := worktree.Filesystem.Root()
+
+	modFullTags := t.ModuleSetRelease.ModuleFullTagNames()
+	if err = PushTags(repoRoot, remote, modFullTags); err != nil {
+		return err
+	}
+
+	if err = VerifyRemoteTags(repoRoot, remote, modFullTags, t.CommitHash); err != nil {
+		return fmt.Errorf("pushed tags could not be verified: %w", err)
+	}
+
+	log.Printf("Verified %d tags on %v\n", len(modFullTags), remote)
+	return nil
+}
+
 func (t tagger) tagAllModules(customTagger *object.Signature) error {
 	modFullTags := t.ModuleSetRelease.ModuleFullTagNames()
 
-	tagMessage := fmt.Sprintf("Module set %v, Version %v",
-		t.ModuleSetRelease.ModSetName, t.ModuleSetRelease.ModSetVersion())
+	tagMessage, err := t.ModuleSetRelease.TagMessage()
+	if err != nil {
+		return err
+	}
 
 	var addedFullTags []string
 
 	log.Printf("Tagging commit %s:\n", t.CommitHash)
 
+	var repoRoot string
+	if customTagger == nil {
+		worktree, err := t.Repo.Worktree()
+		if err != nil {
+			return fmt.Errorf("could not get worktree: %w", err)
+		}
+		repoRoot = worktree.Filesystem.Root()
+	}
+
 	for _, newFullTag := range modFullTags {
 		log.Printf("%v\n", newFullTag)
 
 		var err error
 		if customTagger == nil {
-			cfg, err2 := t.Repo.Config()
-			if err2 != nil {
-				err = fmt.Errorf("unable to load repo config: %w", err2)
-				if cfg == nil || cfg.Core.Worktree == "" {
-					// This is not recoverable, do not panic below.
-					return err
-				}
-			}
 			// TODO: figure out how to use go-git and gpg-agent without needing to have decrypted private key material
 			// #nosec G204
-			cmd := exec.Command("git", "tag", "-a", "-s", "-m", tagMessage, newFullTag, t.CommitHash.String())
-			cmd.Dir = cfg.Core.Worktree
-			output, err2 := cmd.CombinedOutput()
-			if err2 != nil {
+			cmd := exec.Command("git", t.SigningMode.tagArgs(newFullTag, tagMessage, t.CommitHash)...)
+			cmd.Dir = repoRoot
+			if output, err2 := cmd.CombinedOutput(); err2 != nil {
 				err = fmt.Errorf("unable to create tag: %q: %w", string(output), err2)
 			}
 		} else {
@@ -15,6 +15,7 @@
 package tag
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"os"
@@ -257,6 +258,26 @@ func TestVerifyTagsOnCommit(t *testing.T) {
 	}
 }
 
+func TestVerifyTagsOnCommitLightweight(t *testing.T) {
+	repo, commitHash, err := commontest.InitNewMemoryRepoWithCommit()
+	require.NoError(t, err)
+
+	_, err = repo.CreateTag("test/lightweight/v1.0.0", commitHash, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("test/annotated/v1.0.0", commitHash, &git.CreateTagOptions{
+		Message: "test tag message",
+		Tagger:  commontest.TestAuthor,
+	})
+	require.NoError(t, err)
+
+	tagNames := []string{"test/lightweight/v1.0.0", "test/annotated/v1.0.0"}
+	assert.NoError(t, verifyTagsOnCommit(tagNames, repo, commitHash))
+
+	otherHash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	assert.Equal(t, &errGitTagsNotOnCommit{commitHash: otherHash, tagNames: tagNames},
+		verifyTagsOnCommit(tagNames, repo, otherHash))
+}
+
 func TestGetFullCommitHash(t *testing.T) {
 	tmpRootDir := t.TempDir()
 	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
@@ -595,3 +616,117 @@ func TestTagAllModules(t *testing.T) {
 	}
 
 }
+
+func TestTaggerDryRun(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "tag_all_modules", "versions_valid.yaml")
+
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	fullHash, err := common.CommitChangesToNewBranch("test_commit", "commit used in a test", repo, commontest.TestAuthor)
+	require.NoError(t, err)
+
+	modFiles := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test2\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "go.mod"):          []byte("module go.opentelemetry.io/test3\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "go.mod"):                  []byte("module go.opentelemetry.io/testroot/v2\n\ngo 1.16\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(modFiles), "could not create go mod file tree")
+
+	tagger, err := newTagger(versioningFilename, "mod-set-2", tmpRootDir, fullHash.String(), false)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name                string
+		deleteModuleSetTags bool
+		push                bool
+		expected            string
+	}{
+		{
+			name: "create",
+			expected: "Would create tag test/test2/v0.1.0 on commit " + fullHash.String() + "\n" +
+				"Would create tag test/v0.1.0 on commit " + fullHash.String() + "\n" +
+				"Would sign the tags above with signing mode default\n",
+		},
+		{
+			name: "create and push",
+			push: true,
+			expected: "Would create tag test/test2/v0.1.0 on commit " + fullHash.String() + "\n" +
+				"Would create tag test/v0.1.0 on commit " + fullHash.String() + "\n" +
+				"Would sign the tags above with signing mode default\n" +
+				"Would push the tags above to upstream\n",
+		},
+		{
+			name:                "delete",
+			deleteModuleSetTags: true,
+			expected:            "Would delete tag test/test2/v0.1.0\nWould delete tag test/v0.1.0\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tagger.dryRun(&buf, tc.deleteModuleSetTags, tc.push, "upstream")
+			assert.Equal(t, tc.expected, buf.String())
+
+			tags, err := repo.Tags()
+			require.NoError(t, err)
+			count := 0
+			require.NoError(t, tags.ForEach(func(*plumbing.Reference) error {
+				count++
+				return nil
+			}))
+			assert.Zero(t, count, "no tags should be created")
+		})
+	}
+}
+
+func TestRunPreTagChecks(t *testing.T) {
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	versioningFilename := filepath.Join(testDataDir, "tag_all_modules", "versions_valid.yaml")
+
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	modFiles := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/test/test1\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test2\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "go.mod"):          []byte("module go.opentelemetry.io/test3\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "go.mod"):                  []byte("module go.opentelemetry.io/testroot/v2\n\ngo 1.16\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(modFiles), "could not create go mod file tree")
+	goodHash, err := commontest.CommitAll(repo, "add modules")
+	require.NoError(t, err)
+
+	localReplace := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/test2\n\ngo 1.16\n\n" +
+			"replace go.opentelemetry.io/test3 => ../\n"),
+	}
+	require.NoError(t, commontest.WriteTempFiles(localReplace))
+	badHash, err := commontest.CommitAll(repo, "add local replace")
+	require.NoError(t, err)
+
+	// The commit to tag does not need to be checked out, and the working tree is not read.
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Hash: goodHash}))
+	require.NoError(t, commontest.WriteTempFiles(localReplace))
+
+	tagger, err := newTagger(versioningFilename, "mod-set-2", tmpRootDir, badHash.String(), false)
+	require.NoError(t, err)
+	assert.NoError(t, tagger.runPreTagChecks(common.PreTagCheckOptions{Skip: common.SkippedChecks{
+		common.CheckLocalReplace: true, common.CheckTidy: true, common.CheckGoSum: true,
+	}}))
+	err = tagger.runPreTagChecks(common.PreTagCheckOptions{Skip: common.SkippedChecks{common.CheckTidy: true}})
+	assert.ErrorIs(t, err, common.ErrPreTagChecksFailed)
+	assert.ErrorContains(t, err, "local-replace: go.opentelemetry.io/test2: replace go.opentelemetry.io/test3 => ../ points at a local path")
+
+	tagger, err = newTagger(versioningFilename, "mod-set-2", tmpRootDir, goodHash.String(), false)
+	require.NoError(t, err)
+	assert.NoError(t, tagger.runPreTagChecks(common.PreTagCheckOptions{}))
+}
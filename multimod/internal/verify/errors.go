@@ -16,6 +16,7 @@ package verify
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
@@ -27,7 +28,11 @@ type errModuleNotInSet struct {
 }
 
 func (e *errModuleNotInSet) Error() string {
-	return fmt.Sprintf("Module %v (defined in %v) is not listed in any module set.", e.modPath, e.modFilePath)
+	return fmt.Sprintf("Module %v (defined in %v) is not listed in any module set. Run the suggest command to find one.", e.modPath, e.modFilePath)
+}
+
+func (e *errModuleNotInSet) Is(target error) bool {
+	return target == common.ErrVerificationFailed
 }
 
 type errModuleNotInRepo struct {
@@ -39,6 +44,10 @@ func (e *errModuleNotInRepo) Error() string {
 	return fmt.Sprintf("Module %v in module set %v does not exist in the current repo.", e.modPath, e.modSetName)
 }
 
+func (e *errModuleNotInRepo) Is(target error) bool {
+	return target == common.ErrVerificationFailed
+}
+
 type errInvalidVersion struct {
 	modSetName    string
 	modSetVersion string
@@ -48,6 +57,10 @@ func (e *errInvalidVersion) Error() string {
 	return fmt.Sprintf("Module set %v has invalid version string: %v", e.modSetName, e.modSetVersion)
 }
 
+func (e *errInvalidVersion) Is(target error) bool {
+	return target == common.ErrVerificationFailed
+}
+
 type errMultipleSetSameVersionSlice struct {
 	errs []*errMultipleSetSameVersion
 }
@@ -61,6 +74,10 @@ func (e *errMultipleSetSameVersionSlice) Error() string {
 	return strings.Join(errorStringSlice, "\n")
 }
 
+func (e *errMultipleSetSameVersionSlice) Is(target error) bool {
+	return target == common.ErrVerificationFailed
+}
+
 type errMultipleSetSameVersion struct {
 	modSetNames   []string
 	modSetVersion string
@@ -84,3 +101,58 @@ func (e *errDependency) Error() string {
 		e.modPath, e.modVersion,
 		e.depPath, e.depVersion)
 }
+
+// requirementSkew is a require line of a go.mod file that requires a module of a module set at
+// neither the version of its set nor the version of its latest tag.
+type requirementSkew struct {
+	modFilePath common.ModuleFilePath
+	line        int
+	depPath     common.ModulePath
+	version     string
+	setVersion  string
+	// latestTag is the version of the latest tag of the required module, empty if it was never tagged.
+	latestTag string
+}
+
+type errRequirementSkew struct {
+	repoRoot string
+	skews    []requirementSkew
+}
+
+func (e *errRequirementSkew) Error() string {
+	lines := []string{"out-of-date intra-repo requirements found (run with --fix to update them):"}
+	for _, skew := range e.skews {
+		modFilePath := string(skew.modFilePath)
+		if rel, err := filepath.Rel(e.repoRoot, modFilePath); err == nil {
+			modFilePath = rel
+		}
+
+		expected := skew.setVersion + " (module set version)"
+		if skew.latestTag != "" {
+			expected += " or " + skew.latestTag + " (latest tag)"
+		}
+		lines = append(lines, fmt.Sprintf("%v:%d: require %v %v, expected %v",
+			modFilePath, skew.line, skew.depPath, skew.version, expected))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *errRequirementSkew) Is(target error) bool {
+	return target == common.ErrVerificationFailed
+}
+
+type errTagMismatches struct {
+	mismatches []tagMismatch
+}
+
+func (e *errTagMismatches) Error() string {
+	lines := []string{"tags do not match the content of their commits:"}
+	for _, m := range e.mismatches {
+		lines = append(lines, fmt.Sprintf("%v: %v: %v", m.tag, m.file, m.msg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *errTagMismatches) Is(target error) bool {
+	return target == common.ErrVerificationFailed
+}
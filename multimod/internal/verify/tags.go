@@ -0,0 +1,315 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+)
+
+// versionGoRegex matches the version numbers in version.go files, which prerelease updates for
+// module sets that declare no version files.
+var versionGoRegex = regexp.MustCompile(common.SemverRegexNumberOnly)
+
+// RunTags verifies that the tags of the given module sets (or of all module sets if none are
+// given) point at commits whose go.mod and version files match the tagged version. Only the tags
+// of the current version of each set are verified, unless allVersions is true.
+func RunTags(versioningFile string, moduleSetNames []string, allVersions bool) error {
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return fmt.Errorf("unable to find repo root: %w", err)
+	}
+
+	v, err := newVerification(versioningFile, repoRoot)
+	if err != nil {
+		return fmt.Errorf("error creating new verification struct: %w", err)
+	}
+
+	r, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
+	}
+
+	if err = v.verifyTags(r, moduleSetNames, allVersions); err != nil {
+		return fmt.Errorf("verifyTags failed: %w", err)
+	}
+	return nil
+}
+
+// tagMismatch is a difference between the version of a tag and the content of the tagged commit.
+type tagMismatch struct {
+	tag string
+	// file is the path of the file relative to the repo root, with the line number if known.
+	file string
+	msg  string
+}
+
+// verifyTags checks the tags of every module in the named module sets. It returns an
+// errTagMismatches listing every difference found.
+func (v verification) verifyTags(r *git.Repository, moduleSetNames []string, allVersions bool) error {
+	if len(moduleSetNames) == 0 {
+		for modSetName := range v.ModuleVersioning.ModSetMap {
+			moduleSetNames = append(moduleSetNames, modSetName)
+		}
+		sort.Strings(moduleSetNames)
+	}
+
+	tags, err := repoTags(r)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []tagMismatch
+	verified := 0
+	for _, modSetName := range moduleSetNames {
+		modSet, ok := v.ModuleVersioning.ModSetMap[modSetName]
+		if !ok {
+			return common.NewErrModuleSetNotFound(modSetName, "")
+		}
+
+		tagNames, err := common.ModulePathsToTagNames(modSet.Modules, v.ModuleVersioning.ModPathMap, v.repoRoot)
+		if err != nil {
+			return fmt.Errorf("could not retrieve tag names from module paths: %w", err)
+		}
+
+		for i, modPath := range modSet.Modules {
+			var versions []string
+			modTagPrefix := common.ModuleTagPrefix(modSet.TagPrefix, tagNames[i])
+			for _, version := range moduleTagVersions(tags, modTagPrefix) {
+				if allVersions && common.MajorVersionMatches(modPath, version) || version == modSet.Version {
+					versions = append(versions, version)
+				}
+			}
+			if len(versions) == 0 {
+				log.Printf("Module %v has no tags to verify.\n", modPath)
+				continue
+			}
+
+			for _, version := range versions {
+				found, err := v.verifyTag(r, modSetName, modSet, modPath, modTagPrefix+version, version)
+				if err != nil {
+					return err
+				}
+				mismatches = append(mismatches, found...)
+				verified++
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return &errTagMismatches{mismatches: mismatches}
+	}
+
+	log.Printf("PASS: %d tags match the content of their commits.\n", verified)
+	return nil
+}
+
+// repoTags returns the names of all tags in the repo.
+func repoTags(r *git.Repository) ([]string, error) {
+	var names []string
+
+	tags, err := r.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("error getting repo tags: %w", err)
+	}
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		names = append(names, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list git tags: %w", err)
+	}
+	return names, nil
+}
+
+// moduleTagVersions returns the sorted versions of the tags of a module, which start with
+// modTagPrefix as returned by common.ModuleTagPrefix.
+func moduleTagVersions(tags []string, modTagPrefix string) []string {
+	var versions []string
+	for _, tag := range tags {
+		if version, ok := common.TagVersion(tag, modTagPrefix); ok {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return semver.Compare(versions[i], versions[j]) < 0 })
+	return versions
+}
+
+// verifyTag checks the go.mod and version files of the module at modPath in the commit tagged with
+// tag, which holds version.
+func (v verification) verifyTag(r *git.Repository, modSetName string, modSet common.ModuleSet, modPath common.ModulePath, tag, version string) ([]tagMismatch, error) {
+	commit, err := common.TagCommit(r, tag)
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit of tag %v: %w", tag, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not get tree of tag %v: %w", tag, err)
+	}
+
+	modDir, err := filepath.Rel(v.repoRoot, filepath.Dir(string(v.ModuleVersioning.ModPathMap[modPath])))
+	if err != nil {
+		return nil, err
+	}
+	modDir = filepath.ToSlash(modDir)
+
+	var mismatches []tagMismatch
+	report := func(file, format string, args ...interface{}) {
+		mismatches = append(mismatches, tagMismatch{tag: tag, file: file, msg: fmt.Sprintf(format, args...)})
+	}
+
+	goModPath := path.Join(modDir, "go.mod")
+	data, err := treeFileContents(tree, goModPath)
+	switch {
+	case errors.Is(err, object.ErrFileNotFound):
+		report(goModPath, "file does not exist")
+	case err != nil:
+		return nil, err
+	default:
+		modFile, err := modfile.ParseLax(goModPath, data, nil)
+		if err != nil {
+			report(goModPath, "could not parse file: %v", err)
+			break
+		}
+
+		if modFile.Module == nil || modFile.Module.Mod.Path != string(modPath) {
+			report(goModPath, "does not declare module %v", modPath)
+		}
+		for _, req := range modFile.Require {
+			dep, ok := v.ModuleVersioning.ModInfoMap[common.ModulePath(req.Mod.Path)]
+			if ok && dep.ModuleSetName == modSetName && semver.Compare(req.Mod.Version, version) != 0 {
+				report(fmt.Sprintf("%v:%d", goModPath, req.Syntax.Start.Line),
+					"requires %v %v, expected %v", req.Mod.Path, req.Mod.Version, version)
+			}
+		}
+	}
+
+	versionFiles, err := v.taggedVersionFiles(tree, modSet, modDir, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, vf := range versionFiles {
+		report(vf.path, "holds version %v, expected %v", vf.version, version)
+	}
+
+	return mismatches, nil
+}
+
+// versionFileMismatch is a version string in a version file that differs from the tagged version.
+type versionFileMismatch struct {
+	path    string
+	version string
+}
+
+// taggedVersionFiles returns the versions held in the version files of the module in modDir that
+// differ from version. If the module set declares no version files, the version.go file of the
+// module is checked, as updated by prerelease.
+func (v verification) taggedVersionFiles(tree *object.Tree, modSet common.ModuleSet, modDir, version string) ([]versionFileMismatch, error) {
+	var mismatches []versionFileMismatch
+
+	if len(modSet.VersionFiles) == 0 {
+		versionGoPath := path.Join(modDir, "version.go")
+		data, err := treeFileContents(tree, versionGoPath)
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, found := range versionGoRegex.FindAll(data, -1) {
+			if semver.Compare("v"+string(found), version) != 0 {
+				mismatches = append(mismatches, versionFileMismatch{path: versionGoPath, version: string(found)})
+			}
+		}
+		return mismatches, nil
+	}
+
+	for _, vf := range modSet.VersionFiles {
+		pattern := path.Join(modDir, vf.Path)
+		if strings.HasPrefix(vf.Path, "/") {
+			pattern = strings.TrimPrefix(vf.Path, "/")
+		}
+
+		paths, err := treeGlob(tree, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			data, err := treeFileContents(tree, p)
+			if err != nil {
+				return nil, err
+			}
+
+			found, err := vf.FindVersions(p, data)
+			if err != nil {
+				mismatches = append(mismatches, versionFileMismatch{path: p, version: fmt.Sprintf("none (%v)", err)})
+				continue
+			}
+			for _, fv := range found {
+				if semver.Compare("v"+strings.TrimPrefix(fv, "v"), version) != 0 {
+					mismatches = append(mismatches, versionFileMismatch{path: p, version: fv})
+				}
+			}
+		}
+	}
+
+	return mismatches, nil
+}
+
+// treeFileContents returns the contents of the file at p in tree.
+func treeFileContents(tree *object.Tree, p string) ([]byte, error) {
+	f, err := tree.File(p)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := f.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("could not read %v: %w", p, err)
+	}
+	return []byte(contents), nil
+}
+
+// treeGlob returns the paths of the files in tree matching pattern.
+func treeGlob(tree *object.Tree, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid version file path %q: %w", pattern, err)
+	}
+
+	var paths []string
+	err := tree.Files().ForEach(func(f *object.File) error {
+		if ok, _ := path.Match(pattern, f.Name); ok {
+			paths = append(paths, f.Name)
+		}
+		return nil
+	})
+	return paths, err
+}
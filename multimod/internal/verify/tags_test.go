@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestVerifyTags(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	versioningFilename := filepath.Join(tmpRootDir, "versions.yaml")
+	test1Dir := filepath.Join(tmpRootDir, "test", "test1")
+	test2Dir := filepath.Join(tmpRootDir, "test", "test2")
+	test3Dir := filepath.Join(tmpRootDir, "test", "test3")
+
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		versioningFilename: []byte("module-sets:\n" +
+			"  stable:\n    version: v1.1.0\n    modules:\n" +
+			"      - go.opentelemetry.io/test/test1\n      - go.opentelemetry.io/test/test2\n" +
+			"  declared:\n    version: v0.2.0\n    modules:\n      - go.opentelemetry.io/test/test3\n" +
+			"    version-files:\n      - path: version.go\n        constant: Version\n"),
+		filepath.Join(test1Dir, "go.mod"):     []byte("module go.opentelemetry.io/test/test1\n\ngo 1.19\n"),
+		filepath.Join(test1Dir, "version.go"): []byte("package test1\n\nfunc Version() string {\n\treturn \"0.9.0\"\n}\n"),
+		filepath.Join(test2Dir, "go.mod"): []byte("module go.opentelemetry.io/test/test2\n\ngo 1.19\n\n" +
+			"require go.opentelemetry.io/test/test1 v1.0.0\n"),
+		filepath.Join(test3Dir, "go.mod"):     []byte("module go.opentelemetry.io/test/test3\n\ngo 1.19\n"),
+		filepath.Join(test3Dir, "version.go"): []byte("package test3\n\nconst Version = \"v0.1.0\"\n"),
+	}))
+	firstHash, err := commontest.CommitAll(repo, "v1.0.0")
+	require.NoError(t, err)
+
+	// A lightweight tag and an annotated tag.
+	_, err = repo.CreateTag("test/test1/v1.0.0", firstHash, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("test/test2/v1.0.0", firstHash, &git.CreateTagOptions{Message: "v1.0.0", Tagger: commontest.TestAuthor})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("test/test3/v0.1.0", firstHash, &git.CreateTagOptions{Message: "v0.1.0", Tagger: commontest.TestAuthor})
+	require.NoError(t, err)
+
+	// The requirement of test2 on test1 was not updated for v1.1.0, and neither was the version of test3.
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(test1Dir, "version.go"): []byte("package test1\n\nfunc Version() string {\n\treturn \"1.1.0\"\n}\n"),
+	}))
+	secondHash, err := commontest.CommitAll(repo, "v1.1.0")
+	require.NoError(t, err)
+	for _, tagName := range []string{"test/test1/v1.1.0", "test/test2/v1.1.0", "test/test3/v0.2.0"} {
+		_, err = repo.CreateTag(tagName, secondHash, &git.CreateTagOptions{Message: tagName, Tagger: commontest.TestAuthor})
+		require.NoError(t, err)
+	}
+
+	v, err := newVerification(versioningFilename, tmpRootDir)
+	require.NoError(t, err)
+
+	t.Run("current versions", func(t *testing.T) {
+		err := v.verifyTags(repo, nil, false)
+		assert.EqualError(t, err, "tags do not match the content of their commits:\n"+
+			"test/test3/v0.2.0: test/test3/version.go: holds version v0.1.0, expected v0.2.0\n"+
+			"test/test2/v1.1.0: test/test2/go.mod:5: requires go.opentelemetry.io/test/test1 v1.0.0, expected v1.1.0")
+	})
+
+	t.Run("all versions", func(t *testing.T) {
+		err := v.verifyTags(repo, []string{"stable"}, true)
+		assert.EqualError(t, err, "tags do not match the content of their commits:\n"+
+			"test/test1/v1.0.0: test/test1/version.go: holds version 0.9.0, expected v1.0.0\n"+
+			"test/test2/v1.1.0: test/test2/go.mod:5: requires go.opentelemetry.io/test/test1 v1.0.0, expected v1.1.0")
+	})
+
+	t.Run("passing", func(t *testing.T) {
+		err := v.verifyTags(repo, []string{"stable"}, false)
+		assert.ErrorContains(t, err, "test/test2/v1.1.0")
+
+		require.NoError(t, repo.DeleteTag("test/test2/v1.1.0"))
+		assert.NoError(t, v.verifyTags(repo, []string{"stable"}, false))
+	})
+
+	t.Run("unknown module set", func(t *testing.T) {
+		err := v.verifyTags(repo, []string{"unknown"}, false)
+		assert.ErrorContains(t, err, "could not find module set unknown")
+	})
+}
@@ -19,7 +19,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/go-git/go-git/v5"
+	"go.uber.org/multierr"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/semver"
 
@@ -27,40 +30,57 @@ import (
 	"go.opentelemetry.io/build-tools/multimod/internal/common"
 )
 
-func Run(versioningFile string) {
+func Run(versioningFile string, fix bool) error {
 
 	repoRoot, err := repo.FindRoot()
 	if err != nil {
-		log.Fatalf("unable to find repo root: %v", err)
+		return fmt.Errorf("unable to find repo root: %w", err)
 	}
 
 	v, err := newVerification(versioningFile, repoRoot)
 	if err != nil {
-		log.Fatalf("Error creating new verification struct: %v", err)
+		return fmt.Errorf("error creating new verification struct: %w", err)
 	}
 
 	if err = v.verifyAllModulesInSet(); err != nil {
-		log.Fatalf("verifyAllModulesInSet failed: %v", err)
+		return fmt.Errorf("verifyAllModulesInSet failed: %w", err)
 	}
 
 	if err = v.verifyVersions(); err != nil {
-		log.Fatalf("verifyVersions failed: %v", err)
+		return fmt.Errorf("verifyVersions failed: %w", err)
+	}
+
+	if err = v.verifyMajorVersions(); err != nil {
+		return fmt.Errorf("verifyMajorVersions failed: %w", err)
 	}
 
 	if err = v.verifyDependencies(); err != nil {
-		log.Fatalf("verifyDependencies failed: %v", err)
+		return fmt.Errorf("verifyDependencies failed: %w", err)
+	}
+
+	r, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return fmt.Errorf("could not open repo at %v: %w", repoRoot, err)
+	}
+
+	latestTags, err := v.latestTagVersions(r)
+	if err != nil {
+		return fmt.Errorf("could not get latest tags of modules: %w", err)
+	}
+
+	if err = v.verifyRequirementVersions(latestTags, fix); err != nil {
+		return fmt.Errorf("verifyRequirementVersions failed: %w", err)
 	}
 
 	log.Println("PASS: Module sets successfully verified.")
+	return nil
 }
 
 type verification struct {
 	common.ModuleVersioning
+	repoRoot string
 }
 
-// dependencyMap keeps track of all modules' dependencies.
-type dependencyMap map[common.ModulePath][]common.ModulePath
-
 func newVerification(versioningFilename, repoRoot string) (verification, error) {
 	modVersioning, err := common.NewModuleVersioning(versioningFilename, repoRoot)
 	if err != nil {
@@ -69,39 +89,10 @@ func newVerification(versioningFilename, repoRoot string) (verification, error)
 
 	return verification{
 		ModuleVersioning: modVersioning,
+		repoRoot:         repoRoot,
 	}, nil
 }
 
-// getDependencies returns a map of each module's dependencies on other modules within the same repo.
-func (v verification) getDependencies() (dependencyMap, error) {
-	modVersioning := v.ModuleVersioning
-	dependencies := make(dependencyMap)
-
-	// Dependencies are defined by the require section of go.mod files.
-	for modPath := range modVersioning.ModInfoMap {
-		modFilePath := modVersioning.ModPathMap[modPath]
-		modData, err := os.ReadFile(filepath.Clean(string(modFilePath)))
-		if err != nil {
-			return nil, fmt.Errorf("could not read mod file: %w", err)
-		}
-
-		modFile, err := modfile.Parse("", modData, nil)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse go.mod file at %v: %w", modFilePath, err)
-		}
-
-		// get dependencies as defined by the "require" section
-		for _, dep := range modFile.Require {
-			// check if dependency is in the same repo (i.e. if it exists in the module versioning file)
-			if _, exists := modVersioning.ModInfoMap[common.ModulePath(dep.Mod.Path)]; exists {
-				dependencies[modPath] = append(dependencies[modPath], common.ModulePath(dep.Mod.Path))
-			}
-		}
-	}
-
-	return dependencies, nil
-}
-
 // verifyAllModulesInSet checks that every module (as defined by a go.mod file) is contained in exactly
 // one module set, unless it is excluded.
 func (v verification) verifyAllModulesInSet() error {
@@ -172,9 +163,31 @@ func (v verification) verifyVersions() error {
 	return nil
 }
 
+// verifyMajorVersions checks that the major version of every module set matches the major version
+// suffix of the paths of its modules.
+func (v verification) verifyMajorVersions() error {
+	modSetNames := make([]string, 0, len(v.ModuleVersioning.ModSetMap))
+	for modSetName := range v.ModuleVersioning.ModSetMap {
+		modSetNames = append(modSetNames, modSetName)
+	}
+	sort.Strings(modSetNames)
+
+	var err error
+	for _, modSetName := range modSetNames {
+		err = multierr.Append(err, common.CheckModuleSetMajorVersion(modSetName, v.ModuleVersioning.ModSetMap[modSetName]))
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Println("PASS: All module set versions match the major version suffix of their module paths.")
+
+	return nil
+}
+
 // verifyDependencies checks that dependencies between modules conform to versioning semantics.
 func (v verification) verifyDependencies() error {
-	dependencies, err := v.getDependencies()
+	dependencies, err := v.Dependencies()
 	if err != nil {
 		return fmt.Errorf("could not get dependencies of module versioning: %w", err)
 	}
@@ -204,3 +217,132 @@ func (v verification) verifyDependencies() error {
 	log.Println("Finished checking all stable modules' dependencies.")
 	return nil
 }
+
+// latestTagVersions returns the version of the latest tag of each module listed in a module set.
+// Modules without any tag are omitted.
+func (v verification) latestTagVersions(r *git.Repository) (map[common.ModulePath]string, error) {
+	tags, err := repoTags(r)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[common.ModulePath]string)
+	for modPath := range v.ModuleVersioning.ModInfoMap {
+		modTagNames, err := common.ModulePathsToTagNames([]common.ModulePath{modPath}, v.ModuleVersioning.ModPathMap, v.repoRoot)
+		if err != nil {
+			return nil, err
+		}
+
+		// Modules in different major versions may share a tag name, so only versions matching the
+		// major version suffix of the module path are considered.
+		modTagPrefix := common.ModuleTagPrefix(v.ModSetMap[v.ModuleVersioning.ModInfoMap[modPath].ModuleSetName].TagPrefix, modTagNames[0])
+		for _, version := range moduleTagVersions(tags, modTagPrefix) {
+			if common.MajorVersionMatches(modPath, version) && semver.Compare(version, latest[modPath]) > 0 {
+				latest[modPath] = version
+			}
+		}
+	}
+
+	return latest, nil
+}
+
+// requirementSkews returns the require lines of all go.mod files in the repo that require a module of
+// a module set at neither the version of its set nor the version of its latest tag.
+func (v verification) requirementSkews(latestTags map[common.ModulePath]string) ([]requirementSkew, error) {
+	var skews []requirementSkew
+
+	for _, modFilePath := range v.ModuleVersioning.ModPathMap {
+		modData, err := os.ReadFile(filepath.Clean(string(modFilePath)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read mod file: %w", err)
+		}
+
+		modFile, err := modfile.Parse(string(modFilePath), modData, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse go.mod file at %v: %w", modFilePath, err)
+		}
+
+		for _, req := range modFile.Require {
+			depPath := common.ModulePath(req.Mod.Path)
+			depInfo, exists := v.ModuleVersioning.ModInfoMap[depPath]
+			// Versions are compared with semver, since parsing drops build metadata from versions.
+			if !exists || semver.Compare(req.Mod.Version, depInfo.Version) == 0 ||
+				semver.Compare(req.Mod.Version, latestTags[depPath]) == 0 {
+				continue
+			}
+
+			skews = append(skews, requirementSkew{
+				modFilePath: modFilePath,
+				line:        req.Syntax.Start.Line,
+				depPath:     depPath,
+				version:     req.Mod.Version,
+				setVersion:  depInfo.Version,
+				latestTag:   latestTags[depPath],
+			})
+		}
+	}
+
+	sort.Slice(skews, func(i, j int) bool {
+		if skews[i].modFilePath != skews[j].modFilePath {
+			return skews[i].modFilePath < skews[j].modFilePath
+		}
+		return skews[i].line < skews[j].line
+	})
+
+	return skews, nil
+}
+
+// verifyRequirementVersions checks that every go.mod file in the repo requires modules of module sets
+// at the version of their set or of their latest tag. If fix is true, out-of-date requirements are
+// rewritten to the version of the module set instead, leaving replace directives as they are.
+func (v verification) verifyRequirementVersions(latestTags map[common.ModulePath]string, fix bool) error {
+	skews, err := v.requirementSkews(latestTags)
+	if err != nil {
+		return fmt.Errorf("could not check requirement versions: %w", err)
+	}
+
+	if len(skews) == 0 {
+		log.Println("PASS: All intra-repo requirements are at their module set version or latest tag.")
+		return nil
+	}
+
+	if !fix {
+		return &errRequirementSkew{
+			repoRoot: v.repoRoot,
+			skews:    skews,
+		}
+	}
+
+	// Rewrite the requirements of each file, grouped by the version they are updated to.
+	type fileVersion struct {
+		modFilePath common.ModuleFilePath
+		version     string
+	}
+	var order []fileVersion
+	depPaths := make(map[fileVersion][]common.ModulePath)
+	for _, skew := range skews {
+		key := fileVersion{modFilePath: skew.modFilePath, version: skew.setVersion}
+		if _, exists := depPaths[key]; !exists {
+			order = append(order, key)
+		}
+		depPaths[key] = append(depPaths[key], skew.depPath)
+	}
+
+	for _, key := range order {
+		edits, err := common.GoModRequireEdits([]common.ModuleFilePath{key.modFilePath}, depPaths[key], key.version)
+		if err != nil {
+			return fmt.Errorf("could not fix requirements in %v: %w", key.modFilePath, err)
+		}
+		for _, edit := range edits {
+			if err = edit.Write(); err != nil {
+				return err
+			}
+			for _, change := range edit.Changes {
+				log.Printf("Fixed %v: %v\n", edit.Path, change)
+			}
+		}
+	}
+
+	log.Printf("FIXED: %d out-of-date intra-repo requirements. Run 'go mod tidy' in the changed modules.\n", len(skews))
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -190,7 +191,7 @@ func TestGetDependencies(t *testing.T) {
 		tmpRootDir,
 	)
 
-	expected := dependencyMap{
+	expected := common.DependencyMap{
 		"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1": []common.ModulePath{
 			"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2",
 			"go.opentelemetry.io/build-tools/multimod/internal/verify/test3",
@@ -211,13 +212,13 @@ func TestGetDependencies(t *testing.T) {
 		},
 	}
 
-	actual, err := v.getDependencies()
+	actual, err := v.Dependencies()
 
 	require.NoError(t, err)
 	require.Equal(t, len(expected), len(actual))
 	for modPath, expectedDepPaths := range expected {
 		actualDepPaths, ok := actual[modPath]
-		require.True(t, ok, "modPath %v is not in actual dependencyMap.", modPath)
+		require.True(t, ok, "modPath %v is not in actual DependencyMap.", modPath)
 
 		assert.ElementsMatch(t, expectedDepPaths, actualDepPaths)
 	}
@@ -302,6 +303,7 @@ func TestVerifyVersions(t *testing.T) {
 		versioningFilename string
 		repoRoot           string
 		modFiles           map[string][]byte
+		expectedLoadError  string
 		expectedError      error
 	}{
 		{
@@ -326,10 +328,8 @@ func TestVerifyVersions(t *testing.T) {
 				filepath.Join(tmpRootDir, "invalid_version", "go.mod"):                     []byte("module go.opentelemetry.io/testroot/v2\n\ngo 1.16\n"),
 				filepath.Join(tmpRootDir, "invalid_version", "test", "excluded", "go.mod"): []byte("module \"go.opentelemetry.io/test/testexcluded\"\n\ngo 1.16\n"),
 			},
-			expectedError: &errInvalidVersion{
-				modSetName:    "mod-set-1",
-				modSetVersion: "invalid-version-v.02.0.",
-			},
+			// invalid versions are rejected when the versioning file is read
+			expectedLoadError: `invalid_version.yaml:17:14: module set "mod-set-1" has invalid semver version "invalid-version-v.02.0."`,
 		},
 		{
 			name:               "multiple sets with same major version",
@@ -361,6 +361,10 @@ func TestVerifyVersions(t *testing.T) {
 			require.NoError(t, commontest.WriteTempFiles(tc.modFiles), "could not create go mod file tree")
 
 			v, err := newVerification(tc.versioningFilename, tc.repoRoot)
+			if tc.expectedLoadError != "" {
+				require.ErrorContains(t, err, tc.expectedLoadError)
+				return
+			}
 			require.NoError(t, err)
 
 			actual := v.verifyVersions()
@@ -506,3 +510,143 @@ func TestVerifyDependencies(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyMajorVersions(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	versioningFilename := filepath.Join(tmpRootDir, "versions.yaml")
+	modFiles := map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "go.mod"):       []byte("module go.opentelemetry.io/test\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "v2", "go.mod"): []byte("module go.opentelemetry.io/test/v2\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "other", "go.mod"):      []byte("module go.opentelemetry.io/other/v3\n\ngo 1.16\n"),
+	}
+
+	require.NoError(t, commontest.WriteTempFiles(modFiles))
+	require.NoError(t, os.WriteFile(versioningFilename, []byte("module-sets:\n"+
+		"  stable-v1:\n    version: v1.2.0\n    modules:\n      - go.opentelemetry.io/test\n"+
+		"  stable-v2:\n    version: v2.0.0\n    modules:\n      - go.opentelemetry.io/test/v2\n"+
+		"  stable-v3:\n    version: v3.1.0\n    modules:\n      - go.opentelemetry.io/other/v3\n"), 0600))
+
+	v, err := newVerification(versioningFilename, tmpRootDir)
+	require.NoError(t, err)
+	assert.NoError(t, v.verifyMajorVersions())
+
+	require.NoError(t, os.WriteFile(versioningFilename, []byte("module-sets:\n"+
+		"  stable-v1:\n    version: v2.0.0\n    modules:\n      - go.opentelemetry.io/test\n"+
+		"  stable-v2:\n    version: v2.0.0\n    modules:\n      - go.opentelemetry.io/test/v2\n"+
+		"  stable-v3:\n    version: v4.0.0\n    modules:\n      - go.opentelemetry.io/other/v3\n"), 0600))
+
+	v, err = newVerification(versioningFilename, tmpRootDir)
+	require.NoError(t, err)
+	err = v.verifyMajorVersions()
+	assert.ErrorContains(t, err, "module set stable-v1 has version v2.0.0, but the major version suffix of these module paths does not match v2:\ngo.opentelemetry.io/test")
+	assert.ErrorContains(t, err, "module set stable-v3 has version v4.0.0, but the major version suffix of these module paths does not match v4:\ngo.opentelemetry.io/other/v3")
+}
+
+func TestLatestTagVersions(t *testing.T) {
+	tmpRootDir := t.TempDir()
+	repo, hash, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "test", "go.mod"):          []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test3\n\ngo 1.16\n"),
+		filepath.Join(tmpRootDir, "go.mod"):                  []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/testroot\n\ngo 1.16\n"),
+	}))
+
+	for _, tagName := range []string{"test/test1/v1.0.0", "test/test1/v1.2.0", "test/test1/v1.10.0-RC1", "test/v0.1.0", "test/test1/unrelated", "v0.1.0"} {
+		_, err = repo.CreateTag(tagName, hash, &git.CreateTagOptions{
+			Message: tagName,
+			Tagger:  commontest.TestAuthor,
+		})
+		require.NoError(t, err)
+	}
+
+	v, err := newVerification(filepath.Join(testDataDir, "verify_dependencies", "versions_valid.yaml"), tmpRootDir)
+	require.NoError(t, err)
+
+	actual, err := v.latestTagVersions(repo)
+	require.NoError(t, err)
+	assert.Equal(t, map[common.ModulePath]string{
+		"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1": "v1.10.0-RC1",
+		"go.opentelemetry.io/build-tools/multimod/internal/verify/test3":      "v0.1.0",
+		"go.opentelemetry.io/build-tools/multimod/internal/verify/testroot":   "v0.1.0",
+	}, actual)
+}
+
+func TestVerifyRequirementVersions(t *testing.T) {
+	versioningFilename := filepath.Join(testDataDir, "verify_dependencies", "versions_valid.yaml")
+	latestTags := map[common.ModulePath]string{
+		"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1": "v1.2.2",
+		"go.opentelemetry.io/build-tools/multimod/internal/verify/test3":      "v0.0.9",
+	}
+
+	modFiles := func(root string) map[string][]byte {
+		return map[string][]byte{
+			filepath.Join(root, "test", "test1", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1\n\n" +
+				"go 1.16\n\n" +
+				"require (\n\t" +
+				"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2 v1.2.3-RC1+meta\n\t" +
+				"go.opentelemetry.io/other v1.0.0\n" +
+				")\n"),
+			filepath.Join(root, "test", "test2", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2\n\n" +
+				"go 1.16\n\n" +
+				"require (\n\t" +
+				"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1 v1.2.2\n\t" +
+				"go.opentelemetry.io/build-tools/multimod/internal/verify/test3 v0.0.8\n" +
+				")\n"),
+			filepath.Join(root, "test", "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test3\n\n" +
+				"go 1.16\n"),
+			filepath.Join(root, "go.mod"): []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/testroot\n\n" +
+				"go 1.16\n\n" +
+				"require go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2 v1.0.0\n\n" +
+				"replace go.opentelemetry.io/other => go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2 v1.0.0\n"),
+		}
+	}
+
+	t.Run("report", func(t *testing.T) {
+		tmpRootDir := t.TempDir()
+		require.NoError(t, commontest.WriteTempFiles(modFiles(tmpRootDir)))
+
+		v, err := newVerification(versioningFilename, tmpRootDir)
+		require.NoError(t, err)
+
+		err = v.verifyRequirementVersions(latestTags, false)
+		var skewErr *errRequirementSkew
+		require.ErrorAs(t, err, &skewErr)
+		assert.Equal(t, "out-of-date intra-repo requirements found (run with --fix to update them):\n"+
+			"go.mod:5: require go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2 v1.0.0, "+
+			"expected v1.2.3-RC1+meta (module set version)\n"+
+			"test/test2/go.mod:7: require go.opentelemetry.io/build-tools/multimod/internal/verify/test3 v0.0.8, "+
+			"expected v0.1.0 (module set version) or v0.0.9 (latest tag)", err.Error())
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		tmpRootDir := t.TempDir()
+		require.NoError(t, commontest.WriteTempFiles(modFiles(tmpRootDir)))
+
+		v, err := newVerification(versioningFilename, tmpRootDir)
+		require.NoError(t, err)
+
+		require.NoError(t, v.verifyRequirementVersions(latestTags, true))
+
+		expected := modFiles(tmpRootDir)
+		expected[filepath.Join(tmpRootDir, "go.mod")] = []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/testroot\n\n" +
+			"go 1.16\n\n" +
+			"require go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2 v1.2.3-RC1+meta\n\n" +
+			"replace go.opentelemetry.io/other => go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2 v1.0.0\n")
+		expected[filepath.Join(tmpRootDir, "test", "test2", "go.mod")] = []byte("module go.opentelemetry.io/build-tools/multimod/internal/verify/test/test2\n\n" +
+			"go 1.16\n\n" +
+			"require (\n\t" +
+			"go.opentelemetry.io/build-tools/multimod/internal/verify/test/test1 v1.2.2\n\t" +
+			"go.opentelemetry.io/build-tools/multimod/internal/verify/test3 v0.1.0\n" +
+			")\n")
+		for modFilePath, expectedData := range expected {
+			actual, err := os.ReadFile(filepath.Clean(modFilePath))
+			require.NoError(t, err)
+			assert.Equal(t, string(expectedData), string(actual), modFilePath)
+		}
+
+		require.NoError(t, v.verifyRequirementVersions(latestTags, false))
+	})
+}
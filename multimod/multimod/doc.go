@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package multimod runs the multimod commands from Go code. Every command
+// works on the Git repo enclosing the working directory, as the multimod
+// binary does, and returns an error instead of exiting the process. The
+// errors can be told apart with errors.Is and errors.As, using the errors
+// declared in this package.
+package multimod
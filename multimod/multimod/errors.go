@@ -0,0 +1,33 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package multimod
+
+import "go.opentelemetry.io/build-tools/multimod/internal/common"
+
+// Errors matched with errors.Is by the errors returned from the commands.
+var (
+	// ErrInvalidVersioningFile is matched when the versioning file does not conform to its
+	// schema, or when its module patterns conflict.
+	ErrInvalidVersioningFile = common.ErrInvalidVersioningFile
+	// ErrModuleSetNotFound is matched when a module set is not listed in the versioning file.
+	ErrModuleSetNotFound = common.ErrModuleSetNotFound
+	// ErrWorkingTreeNotClean is matched when a command that commits changes finds uncommitted
+	// changes in the working tree.
+	ErrWorkingTreeNotClean = common.ErrWorkingTreeNotClean
+	// ErrPreTagChecksFailed is matched when the modules of a module set fail the pre-tag checks.
+	ErrPreTagChecksFailed = common.ErrPreTagChecksFailed
+	// ErrVerificationFailed is matched when Verify or VerifyTags find problems in the repo.
+	ErrVerificationFailed = common.ErrVerificationFailed
+	// ErrModuleSetCycle is matched when module sets depend on each other, so that no release
+	// order exists.
+	ErrModuleSetCycle = common.ErrModuleSetCycle
+)
+
+// ErrGitTagsAlreadyExist is returned, as found with errors.As, when all the tags of the version
+// of a module set already exist.
+type ErrGitTagsAlreadyExist = common.ErrGitTagsAlreadyExist
+
+// ErrInconsistentGitTagsExist is returned, as found with errors.As, when only some of the tags of
+// the version of a module set exist.
+type ErrInconsistentGitTagsExist = common.ErrInconsistentGitTagsExist
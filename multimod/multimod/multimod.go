@@ -0,0 +1,432 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package multimod
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"go.opentelemetry.io/build-tools/internal/repo"
+	"go.opentelemetry.io/build-tools/multimod/internal/bump"
+	"go.opentelemetry.io/build-tools/multimod/internal/common"
+	"go.opentelemetry.io/build-tools/multimod/internal/diff"
+	"go.opentelemetry.io/build-tools/multimod/internal/graph"
+	"go.opentelemetry.io/build-tools/multimod/internal/plan"
+	"go.opentelemetry.io/build-tools/multimod/internal/prerelease"
+	"go.opentelemetry.io/build-tools/multimod/internal/release"
+	"go.opentelemetry.io/build-tools/multimod/internal/retract"
+	"go.opentelemetry.io/build-tools/multimod/internal/suggest"
+	"go.opentelemetry.io/build-tools/multimod/internal/sync"
+	"go.opentelemetry.io/build-tools/multimod/internal/tag"
+	"go.opentelemetry.io/build-tools/multimod/internal/verify"
+)
+
+// DefaultVersioningFile is the name of the versioning file, in the repo root, used when the
+// options of a command leave it empty.
+const DefaultVersioningFile = "versions.yaml"
+
+// DefaultGoModTidyCompat is the Go version passed to "go mod tidy -compat" by the command line.
+const DefaultGoModTidyCompat = common.DefaultGoModTidyCompat
+
+// versioningFilePath returns versioningFile, or the default versioning file of the repo if it is
+// empty.
+func versioningFilePath(versioningFile string) (string, error) {
+	if versioningFile != "" {
+		return versioningFile, nil
+	}
+
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return "", fmt.Errorf("unable to find repo root: %w", err)
+	}
+	return filepath.Join(repoRoot, DefaultVersioningFile), nil
+}
+
+// VerifyOptions configures Verify.
+type VerifyOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// Fix rewrites out-of-date requirements on modules of the repo to the version of their set.
+	Fix bool
+}
+
+// Verify checks that every module of the repo is in exactly one module set, that module set
+// versions are valid and that go.mod files require modules of the repo at up-to-date versions.
+func Verify(opts VerifyOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	return verify.Run(versioningFile, opts.Fix)
+}
+
+// VerifyTagsOptions configures VerifyTags.
+type VerifyTagsOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// ModuleSetNames lists the module sets whose tags are verified, all of them if empty.
+	ModuleSetNames []string
+	// AllVersions verifies the tags of every version, not only of the current version of each set.
+	AllVersions bool
+}
+
+// VerifyTags checks that the tagged commits of the modules in the module sets hold go.mod and
+// version files matching the tagged version.
+func VerifyTags(opts VerifyTagsOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	return verify.RunTags(versioningFile, opts.ModuleSetNames, opts.AllVersions)
+}
+
+// PrereleaseOptions configures Prerelease.
+type PrereleaseOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// ModuleSetNames lists the module sets to prepare, unless AllModuleSets is set.
+	ModuleSetNames []string
+	AllModuleSets  bool
+	// SkipModTidy skips running "go mod tidy" on the changed modules.
+	SkipModTidy bool
+	// GoModTidyCompat is the Go version passed to "go mod tidy -compat", omitted if empty.
+	GoModTidyCompat string
+	// CommitToDifferentBranch commits the changes of each module set to a new branch.
+	CommitToDifferentBranch bool
+	// DryRun prints the planned changes instead of making them.
+	DryRun bool
+	// SkipChecks lists the pre-tag checks to skip, or "all".
+	SkipChecks []string
+}
+
+// Prerelease updates the version files and the go.mod files of the repo to the new version of
+// each module set, and commits the changes.
+func Prerelease(opts PrereleaseOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	return prerelease.Run(versioningFile, opts.ModuleSetNames, opts.AllModuleSets, opts.SkipModTidy, opts.GoModTidyCompat,
+		opts.CommitToDifferentBranch, opts.DryRun, opts.SkipChecks)
+}
+
+// TagOptions configures Tag.
+type TagOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	ModuleSetName  string
+	// CommitHash is the commit to tag.
+	CommitHash string
+	// DeleteModuleSetTags deletes the tags of the current version of the module set instead.
+	DeleteModuleSetTags bool
+	// PrintTags prints the tags once done.
+	PrintTags bool
+	// Push pushes the new tags to Remote and verifies them.
+	Push   bool
+	Remote string
+	// SigningMode is default, none, gpg or ssh. Empty means default, which follows the Git
+	// configuration.
+	SigningMode string
+	// DryRun lists the tags that would be created or deleted instead.
+	DryRun bool
+	// SkipChecks lists the pre-tag checks to skip, or "all".
+	SkipChecks []string
+	// GoModTidyCompat is the Go version passed to "go mod tidy -compat" by the tidy check.
+	GoModTidyCompat string
+}
+
+// Tag creates the tags of the current version of a module set on a commit.
+func Tag(opts TagOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	if opts.SigningMode == "" {
+		opts.SigningMode = string(tag.DefaultSigningMode)
+	}
+	return tag.Run(versioningFile, opts.ModuleSetName, opts.CommitHash, opts.DeleteModuleSetTags, opts.PrintTags, opts.Push,
+		opts.Remote, opts.SigningMode, opts.DryRun, opts.SkipChecks, opts.GoModTidyCompat)
+}
+
+// SyncOptions configures Sync.
+type SyncOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// OtherRepoRoot is the root of a local clone of the other repo. Exactly one of OtherRepoRoot
+	// and OtherRepoURL must be set.
+	OtherRepoRoot string
+	// OtherRepoURL is the git URL, or the path of a local bare repo, of the other repo, which is
+	// cloned in memory.
+	OtherRepoURL string
+	// OtherRef is the ref of OtherRepoURL to read the versioning file at. The latest tag of each
+	// module set is used if empty.
+	OtherRef string
+	// OtherVersioningFile is the path of the versioning file of the other repo, relative to its
+	// root with OtherRepoURL. DefaultVersioningFile in the other repo root is used if empty.
+	OtherVersioningFile string
+	// ModuleSetNames lists the module sets of the other repo to sync, unless AllModuleSets is set.
+	ModuleSetNames []string
+	AllModuleSets  bool
+	// SkipModTidy skips running "go mod tidy" on the changed modules.
+	SkipModTidy bool
+	// GoModTidyCompat is the Go version passed to "go mod tidy -compat", omitted if empty.
+	GoModTidyCompat string
+	// CommitToDifferentBranch commits the changes of each module set to a new branch.
+	CommitToDifferentBranch bool
+	// DryRun prints the planned changes instead of making them.
+	DryRun bool
+}
+
+// Sync updates the requirements of the repo on the modules of another repo to the versions of
+// their module sets there, and commits the changes.
+func Sync(opts SyncOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	if (opts.OtherRepoRoot == "") == (opts.OtherRepoURL == "") {
+		return errors.New("exactly one of the other repo root and URL must be given")
+	}
+	if opts.OtherRef != "" && opts.OtherRepoURL == "" {
+		return errors.New("the other ref can only be used with the other repo URL")
+	}
+
+	other := sync.Other{
+		VersioningFile: opts.OtherVersioningFile,
+		RepoRoot:       opts.OtherRepoRoot,
+		URL:            opts.OtherRepoURL,
+		Ref:            opts.OtherRef,
+	}
+	if other.VersioningFile == "" {
+		other.VersioningFile = DefaultVersioningFile
+		if other.URL == "" {
+			other.VersioningFile = filepath.Join(other.RepoRoot, other.VersioningFile)
+		}
+	}
+	return sync.Run(versioningFile, other, opts.ModuleSetNames, opts.AllModuleSets, opts.SkipModTidy, opts.GoModTidyCompat,
+		opts.CommitToDifferentBranch, opts.DryRun)
+}
+
+// BumpOptions configures Bump.
+type BumpOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// ModuleSetNames lists the module sets to consider, all of them if empty.
+	ModuleSetNames []string
+	// Level is patch, minor or major. It is derived from the chloggen entries if empty.
+	Level string
+	// ChlogDir is the directory of the chloggen entries, .chloggen in the repo root if empty.
+	ChlogDir string
+}
+
+// Bump sets the next version of the module sets whose modules changed since they were tagged.
+func Bump(opts BumpOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	return bump.Run(versioningFile, opts.ModuleSetNames, opts.Level, opts.ChlogDir)
+}
+
+// PlanOptions configures Plan.
+type PlanOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	ModuleSetName  string
+	// Format is markdown or json, markdown if empty.
+	Format string
+}
+
+// Plan prints the release plan of a module set to stdout.
+func Plan(opts PlanOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	if opts.Format == "" {
+		opts.Format = plan.FormatMarkdown
+	}
+	return plan.Run(versioningFile, opts.ModuleSetName, opts.Format)
+}
+
+// GraphOptions configures Graph.
+type GraphOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// Level is module or module-set, module-set if empty.
+	Level string
+	// Format is dot, mermaid or json, dot if empty.
+	Format string
+}
+
+// Graph prints the dependency graph of the modules or module sets to stdout.
+func Graph(opts GraphOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	if opts.Level == "" {
+		opts.Level = graph.LevelModuleSet
+	}
+	if opts.Format == "" {
+		opts.Format = graph.FormatDOT
+	}
+	return graph.Run(versioningFile, opts.Level, opts.Format)
+}
+
+// ReleaseOptions configures Release.
+type ReleaseOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// ModuleSetNames lists the module sets to release, unless AllModuleSets is set.
+	ModuleSetNames []string
+	AllModuleSets  bool
+	// CommitHash is the merged commit to tag, once the prerelease branches are merged.
+	CommitHash string
+	// Remote is the Git remote to push tags to.
+	Remote string
+	// StateFile records the progress of the release, in the .git directory if empty.
+	StateFile string
+	// SkipModTidy skips running "go mod tidy" on the changed modules.
+	SkipModTidy bool
+	// GoModTidyCompat is the Go version passed to "go mod tidy -compat", omitted if empty.
+	GoModTidyCompat string
+	// SigningMode is default, none, gpg or ssh. Empty means default, which follows the Git
+	// configuration.
+	SigningMode string
+	// SkipChecks lists the pre-tag checks to skip, or "all".
+	SkipChecks []string
+}
+
+// Release runs, or resumes, the release of module sets from prerelease to pushed tags.
+func Release(opts ReleaseOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	if opts.SigningMode == "" {
+		opts.SigningMode = string(tag.DefaultSigningMode)
+	}
+	return release.Run(versioningFile, opts.ModuleSetNames, opts.AllModuleSets, opts.CommitHash, opts.Remote, opts.StateFile,
+		opts.SkipModTidy, opts.GoModTidyCompat, opts.SigningMode, opts.SkipChecks)
+}
+
+// RetractOptions configures Retract.
+type RetractOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	ModuleSetName  string
+	// Versions is a version, such as v1.2.3, or a closed range, such as [v1.2.0, v1.2.3].
+	Versions string
+	// Rationale is added as a comment above every retract directive.
+	Rationale string
+	// Patch runs prerelease for the next patch version of the module set after the retraction.
+	Patch bool
+	// SkipModTidy, GoModTidyCompat and SkipChecks are used by prerelease if Patch is set.
+	SkipModTidy     bool
+	GoModTidyCompat string
+	SkipChecks      []string
+	// CommitToDifferentBranch commits the changes to a new branch.
+	CommitToDifferentBranch bool
+}
+
+// Retract adds retract directives for versions of a module set to the go.mod files of its
+// modules and commits them.
+func Retract(opts RetractOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	skipped, err := common.ParseSkippedChecks(opts.SkipChecks)
+	if err != nil {
+		return err
+	}
+	return retract.Run(versioningFile, opts.ModuleSetName, opts.Versions, retract.Options{
+		Rationale:               opts.Rationale,
+		Patch:                   opts.Patch,
+		SkipModTidy:             opts.SkipModTidy,
+		GoModTidyCompat:         opts.GoModTidyCompat,
+		SkipChecks:              skipped,
+		CommitToDifferentBranch: opts.CommitToDifferentBranch,
+	})
+}
+
+// SuggestOptions configures Suggest.
+type SuggestOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	// Write inserts the modules into the proposed module sets of the versioning file.
+	Write bool
+}
+
+// Suggest prints a module set for every module of the repo missing from the versioning file.
+func Suggest(opts SuggestOptions) error {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return err
+	}
+	return suggest.Run(versioningFile, opts.Write)
+}
+
+// ModuleChanges lists the files of a module changed between two refs.
+type ModuleChanges = diff.ModuleChanges
+
+// FileChange is a file changed between two refs.
+type FileChange = diff.FileChange
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	// VersioningFile is the path of the versioning file. DefaultVersioningFile in the repo root
+	// is used if empty.
+	VersioningFile string
+	ModuleSetName  string
+	// PreviousVersion is the version of the module set whose tags are compared against, one tag
+	// per module. It is ignored if Base is set.
+	PreviousVersion string
+	// Base is the ref all modules are compared against instead of their previous version tags.
+	Base string
+	// Head is the ref compared, HEAD if empty.
+	Head string
+	// Include and Exclude are patterns of the files compared, relative to the module directory.
+	// Go files are compared if Include is empty.
+	Include []string
+	Exclude []string
+}
+
+// Diff returns the modules of a module set with files changed between the given refs.
+func Diff(opts DiffOptions) ([]ModuleChanges, error) {
+	versioningFile, err := versioningFilePath(opts.VersioningFile)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := repo.FindRoot()
+	if err != nil {
+		return nil, fmt.Errorf("unable to find repo root: %w", err)
+	}
+	return diff.HasChanged(repoRoot, versioningFile, opts.ModuleSetName, diff.Options{
+		PreviousVersion: opts.PreviousVersion,
+		Base:            opts.Base,
+		Head:            opts.Head,
+		Include:         opts.Include,
+		Exclude:         opts.Exclude,
+	})
+}
+
+// WriteDiffReport writes the changes returned by Diff to w, grouped by module.
+func WriteDiffReport(w io.Writer, changes []ModuleChanges) error {
+	return diff.WriteReport(w, changes)
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package multimod
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/build-tools/multimod/internal/common/commontest"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// chdir changes the working directory to dir until the end of the test.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+}
+
+// newRepo creates a repo with one module set, one listed module and one committed module
+// missing from the versioning file.
+func newRepo(t *testing.T) string {
+	tmpRootDir := t.TempDir()
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, DefaultVersioningFile): []byte("module-sets:\n" +
+			"  mod-set-1:\n    version: v1.2.3\n    modules:\n      - go.opentelemetry.io/test/test1\n"),
+		filepath.Join(tmpRootDir, "test1", "go.mod"):      []byte("module go.opentelemetry.io/test/test1\n\ngo 1.19\n"),
+		filepath.Join(tmpRootDir, "not_listed", "go.mod"): []byte("module go.opentelemetry.io/test/notlisted\n\ngo 1.19\n"),
+	}))
+
+	repo, _, err := commontest.InitNewRepoWithCommit(tmpRootDir)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.AddGlob("."))
+	_, err = worktree.Commit("add modules", &git.CommitOptions{Author: commontest.TestAuthor})
+	require.NoError(t, err)
+
+	return tmpRootDir
+}
+
+func TestVerify(t *testing.T) {
+	chdir(t, newRepo(t))
+
+	err := Verify(VerifyOptions{})
+	assert.ErrorIs(t, err, ErrVerificationFailed)
+	assert.ErrorContains(t, err, "go.opentelemetry.io/test/notlisted")
+}
+
+func TestPlan(t *testing.T) {
+	chdir(t, newRepo(t))
+
+	err := Plan(PlanOptions{ModuleSetName: "mod-set-2"})
+	assert.ErrorIs(t, err, ErrModuleSetNotFound)
+}
+
+func TestPrerelease(t *testing.T) {
+	tmpRootDir := newRepo(t)
+	chdir(t, tmpRootDir)
+	require.NoError(t, commontest.WriteTempFiles(map[string][]byte{
+		filepath.Join(tmpRootDir, "test1", "test1.go"): []byte("package test1\n"),
+	}))
+
+	err := Prerelease(PrereleaseOptions{
+		VersioningFile: filepath.Join(tmpRootDir, DefaultVersioningFile),
+		ModuleSetNames: []string{"mod-set-1"},
+		SkipModTidy:    true,
+	})
+	assert.ErrorIs(t, err, ErrWorkingTreeNotClean)
+}
+
+func TestSync(t *testing.T) {
+	chdir(t, newRepo(t))
+
+	assert.EqualError(t, Sync(SyncOptions{AllModuleSets: true}),
+		"exactly one of the other repo root and URL must be given")
+}